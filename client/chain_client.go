@@ -4,36 +4,44 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
 	"path"
-	"sync"
+	"path/filepath"
+	"strings"
 	"time"
 
 	registry "github.com/KyleMoser/cosmos-client/client/chain_registry"
 	"github.com/KyleMoser/cosmos-client/client/rpc"
+	"github.com/KyleMoser/cosmos-client/client/telemetry"
+	"github.com/KyleMoser/cosmos-client/client/throttle"
 	"github.com/avast/retry-go/v4"
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/crypto/keyring"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
 
 	provtypes "github.com/cometbft/cometbft/light/provider"
-	prov "github.com/cometbft/cometbft/light/provider/http"
 	rpcclient "github.com/cometbft/cometbft/rpc/client"
 	"github.com/cosmos/gogoproto/proto"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 	"gopkg.in/yaml.v3"
 )
 
 var (
-	// Variables used for retries
+	// RtyAttNum and the variables below were used for retries.
+	//
+	// Deprecated: retries are configured with the retry policy of ChainClientConfig.Retry, which defaults to
+	// throttle.DefaultRetryPolicy. These variables are no longer used by the client.
 	RtyAttNum = uint(5)
-	RtyAtt    = retry.Attempts(RtyAttNum)
-	RtyDel    = retry.Delay(time.Millisecond * 400)
-	RtyErr    = retry.LastErrorOnly(true)
+	// Deprecated: see RtyAttNum.
+	RtyAtt = retry.Attempts(RtyAttNum)
+	// Deprecated: see RtyAttNum.
+	RtyDel = retry.Delay(time.Millisecond * 400)
+	// Deprecated: see RtyAttNum.
+	RtyErr = retry.LastErrorOnly(true)
 )
 
 type ChainClient struct {
@@ -46,19 +54,53 @@ type ChainClient struct {
 	LightProvider  provtypes.Provider
 	Input          io.Reader
 	Output         io.Writer
-	// TODO: GRPC Client type?
+	// Registry is the chain registry used to look up IBC paths. Defaults to the cosmos/chain-registry on GitHub.
+	Registry registry.ChainRegistry
+	// GRPCConn is the native gRPC connection to GRPCAddr if it is set, used for queries unless the query route is rpc.
+	GRPCConn *grpc.ClientConn
+	// verifier holds the light client verifying store queries when Config.Verify is set.
+	verifier lightVerifier
+	// Metrics records the Prometheus metrics of the client's requests if set. It defaults to
+	// telemetry.DefaultMetrics if metrics are enabled in the config.
+	Metrics *telemetry.Metrics
+	// QueryCache caches the responses of queries run by RunGRPCQuery if set. It defaults to the cache
+	// configured by Config.QueryCache.
+	QueryCache *QueryCache
+	// retryPolicy retries failed requests, see RetryPolicy.
+	retryPolicy throttle.RetryPolicy
+	// rpcOpts are the options of the RPC clients, which light client providers are created with too.
+	rpcOpts []rpc.Option
 	rpcLiveness
 	Codec Codec
 }
 
-type rpcLiveness struct {
-	isActive    bool
-	lastChecked time.Time
-	lastActive  time.Time
+// RetryPolicy returns the policy retrying failed requests of the client, which is configured by Config.Retry.
+func (cc *ChainClient) RetryPolicy() throttle.RetryPolicy {
+	if cc.retryPolicy.Attempts == 0 {
+		return throttle.DefaultRetryPolicy()
+	}
+	return cc.retryPolicy
 }
 
-func (cc *ChainClient) IsActive() bool {
-	return cc.rpcLiveness.isActive
+// instrument starts the telemetry of a request of the client, see telemetry.Start.
+func (cc *ChainClient) instrument(ctx context.Context, operation, method, endpoint string) (context.Context, func(error)) {
+	return telemetry.Start(ctx, cc.Metrics, telemetry.Labels{
+		ChainID:   cc.Config.ChainID,
+		Operation: operation,
+		Method:    method,
+		Endpoint:  endpoint,
+	})
+}
+
+// msgsMethod returns the type URLs of the msgs, which label their transactions in telemetry.
+func msgsMethod(msgs []sdk.Msg) string {
+	typeURLs := make([]string, 0, len(msgs))
+	for _, msg := range msgs {
+		if typeURL := sdk.MsgTypeURL(msg); !containsString(typeURLs, typeURL) {
+			typeURLs = append(typeURLs, typeURL)
+		}
+	}
+	return strings.Join(typeURLs, ",")
 }
 
 // CliContext creates a new Cosmos SDK client context
@@ -75,129 +117,40 @@ func (cc *ChainClient) CliContext() client.Context {
 	}
 }
 
-// HealthChecks will continuously check the liveness of each of the given ChainClients.
-// If a ChainClient's RPC node is active, chainClient.IsActive() will return true.
-func HealthChecks(chainClients ...*ChainClient) {
-	ticker := time.NewTicker(10 * time.Second)
-	quit := make(chan struct{})
-	go func() {
-		for {
-			select {
-			case <-ticker.C:
-				//Check all of the ChainClients to see if they respond to RPC 'get height' queries.
-				var wg sync.WaitGroup
-				for _, cc := range chainClients {
-					cc := cc
-					if time.Since(cc.rpcLiveness.lastChecked) > (9 * time.Second) {
-						wg.Add(1)
-
-						go func() {
-							defer wg.Done()
-							cc.healthCheck()
-						}()
-					}
-				}
-
-				wg.Wait()
-
-				//Stop the liveness checks if none of the RPC clients have been active for at least 10 minutes
-				lastActiveTime := time.Now().Add(time.Duration(-10) * time.Minute)
-				for _, cc := range chainClients {
-					if cc.rpcLiveness.isActive {
-						break
-					}
-
-					if cc.rpcLiveness.lastActive.After(lastActiveTime) {
-						break
-					}
-				}
-
-				close(quit)
-			case <-quit:
-				ticker.Stop()
-				return
-			}
-		}
-	}()
-}
-
+// GetIbcTransferConfig returns the preferred transfer channel from this chain to destChain.
 func (c *ChainClient) GetIbcTransferConfig(destChain string) (srcChannel, srcPort, clientId string, err error) {
 	ibcConfig, err := c.GetIbcConfig(destChain)
 	if err != nil {
 		return "", "", "", err
-	} else if len(ibcConfig.Channels) == 0 {
-		return "", "", "", errors.New("unexpected chain configuration 'channels' not found")
+	}
+	channel, err := ibcConfig.PreferredChannel(transfertypes.PortID)
+	if err != nil {
+		return "", "", "", err
 	}
 
 	clientId = ibcConfig.Chain1.ClientId
-	srcChannel = ibcConfig.Channels[0].Chain1.ChannelId
-	srcPort = ibcConfig.Channels[0].Chain1.PortId
+	srcChannel = channel.Chain1.ChannelId
+	srcPort = channel.Chain1.PortId
 	return
 }
 
+// GetIbcConfigs returns the IBC configs between this chain and every chain it has a path to in the registry.
+func (c *ChainClient) GetIbcConfigs(ctx context.Context) ([]registry.IbcConfig, error) {
+	return registry.GetIbcConfigs(ctx, c.ChainRegistry(), c.Config.ChainName)
+}
+
 // Get the IBC configuration where this chain is the source and destChain is the IBC endpoint.
 func (c *ChainClient) GetIbcConfig(destChain string) (registry.IbcConfig, error) {
-	// A chain registry entry could either be under e.g. https://github.com/cosmos/chain-registry/blob/master/_IBC/juno-osmosis.json
-	// OR it could be under e.g. https://github.com/cosmos/chain-registry/blob/master/_IBC/osmosis-juno.json
-	chainRegURL := fmt.Sprintf("https://raw.githubusercontent.com/cosmos/chain-registry/master/_IBC/%s-%s.json", c.Config.ChainName, destChain)
-	chainRegURLReversed := fmt.Sprintf("https://raw.githubusercontent.com/cosmos/chain-registry/master/_IBC/%s-%s.json", destChain, c.Config.ChainName)
-	reversed := false
-
-	res, err := http.Get(chainRegURL)
-	if err != nil {
-		return registry.IbcConfig{}, err
-	}
-
-	if res.StatusCode == 404 {
-		reversed = true
-		res, err = http.Get(chainRegURLReversed)
-		if err != nil {
-			return registry.IbcConfig{}, err
-		} else if res.StatusCode != 200 {
-			return registry.IbcConfig{}, fmt.Errorf("expected http 200, got %d", res.StatusCode)
-		}
-	}
-	defer res.Body.Close()
-	if res.StatusCode == http.StatusNotFound {
-		return registry.IbcConfig{}, fmt.Errorf("IBC config not found: response code: %d: GET failed: %s", res.StatusCode, chainRegURL)
-	}
-	if res.StatusCode != http.StatusOK {
-		return registry.IbcConfig{}, fmt.Errorf("response code: %d: GET failed: %s", res.StatusCode, chainRegURL)
-	}
-
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		return registry.IbcConfig{}, err
-	}
-
-	var conf registry.IbcConfig
-	if err := json.Unmarshal([]byte(body), &conf); err != nil {
-		return registry.IbcConfig{}, err
-	}
-
-	// In the registry file contents, we must flip all references to chain1 and chain2. See example URLs above.
-	if reversed {
-		tmp := conf.Chain1
-		conf.Chain1 = conf.Chain2
-		conf.Chain2 = tmp
-
-		for i := range conf.Channels {
-			tmp := conf.Channels[i].Chain1
-			conf.Channels[i].Chain1 = conf.Channels[i].Chain2
-			conf.Channels[i].Chain2 = tmp
-		}
-	}
-	return conf, nil
+	return c.ChainRegistry().GetIbcConfig(c.Config.ChainName, destChain)
 }
 
-func (cc *ChainClient) healthCheck() bool {
-	_, err := cc.QueryLatestHeight(context.Background())
-	cc.rpcLiveness.isActive = err == nil
-	cc.rpcLiveness.lastChecked = time.Now()
-	if cc.rpcLiveness.isActive {
-		cc.rpcLiveness.lastActive = time.Now()
+// ChainRegistry returns the chain registry used by this client, which is the
+// DefaultChainRegistry unless the Registry field has been set.
+func (c *ChainClient) ChainRegistry() registry.ChainRegistry {
+	if c.Registry != nil {
+		return c.Registry
 	}
-	return cc.rpcLiveness.isActive
+	return registry.DefaultChainRegistry(c.log)
 }
 
 // Chain client where keys are in 'rootKeyDirectory/keyring-test' (or whichever keyring-backend is chosen)
@@ -243,17 +196,61 @@ func (cc *ChainClient) Init() error {
 	}
 	// TODO: figure out how to deal with input or maybe just make all keyring backends test?
 
+	if cc.Metrics == nil && cc.Config.Metrics {
+		cc.Metrics = telemetry.DefaultMetrics()
+	}
+	if cc.QueryCache == nil && cc.Config.QueryCache != nil {
+		cacheConfig := *cc.Config.QueryCache
+		if cacheConfig.Dir != "" {
+			// Requests of different chains may be identical, so every chain has its own directory.
+			cacheConfig.Dir = filepath.Join(cacheConfig.Dir, cc.Config.ChainID)
+		}
+		queryCache, err := cacheConfig.NewQueryCache()
+		if err != nil {
+			return err
+		}
+		cc.QueryCache = queryCache
+	}
+	retryPolicy, err := cc.Config.Retry.Policy()
+	if err != nil {
+		return err
+	}
+	rpcOpts := []rpc.Option{
+		rpc.WithTelemetry(cc.Config.ChainID, cc.Metrics),
+		rpc.WithRetryPolicy(retryPolicy),
+		rpc.WithRateLimit(cc.Config.RateLimit),
+		rpc.WithTransport(cc.Config.Transport),
+	}
+
 	timeout, _ := time.ParseDuration(cc.Config.Timeout)
-	rpcClient, err := rpc.NewRPCClient(cc.Config.RPCAddr, timeout)
+	var rpcClient rpcclient.Client
+	if len(cc.Config.RPCAddrs) > 0 {
+		// The RPC address is preferred until it is slower or less healthy than the other addresses.
+		rpcClient, err = rpc.NewMultiClient(append([]string{cc.Config.RPCAddr}, cc.Config.RPCAddrs...), timeout, rpcOpts...)
+	} else {
+		rpcClient, err = rpc.NewRPCClient(cc.Config.RPCAddr, timeout, rpcOpts...)
+	}
 	if err != nil {
 		return err
 	}
 
-	lightprovider, err := prov.New(cc.Config.ChainID, cc.Config.RPCAddr)
+	cc.rpcOpts = rpcOpts
+	lightprovider, err := cc.newLightProvider(cc.Config.RPCAddr)
 	if err != nil {
 		return err
 	}
 
+	// The connection is established on first use, so it is set up even if the query route is rpc
+	// for calls and contexts that select another route.
+	if cc.Config.GRPCAddr != "" {
+		grpcConn, err := dialGRPC(cc.log, cc.Config.GRPCAddr, cc.Config.Transport, cc.Codec.InterfaceRegistry)
+		if err != nil {
+			return err
+		}
+		cc.GRPCConn = grpcConn
+	}
+
+	cc.retryPolicy = retryPolicy
 	cc.RPCClient = rpcClient
 	cc.LightProvider = lightprovider
 	cc.Keybase = keybase
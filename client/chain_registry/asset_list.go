@@ -1,22 +1,130 @@
 package chain_registry
 
+// AssetList is the assetlist.json of a chain in the registry.
+// See https://github.com/cosmos/chain-registry/blob/master/assetlist.schema.json
 type AssetList struct {
-	Schema  string `json:"$schema"`
-	ChainID string `json:"chain_id"`
-	Assets  []struct {
-		Description string `json:"description"`
-		DenomUnits  []struct {
-			Denom    string `json:"denom"`
-			Exponent int    `json:"exponent"`
-		} `json:"denom_units"`
-		Base     string `json:"base"`
-		Name     string `json:"name"`
-		Display  string `json:"display"`
-		Symbol   string `json:"symbol"`
-		LogoURIs struct {
-			Png string `json:"png"`
-			Svg string `json:"svg"`
-		} `json:"logo_URIs"`
-		CoingeckoID string `json:"coingecko_id"`
-	} `json:"assets"`
+	Schema    string `json:"$schema"`
+	ChainName string `json:"chain_name"`
+	// ChainID is set to the chain ID of the chain when the asset list is read with ChainInfo.GetAssetList.
+	//
+	// Deprecated: asset lists in the registry have no chain_id; use ChainName.
+	ChainID string  `json:"chain_id,omitempty"`
+	Assets  []Asset `json:"assets"`
+}
+
+// Asset types, as found in the type_asset field.
+const (
+	AssetTypeSdkCoin = "sdk.coin"
+	AssetTypeCw20    = "cw20"
+	AssetTypeErc20   = "erc20"
+	AssetTypeIcs20   = "ics20"
+	AssetTypeSnip20  = "snip20"
+	AssetTypeSnip25  = "snip25"
+)
+
+// Trace types, as found in the type field of an asset trace.
+const (
+	TraceTypeIbc         = "ibc"
+	TraceTypeIbcCw20     = "ibc-cw20"
+	TraceTypeBridge      = "bridge"
+	TraceTypeLiquidStake = "liquid-stake"
+	TraceTypeWrapped     = "wrapped"
+	TraceTypeSynthetic   = "synthetic"
+	TraceTypeAdditional  = "additional-mintage"
+	TraceTypeTestMintage = "test-mintage"
+)
+
+type Asset struct {
+	Deprecated          bool        `json:"deprecated,omitempty"`
+	Description         string      `json:"description"`
+	ExtendedDescription string      `json:"extended_description,omitempty"`
+	DenomUnits          []DenomUnit `json:"denom_units"`
+	// TypeAsset is the kind of token, e.g. sdk.coin, cw20 or ics20. See the AssetType constants.
+	TypeAsset string `json:"type_asset,omitempty"`
+	// Address is the contract address of cw20, erc20 and snip20 tokens.
+	Address string       `json:"address,omitempty"`
+	Base    string       `json:"base"`
+	Name    string       `json:"name"`
+	Display string       `json:"display"`
+	Symbol  string       `json:"symbol"`
+	Traces  []AssetTrace `json:"traces,omitempty"`
+	// Ibc is the deprecated single hop description of an IBC asset, superseded by Traces.
+	Ibc         *AssetIbc `json:"ibc,omitempty"`
+	LogoURIs    LogoURIs  `json:"logo_URIs"`
+	Images      []Image   `json:"images,omitempty"`
+	CoingeckoID string    `json:"coingecko_id"`
+	Keywords    []string  `json:"keywords,omitempty"`
+	Socials     struct {
+		Website string `json:"website,omitempty"`
+		Twitter string `json:"twitter,omitempty"`
+	} `json:"socials,omitempty"`
+}
+
+type DenomUnit struct {
+	Denom    string   `json:"denom"`
+	Exponent int      `json:"exponent"`
+	Aliases  []string `json:"aliases,omitempty"`
+}
+
+// AssetTrace describes how an asset was derived from an asset on another chain, e.g. by an IBC transfer.
+type AssetTrace struct {
+	Type         string `json:"type"`
+	Counterparty struct {
+		ChainName string `json:"chain_name"`
+		BaseDenom string `json:"base_denom"`
+		Port      string `json:"port,omitempty"`
+		ChannelID string `json:"channel_id,omitempty"`
+	} `json:"counterparty"`
+	Chain struct {
+		Port      string `json:"port,omitempty"`
+		ChannelID string `json:"channel_id,omitempty"`
+		// Path is the IBC denom trace of the asset on this chain, e.g. transfer/channel-0/uatom.
+		Path string `json:"path,omitempty"`
+	} `json:"chain,omitempty"`
+	Provider string `json:"provider,omitempty"`
+}
+
+type AssetIbc struct {
+	SourceChannel string `json:"source_channel"`
+	DstChannel    string `json:"dst_channel"`
+	SourceDenom   string `json:"source_denom"`
+}
+
+type LogoURIs struct {
+	Png string `json:"png,omitempty"`
+	Svg string `json:"svg,omitempty"`
+}
+
+type Image struct {
+	ImageSync *struct {
+		ChainName string `json:"chain_name"`
+		BaseDenom string `json:"base_denom"`
+	} `json:"image_sync,omitempty"`
+	Png   string `json:"png,omitempty"`
+	Svg   string `json:"svg,omitempty"`
+	Theme *struct {
+		PrimaryColorHex string `json:"primary_color_hex,omitempty"`
+		Circle          bool   `json:"circle,omitempty"`
+		DarkMode        bool   `json:"dark_mode,omitempty"`
+	} `json:"theme,omitempty"`
+}
+
+// DisplayExponent returns the exponent of the asset's display denom unit, or 0 if it is not listed.
+func (a Asset) DisplayExponent() int {
+	for _, u := range a.DenomUnits {
+		if u.Denom == a.Display {
+			return u.Exponent
+		}
+	}
+	return 0
+}
+
+// IbcTrace returns the last IBC trace of the asset, which describes the hop that brought it to this chain.
+func (a Asset) IbcTrace() (AssetTrace, bool) {
+	for i := len(a.Traces) - 1; i >= 0; i-- {
+		if a.Traces[i].Type == TraceTypeIbc || a.Traces[i].Type == TraceTypeIbcCw20 {
+			return a.Traces[i], true
+		}
+	}
+	return AssetTrace{}, false
 }
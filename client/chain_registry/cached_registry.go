@@ -0,0 +1,401 @@
+package chain_registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultRegistryCacheTTL is how long cached registry data is used before it is revalidated.
+	DefaultRegistryCacheTTL = 24 * time.Hour
+	// DefaultRegistryNotFoundTTL is how long a file missing from the registry is remembered as missing,
+	// unless the TTL of the cache is shorter. Files are added to the registry more often than they change.
+	DefaultRegistryNotFoundTTL = 5 * time.Minute
+)
+
+// cacheFileExt is the extension of the files holding cache entries.
+const cacheFileExt = ".cache"
+
+// Document is a raw file fetched from a chain registry.
+type Document struct {
+	Body []byte
+	// ETag identifies the version of the document, if the registry supports it.
+	ETag string
+	// NotModified is set when a conditional fetch found the document unchanged. Body is empty in that case.
+	NotModified bool
+}
+
+// DocumentFetcher is implemented by registries which can fetch raw registry files by path,
+// optionally conditional on an ETag. CachedRegistry uses it to revalidate cached files cheaply.
+type DocumentFetcher interface {
+	FetchDocument(ctx context.Context, path, etag string) (Document, error)
+}
+
+// Cache entry kinds, recording which registry call produced an entry so it can be refreshed.
+const (
+	cacheKindChains    = "chains"
+	cacheKindChain     = "chain"
+	cacheKindAssetList = "assetlist"
+	cacheKindIbc       = "ibc"
+	cacheKindIbcPaths  = "ibc-paths"
+)
+
+type cacheEntry struct {
+	Key       string          `json:"key"`
+	Kind      string          `json:"kind"`
+	Args      []string        `json:"args"`
+	ETag      string          `json:"etag,omitempty"`
+	FetchedAt time.Time       `json:"fetched_at"`
+	Body      json.RawMessage `json:"body"`
+	// NotFound records that the document does not exist in the registry, in which case Body is empty.
+	NotFound bool `json:"not_found,omitempty"`
+}
+
+// CacheEntryStatus describes an entry of the registry cache.
+type CacheEntryStatus struct {
+	Key       string        `json:"key" yaml:"key"`
+	ETag      string        `json:"etag,omitempty" yaml:"etag,omitempty"`
+	FetchedAt time.Time     `json:"fetched_at" yaml:"fetched_at"`
+	Age       time.Duration `json:"age" yaml:"age"`
+	Stale     bool          `json:"stale" yaml:"stale"`
+	NotFound  bool          `json:"not_found,omitempty" yaml:"not_found,omitempty"`
+}
+
+// CachedRegistry wraps a ChainRegistry and caches its responses on disk.
+//
+// Cached data is used as is until it is older than the TTL, after which it is revalidated.
+// If the wrapped registry is a DocumentFetcher the revalidation is conditional on the ETag
+// of the cached file. If the wrapped registry cannot be reached, stale data is returned instead.
+// Files missing from the registry are cached as missing for DefaultRegistryNotFoundTTL.
+type CachedRegistry struct {
+	log         *zap.Logger
+	inner       ChainRegistry
+	dir         string
+	ttl         time.Duration
+	notFoundTTL time.Duration
+
+	mu sync.Mutex
+}
+
+// NewCachedRegistry returns a CachedRegistry storing the responses of inner under dir.
+// A non-positive ttl uses DefaultRegistryCacheTTL.
+func NewCachedRegistry(log *zap.Logger, inner ChainRegistry, dir string, ttl time.Duration) *CachedRegistry {
+	if ttl <= 0 {
+		ttl = DefaultRegistryCacheTTL
+	}
+	notFoundTTL := DefaultRegistryNotFoundTTL
+	if ttl < notFoundTTL {
+		notFoundTTL = ttl
+	}
+	return &CachedRegistry{log: log, inner: inner, dir: dir, ttl: ttl, notFoundTTL: notFoundTTL}
+}
+
+func (c *CachedRegistry) ListChains(ctx context.Context) ([]string, error) {
+	var chains []string
+	if err := c.getJSON(ctx, "_chains.json", cacheKindChains, nil, &chains); err != nil {
+		return nil, err
+	}
+	return chains, nil
+}
+
+func (c *CachedRegistry) GetChain(name string) (ChainInfo, error) {
+	result := NewChainInfo(c.log.With(zap.String("chain_name", name)))
+	if err := c.getJSON(context.Background(), name+"/chain.json", cacheKindChain, []string{name}, &result); err != nil {
+		return ChainInfo{}, err
+	}
+	result.registry = c
+	return result, nil
+}
+
+func (c *CachedRegistry) GetAssetList(name string) (AssetList, error) {
+	var assetList AssetList
+	if err := c.getJSON(context.Background(), name+"/assetlist.json", cacheKindAssetList, []string{name}, &assetList); err != nil {
+		return AssetList{}, err
+	}
+	return assetList, nil
+}
+
+func (c *CachedRegistry) GetIbcConfig(chainA, chainB string) (IbcConfig, error) {
+	ctx := context.Background()
+	path, reversedPath := ibcConfigPaths(chainA, chainB)
+
+	var conf IbcConfig
+	err := c.getJSON(ctx, path, cacheKindIbc, []string{chainA, chainB}, &conf)
+	if err == nil {
+		return conf, nil
+	}
+	if _, ok := c.inner.(DocumentFetcher); !ok {
+		// The wrapped registry already orients the config, so there is no reversed file to look for.
+		return IbcConfig{}, err
+	}
+
+	if rerr := c.getJSON(ctx, reversedPath, cacheKindIbc, []string{chainB, chainA}, &conf); rerr != nil {
+		if errors.Is(err, ErrNotFound) {
+			return IbcConfig{}, rerr
+		}
+		return IbcConfig{}, err
+	}
+	return conf.Reversed(), nil
+}
+
+func (c *CachedRegistry) ListIbcPaths(ctx context.Context) ([]IbcPath, error) {
+	var paths []IbcPath
+	if err := c.getJSON(ctx, "_IBC/_paths.json", cacheKindIbcPaths, nil, &paths); err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
+func (c *CachedRegistry) SourceLink() string {
+	return c.inner.SourceLink()
+}
+
+// Refresh revalidates every cached entry regardless of its age and returns the number of entries refreshed.
+func (c *CachedRegistry) Refresh(ctx context.Context) (int, error) {
+	entries, err := c.entries()
+	if err != nil {
+		return 0, err
+	}
+
+	refreshed := 0
+	for _, e := range entries {
+		if _, err := c.revalidate(ctx, e, true); err != nil && !errors.Is(err, ErrNotFound) {
+			return refreshed, fmt.Errorf("failed to refresh %s: %w", e.Key, err)
+		}
+		refreshed++
+	}
+	return refreshed, nil
+}
+
+// Clear removes every cached entry.
+func (c *CachedRegistry) Clear() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return os.RemoveAll(c.dir)
+}
+
+// Status describes every cached entry, sorted by key.
+func (c *CachedRegistry) Status() ([]CacheEntryStatus, error) {
+	entries, err := c.entries()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	out := make([]CacheEntryStatus, 0, len(entries))
+	for _, e := range entries {
+		age := now.Sub(e.FetchedAt).Truncate(time.Second)
+		out = append(out, CacheEntryStatus{
+			Key:       e.Key,
+			ETag:      e.ETag,
+			FetchedAt: e.FetchedAt,
+			Age:       age,
+			Stale:     age >= c.entryTTL(e),
+			NotFound:  e.NotFound,
+		})
+	}
+	return out, nil
+}
+
+// getJSON returns the cached document for key, revalidating it first if it has expired, and unmarshals it into out.
+func (c *CachedRegistry) getJSON(ctx context.Context, key, kind string, args []string, out interface{}) error {
+	e, ok := c.load(key)
+	if !ok {
+		e = cacheEntry{Key: key, Kind: kind, Args: args}
+	}
+
+	body, err := c.revalidate(ctx, e, !ok || time.Since(e.FetchedAt) >= c.entryTTL(e))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, out)
+}
+
+// entryTTL returns how long the entry is used before it is revalidated.
+func (c *CachedRegistry) entryTTL(e cacheEntry) time.Duration {
+	if e.NotFound {
+		return c.notFoundTTL
+	}
+	return c.ttl
+}
+
+// revalidate fetches the entry from the wrapped registry if expired is set, updates the cache
+// and returns the current document. Stale data is returned if the registry cannot be reached.
+// Documents missing from the registry return ErrNotFound.
+func (c *CachedRegistry) revalidate(ctx context.Context, e cacheEntry, expired bool) ([]byte, error) {
+	cached := len(e.Body) > 0 || e.NotFound
+	if cached && !expired {
+		return e.cached()
+	}
+
+	doc, err := c.fetch(ctx, e)
+	if errors.Is(err, ErrNotFound) {
+		e.Body, e.ETag, e.NotFound, e.FetchedAt = nil, "", true, time.Now()
+		if err := c.store(e); err != nil {
+			c.log.Warn("Failed to write chain registry cache", zap.String("key", e.Key), zap.Error(err))
+		}
+		return nil, err
+	}
+	if err != nil {
+		if !cached {
+			return nil, err
+		}
+		c.log.Warn(
+			"Using stale chain registry data",
+			zap.String("key", e.Key),
+			zap.Time("fetched_at", e.FetchedAt),
+			zap.Error(err),
+		)
+		return e.cached()
+	}
+
+	if !doc.NotModified || !cached || e.NotFound {
+		e.Body = doc.Body
+		e.ETag = doc.ETag
+	}
+	e.NotFound = false
+	e.FetchedAt = time.Now()
+	if err := c.store(e); err != nil {
+		// The document is still valid, it just cannot be cached.
+		c.log.Warn("Failed to write chain registry cache", zap.String("key", e.Key), zap.Error(err))
+	}
+	return e.Body, nil
+}
+
+// cached returns the cached document, or ErrNotFound if the document was cached as missing.
+func (e cacheEntry) cached() ([]byte, error) {
+	if e.NotFound {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, e.Key)
+	}
+	return e.Body, nil
+}
+
+// fetch fetches the document for the entry from the wrapped registry.
+func (c *CachedRegistry) fetch(ctx context.Context, e cacheEntry) (Document, error) {
+	if f, ok := c.inner.(DocumentFetcher); ok && e.Kind != cacheKindChains && e.Kind != cacheKindIbcPaths {
+		return f.FetchDocument(ctx, e.Key, e.ETag)
+	}
+
+	var (
+		result interface{}
+		err    error
+	)
+	switch {
+	case e.Kind == cacheKindChains:
+		result, err = c.inner.ListChains(ctx)
+	case e.Kind == cacheKindIbcPaths:
+		result, err = c.inner.ListIbcPaths(ctx)
+	case e.Kind == cacheKindChain && len(e.Args) == 1:
+		result, err = c.inner.GetChain(e.Args[0])
+	case e.Kind == cacheKindAssetList && len(e.Args) == 1:
+		result, err = c.inner.GetAssetList(e.Args[0])
+	case e.Kind == cacheKindIbc && len(e.Args) == 2:
+		result, err = c.inner.GetIbcConfig(e.Args[0], e.Args[1])
+	default:
+		return Document{}, fmt.Errorf("invalid cache entry %s of kind %q", e.Key, e.Kind)
+	}
+	if err != nil {
+		return Document{}, err
+	}
+
+	body, err := json.Marshal(result)
+	if err != nil {
+		return Document{}, err
+	}
+	return Document{Body: body}, nil
+}
+
+func (c *CachedRegistry) entryPath(key string) string {
+	return filepath.Join(c.dir, filepath.FromSlash(key)+cacheFileExt)
+}
+
+func (c *CachedRegistry) load(key string) (cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	bz, err := os.ReadFile(c.entryPath(key))
+	if err != nil {
+		return cacheEntry{}, false
+	}
+	var e cacheEntry
+	if err := json.Unmarshal(bz, &e); err != nil || (len(e.Body) == 0 && !e.NotFound) {
+		c.log.Debug("Ignoring invalid chain registry cache entry", zap.String("key", key), zap.Error(err))
+		return cacheEntry{}, false
+	}
+	return e, true
+}
+
+func (c *CachedRegistry) store(e cacheEntry) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	bz, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	file := c.entryPath(e.Key)
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so that concurrent readers never see a partial entry.
+	tmp, err := os.CreateTemp(filepath.Dir(file), filepath.Base(file)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(bz); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
+
+// entries returns every valid entry in the cache, sorted by key.
+func (c *CachedRegistry) entries() ([]cacheEntry, error) {
+	var keys []string
+	err := filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, cacheFileExt) {
+			return nil
+		}
+		rel, err := filepath.Rel(c.dir, path)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, filepath.ToSlash(strings.TrimSuffix(rel, cacheFileExt)))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(keys)
+	entries := make([]cacheEntry, 0, len(keys))
+	for _, key := range keys {
+		if e, ok := c.load(key); ok {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
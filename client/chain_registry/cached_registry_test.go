@@ -0,0 +1,146 @@
+package chain_registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// fakeFetcherRegistry serves registry documents from memory, with ETags, and can be taken offline.
+type fakeFetcherRegistry struct {
+	LocalRegistry
+	docs    map[string]string
+	fetches int
+	hits    int
+	offline bool
+}
+
+func (f *fakeFetcherRegistry) FetchDocument(_ context.Context, path, etag string) (Document, error) {
+	if f.offline {
+		return Document{}, errors.New("network unreachable")
+	}
+	f.fetches++
+	body, ok := f.docs[path]
+	if !ok {
+		return Document{}, fmt.Errorf("%w: %s", ErrNotFound, path)
+	}
+	current := fmt.Sprintf(`"%d"`, len(body))
+	if etag == current {
+		f.hits++
+		return Document{ETag: etag, NotModified: true}, nil
+	}
+	return Document{Body: []byte(body), ETag: current}, nil
+}
+
+func TestCachedRegistry(t *testing.T) {
+	inner := &fakeFetcherRegistry{docs: map[string]string{
+		"osmosis/chain.json": `{"chain_name": "osmosis", "chain_id": "osmosis-1"}`,
+		"_IBC/cosmoshub-osmosis.json": `{
+			"chain_1": {"chain_name": "cosmoshub"},
+			"chain_2": {"chain_name": "osmosis"},
+			"channels": [{"chain_1": {"channel_id": "channel-141"}, "chain_2": {"channel_id": "channel-0"}}]
+		}`,
+	}}
+	dir := t.TempDir()
+	cache := NewCachedRegistry(zaptest.NewLogger(t), inner, dir, time.Hour)
+
+	chain, err := cache.GetChain("osmosis")
+	require.NoError(t, err)
+	require.Equal(t, "osmosis-1", chain.ChainID)
+	require.Equal(t, 1, inner.fetches)
+
+	// Within the TTL, the cached document is used without contacting the registry.
+	_, err = cache.GetChain("osmosis")
+	require.NoError(t, err)
+	require.Equal(t, 1, inner.fetches)
+
+	// Once expired, the document is revalidated with its ETag.
+	cache = NewCachedRegistry(zaptest.NewLogger(t), inner, dir, time.Nanosecond)
+	chain, err = cache.GetChain("osmosis")
+	require.NoError(t, err)
+	require.Equal(t, "osmosis-1", chain.ChainID)
+	require.Equal(t, 2, inner.fetches)
+	require.Equal(t, 1, inner.hits)
+
+	// When the registry cannot be reached, stale data is returned.
+	inner.offline = true
+	chain, err = cache.GetChain("osmosis")
+	require.NoError(t, err)
+	require.Equal(t, "osmosis-1", chain.ChainID)
+
+	// Data that was never cached cannot be served offline.
+	_, err = cache.GetChain("juno")
+	require.Error(t, err)
+	inner.offline = false
+
+	conf, err := cache.GetIbcConfig("osmosis", "cosmoshub")
+	require.NoError(t, err)
+	require.Equal(t, "osmosis", conf.Chain1.ChainName)
+	require.Equal(t, "channel-0", conf.Channels[0].Chain1.ChannelId)
+
+	_, err = cache.GetIbcConfig("osmosis", "juno")
+	require.True(t, errors.Is(err, ErrNotFound))
+
+	// Files missing from the registry are cached as missing.
+	status, err := cache.Status()
+	require.NoError(t, err)
+	keys := map[string]bool{}
+	for _, s := range status {
+		keys[s.Key] = s.NotFound
+	}
+	require.Equal(t, map[string]bool{
+		"_IBC/cosmoshub-osmosis.json": false,
+		"_IBC/juno-osmosis.json":      true,
+		"_IBC/osmosis-cosmoshub.json": true,
+		"_IBC/osmosis-juno.json":      true,
+		"osmosis/chain.json":          false,
+	}, keys)
+
+	refreshed, err := cache.Refresh(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 5, refreshed)
+
+	require.NoError(t, cache.Clear())
+	status, err = cache.Status()
+	require.NoError(t, err)
+	require.Empty(t, status)
+}
+
+func TestCachedRegistryNotFound(t *testing.T) {
+	inner := &fakeFetcherRegistry{docs: map[string]string{}}
+	dir := t.TempDir()
+	cache := NewCachedRegistry(zaptest.NewLogger(t), inner, dir, time.Hour)
+	require.Equal(t, DefaultRegistryNotFoundTTL, cache.notFoundTTL)
+
+	_, err := cache.GetAssetList("osmosis")
+	require.True(t, errors.Is(err, ErrNotFound))
+	require.Equal(t, 1, inner.fetches)
+
+	// Within the not found TTL, a missing file is not fetched again, even by a new cache.
+	cache = NewCachedRegistry(zaptest.NewLogger(t), inner, dir, time.Hour)
+	_, err = cache.GetAssetList("osmosis")
+	require.True(t, errors.Is(err, ErrNotFound))
+	require.Equal(t, 1, inner.fetches)
+
+	// Once the not found TTL expired, the file is fetched again and found once it was added.
+	inner.docs["osmosis/assetlist.json"] = `{"chain_name": "osmosis", "assets": [{"base": "uosmo"}]}`
+	cache.notFoundTTL = time.Nanosecond
+	assetList, err := cache.GetAssetList("osmosis")
+	require.NoError(t, err)
+	require.Equal(t, "uosmo", assetList.Assets[0].Base)
+	require.Equal(t, 2, inner.fetches)
+
+	status, err := cache.Status()
+	require.NoError(t, err)
+	require.Len(t, status, 1)
+	require.False(t, status[0].NotFound)
+
+	// The not found TTL never exceeds the TTL of the cache.
+	cache = NewCachedRegistry(zaptest.NewLogger(t), inner, dir, time.Second)
+	require.Equal(t, time.Second, cache.notFoundTTL)
+}
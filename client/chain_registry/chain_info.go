@@ -2,63 +2,186 @@ package chain_registry
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"io/ioutil"
 	"math/rand"
-	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/KyleMoser/cosmos-client/client/rpc"
 	"go.uber.org/zap"
-	"golang.org/x/sync/errgroup"
 )
 
 type ChainInfo struct {
 	log *zap.Logger
+	// registry is the ChainRegistry this ChainInfo was read from.
+	registry ChainRegistry
 
 	Schema       string `json:"$schema"`
 	ChainName    string `json:"chain_name"`
 	Status       string `json:"status"`
 	NetworkType  string `json:"network_type"`
+	Website      string `json:"website,omitempty"`
 	PrettyName   string `json:"pretty_name"`
+	ChainType    string `json:"chain_type,omitempty"`
 	ChainID      string `json:"chain_id"`
 	Bech32Prefix string `json:"bech32_prefix"`
 	DaemonName   string `json:"daemon_name"`
 	NodeHome     string `json:"node_home"`
-	Genesis      struct {
+	// KeyAlgos are the key algorithms supported by the chain, e.g. secp256k1 or ethsecp256k1.
+	KeyAlgos []string `json:"key_algos,omitempty"`
+	Genesis  struct {
 		GenesisURL string `json:"genesis_url"`
 	} `json:"genesis"`
-	Slip44   int `json:"slip44"`
-	Codebase struct {
-		GitRepo            string   `json:"git_repo"`
-		RecommendedVersion string   `json:"recommended_version"`
-		CompatibleVersions []string `json:"compatible_versions"`
-	} `json:"codebase"`
-	Peers struct {
-		Seeds []struct {
-			ID       string `json:"id"`
-			Address  string `json:"address"`
-			Provider string `json:"provider,omitempty"`
-		} `json:"seeds"`
-		PersistentPeers []struct {
-			ID      string `json:"id"`
-			Address string `json:"address"`
-		} `json:"persistent_peers"`
-	} `json:"peers"`
-	Apis struct {
-		RPC []struct {
-			Address  string `json:"address"`
-			Provider string `json:"provider"`
-		} `json:"rpc"`
-		Rest []struct {
-			Address  string `json:"address"`
-			Provider string `json:"provider"`
-		} `json:"rest"`
-	} `json:"apis"`
+	Slip44    int        `json:"slip44"`
+	Fees      Fees       `json:"fees"`
+	Staking   Staking    `json:"staking"`
+	Codebase  Codebase   `json:"codebase"`
+	Peers     Peers      `json:"peers"`
+	Apis      Apis       `json:"apis"`
+	Explorers []Explorer `json:"explorers,omitempty"`
+	Keywords  []string   `json:"keywords,omitempty"`
+	// ExtraCodecs are codecs beyond the Cosmos SDK ones needed to decode the chain's accounts and txs, e.g. ethermint.
+	ExtraCodecs []string `json:"extra_codecs,omitempty"`
+}
+
+// Fees lists the tokens accepted to pay transaction fees.
+type Fees struct {
+	FeeTokens []FeeToken `json:"fee_tokens"`
+}
+
+// FeeToken is a token accepted to pay fees, with the gas prices recommended by the chain.
+type FeeToken struct {
+	Denom            string   `json:"denom"`
+	FixedMinGasPrice float64  `json:"fixed_min_gas_price,omitempty"`
+	LowGasPrice      float64  `json:"low_gas_price,omitempty"`
+	AverageGasPrice  float64  `json:"average_gas_price,omitempty"`
+	HighGasPrice     float64  `json:"high_gas_price,omitempty"`
+	GasCosts         GasCosts `json:"gas_costs,omitempty"`
+}
+
+// GasCosts is the typical gas used by common transactions.
+type GasCosts struct {
+	CosmosSend  int64 `json:"cosmos_send,omitempty"`
+	IbcTransfer int64 `json:"ibc_transfer,omitempty"`
+}
+
+// Staking describes the chain's staking tokens.
+type Staking struct {
+	StakingTokens []StakingToken `json:"staking_tokens"`
+	LockDuration  struct {
+		Blocks int64  `json:"blocks,omitempty"`
+		Time   string `json:"time,omitempty"`
+	} `json:"lock_duration,omitempty"`
+}
+
+type StakingToken struct {
+	Denom string `json:"denom"`
+}
+
+type Codebase struct {
+	GitRepo            string   `json:"git_repo"`
+	RecommendedVersion string   `json:"recommended_version"`
+	CompatibleVersions []string `json:"compatible_versions"`
+	CosmosSdkVersion   string   `json:"cosmos_sdk_version,omitempty"`
+	Consensus          struct {
+		Type    string `json:"type"`
+		Version string `json:"version,omitempty"`
+	} `json:"consensus,omitempty"`
+	CosmwasmVersion string `json:"cosmwasm_version,omitempty"`
+	CosmwasmEnabled bool   `json:"cosmwasm_enabled,omitempty"`
+	IbcGoVersion    string `json:"ibc_go_version,omitempty"`
+}
+
+type Peers struct {
+	Seeds           []Peer `json:"seeds"`
+	PersistentPeers []Peer `json:"persistent_peers"`
+}
+
+type Peer struct {
+	ID       string `json:"id"`
+	Address  string `json:"address"`
+	Provider string `json:"provider,omitempty"`
+}
+
+// Apis lists the public endpoints of the chain, by protocol.
+type Apis struct {
+	RPC     []Endpoint `json:"rpc"`
+	Rest    []Endpoint `json:"rest"`
+	GRPC    []Endpoint `json:"grpc"`
+	Wss     []Endpoint `json:"wss,omitempty"`
+	GRPCWeb []Endpoint `json:"grpc-web,omitempty"`
+}
+
+// Endpoint is a public API endpoint of the chain.
+type Endpoint struct {
+	Address  string `json:"address"`
+	Provider string `json:"provider"`
+	// Archive is set for nodes that do not prune state.
+	Archive bool `json:"archive,omitempty"`
+}
+
+// Explorer is a block explorer of the chain. TxPage and AccountPage are URL templates
+// containing ${txHash} and ${accountAddress} respectively.
+type Explorer struct {
+	Kind        string `json:"kind,omitempty"`
+	URL         string `json:"url"`
+	TxPage      string `json:"tx_page,omitempty"`
+	AccountPage string `json:"account_page,omitempty"`
+}
+
+const (
+	GasPriceLow     = "low"
+	GasPriceAverage = "average"
+	GasPriceHigh    = "high"
+)
+
+// GasPrice returns the gas price of the token at the given level (low, average or high; average by default).
+// Levels the chain does not publish fall back to the closest published level, and the result is never
+// below the fixed minimum gas price.
+func (t FeeToken) GasPrice(level string) float64 {
+	var candidates []float64
+	switch level {
+	case GasPriceLow:
+		candidates = []float64{t.LowGasPrice, t.AverageGasPrice, t.HighGasPrice}
+	case GasPriceHigh:
+		candidates = []float64{t.HighGasPrice, t.AverageGasPrice, t.LowGasPrice}
+	default:
+		candidates = []float64{t.AverageGasPrice, t.LowGasPrice, t.HighGasPrice}
+	}
+
+	price := t.FixedMinGasPrice
+	for _, p := range candidates {
+		if p > 0 {
+			price = p
+			break
+		}
+	}
+	if price < t.FixedMinGasPrice {
+		price = t.FixedMinGasPrice
+	}
+	return price
+}
+
+// GasPrices returns the gas price of the chain's first fee token at the given level, formatted
+// as e.g. 0.0025uatom, or an empty string if the chain does not list any fee tokens.
+func (c ChainInfo) GasPrices(level string) string {
+	if len(c.Fees.FeeTokens) == 0 {
+		return ""
+	}
+	token := c.Fees.FeeTokens[0]
+	return strconv.FormatFloat(token.GasPrice(level), 'f', -1, 64) + token.Denom
+}
+
+// GetGRPCEndpoint returns the first gRPC endpoint listed for the chain, or an empty string if there are none.
+func (c ChainInfo) GetGRPCEndpoint() string {
+	if len(c.Apis.GRPC) == 0 {
+		return ""
+	}
+	return c.Apis.GRPC[0].Address
 }
 
 // NewChainInfo returns a ChainInfo that is uninitialized other than the provided zap.Logger.
@@ -69,29 +192,38 @@ func NewChainInfo(log *zap.Logger) ChainInfo {
 
 func (c ChainInfo) GetAllRPCEndpoints() (out []string, err error) {
 	for _, endpoint := range c.Apis.RPC {
-		u, err := url.Parse(endpoint.Address)
+		addr, err := normalizeRPCAddress(endpoint.Address)
 		if err != nil {
 			return nil, err
 		}
+		out = append(out, addr)
+	}
 
-		var port string
-		if u.Port() == "" {
-			switch u.Scheme {
-			case "https":
-				port = "443"
-			case "http":
-				port = "80"
-			default:
-				return nil, fmt.Errorf("invalid or unsupported url scheme: %v", u.Scheme)
-			}
-		} else {
-			port = u.Port()
-		}
+	return
+}
 
-		out = append(out, fmt.Sprintf("%s://%s:%s%s", u.Scheme, u.Hostname(), port, u.Path))
+// normalizeRPCAddress adds the default port of the scheme to the address if it does not have one.
+func normalizeRPCAddress(address string) (string, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return "", err
 	}
 
-	return
+	var port string
+	if u.Port() == "" {
+		switch u.Scheme {
+		case "https":
+			port = "443"
+		case "http":
+			port = "80"
+		default:
+			return "", fmt.Errorf("invalid or unsupported url scheme: %v", u.Scheme)
+		}
+	} else {
+		port = u.Port()
+	}
+
+	return fmt.Sprintf("%s://%s:%s%s", u.Scheme, u.Hostname(), port, u.Path), nil
 }
 
 func IsHealthyRPC(ctx context.Context, endpoint string) error {
@@ -111,143 +243,91 @@ func IsHealthyRPC(ctx context.Context, endpoint string) error {
 	return nil
 }
 
-func (c ChainInfo) GetRPCEndpointWithDomain(ctx context.Context, preferredDomains []string) (out string, err error) {
-	allRPCEndpoints, err := c.GetAllRPCEndpoints()
-	if err != nil {
-		return "", err
-	}
-
-	var eg errgroup.Group
-	var endpoints []string
-	healthy := 0
-	unhealthy := 0
-	for _, endpoint := range allRPCEndpoints {
+// healthyEndpoints checks the given endpoints concurrently and returns the healthy ones.
+func (c ChainInfo) healthyEndpoints(ctx context.Context, endpoints []string) []string {
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		healthy   []string
+		unhealthy int
+	)
+	for _, endpoint := range endpoints {
 		endpoint := endpoint
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := IsHealthyRPC(ctx, endpoint)
 
-		url, err := url.Parse(endpoint)
-		if err == nil {
-			host := url.Hostname()
-			isMatch := false
-			for _, domain := range preferredDomains {
-				if strings.Contains(host, domain) {
-					isMatch = true
-				}
-			}
-
-			if isMatch {
-				eg.Go(func() error {
-					err := IsHealthyRPC(ctx, endpoint)
-					if err != nil {
-						unhealthy += 1
-						c.log.Debug(
-							"Ignoring endpoint due to error",
-							zap.String("endpoint", endpoint),
-							zap.Error(err),
-						)
-						return nil
-					}
-					healthy += 1
-					c.log.Debug("Verified healthy endpoint", zap.String("endpoint", endpoint))
-					endpoints = append(endpoints, endpoint)
-					return nil
-				})
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				unhealthy += 1
+				c.log.Debug(
+					"Ignoring endpoint due to error",
+					zap.String("endpoint", endpoint),
+					zap.Error(err),
+				)
+				return
 			}
-		}
-	}
-	if err := eg.Wait(); err != nil {
-		return "", err
+			c.log.Debug("Verified healthy endpoint", zap.String("endpoint", endpoint))
+			healthy = append(healthy, endpoint)
+		}()
 	}
+	wg.Wait()
+
 	c.log.Info("Endpoints queried",
 		zap.String("chain_name", c.ChainName),
-		zap.Int("healthy", healthy),
+		zap.Int("healthy", len(healthy)),
 		zap.Int("unhealthy", unhealthy),
 	)
-	if len(endpoints) == 0 {
-		return "", fmt.Errorf("no working RPCs found")
-	}
-
-	randomGenerator := rand.New(rand.NewSource(time.Now().UnixNano()))
-	endpoint := endpoints[randomGenerator.Intn(len(endpoints))]
-	c.log.Info("Endpoint selected",
-		zap.String("chain_name", c.ChainName),
-		zap.String("endpoint", endpoint),
-	)
-	return endpoint, nil
+	return healthy
 }
 
-func (c ChainInfo) GetRPCEndpoints(ctx context.Context) (out []string, err error) {
+func (c ChainInfo) GetRPCEndpointWithDomain(ctx context.Context, preferredDomains []string) (out string, err error) {
 	allRPCEndpoints, err := c.GetAllRPCEndpoints()
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
-	var eg errgroup.Group
-	var endpoints []string
-	healthy := 0
-	unhealthy := 0
+	var matching []string
 	for _, endpoint := range allRPCEndpoints {
-		endpoint := endpoint
-		eg.Go(func() error {
-			err := IsHealthyRPC(ctx, endpoint)
-			if err != nil {
-				unhealthy += 1
-				c.log.Debug(
-					"Ignoring endpoint due to error",
-					zap.String("endpoint", endpoint),
-					zap.Error(err),
-				)
-				return nil
+		url, err := url.Parse(endpoint)
+		if err != nil {
+			continue
+		}
+		host := url.Hostname()
+		for _, domain := range preferredDomains {
+			if strings.Contains(host, domain) {
+				matching = append(matching, endpoint)
+				break
 			}
-			healthy += 1
-			c.log.Debug("Verified healthy endpoint", zap.String("endpoint", endpoint))
-			endpoints = append(endpoints, endpoint)
-			return nil
-		})
+		}
 	}
-	if err := eg.Wait(); err != nil {
+
+	return c.selectRandom(c.healthyEndpoints(ctx, matching))
+}
+
+func (c ChainInfo) GetRPCEndpoints(ctx context.Context) (out []string, err error) {
+	allRPCEndpoints, err := c.GetAllRPCEndpoints()
+	if err != nil {
 		return nil, err
 	}
-	c.log.Info("Endpoints queried",
-		zap.String("chain_name", c.ChainName),
-		zap.Int("healthy", healthy),
-		zap.Int("unhealthy", unhealthy),
-	)
-	return endpoints, nil
+	return c.healthyEndpoints(ctx, allRPCEndpoints), nil
 }
 
 func (c ChainInfo) GetPreferredRPCEndpoint(ctx context.Context, preferredEndpoints []string) (string, error) {
-	var eg errgroup.Group
-	var endpoints []string
-	healthy := 0
-	unhealthy := 0
-	for _, endpoint := range preferredEndpoints {
-		endpoint := endpoint
-		eg.Go(func() error {
-			err := IsHealthyRPC(ctx, endpoint)
-			if err != nil {
-				unhealthy += 1
-				c.log.Debug(
-					"Ignoring endpoint due to error",
-					zap.String("endpoint", endpoint),
-					zap.Error(err),
-				)
-				return nil
-			}
-			healthy += 1
-			c.log.Debug("Verified healthy endpoint", zap.String("endpoint", endpoint))
-			endpoints = append(endpoints, endpoint)
-			return nil
-		})
-	}
-	if err := eg.Wait(); err != nil {
+	return c.selectRandom(c.healthyEndpoints(ctx, preferredEndpoints))
+}
+
+func (c ChainInfo) GetRandomRPCEndpoint(ctx context.Context) (string, error) {
+	rpcs, err := c.GetRPCEndpoints(ctx)
+	if err != nil {
 		return "", err
 	}
-	c.log.Info("Endpoints queried",
-		zap.String("chain_name", c.ChainName),
-		zap.Int("healthy", healthy),
-		zap.Int("unhealthy", unhealthy),
-	)
+	return c.selectRandom(rpcs)
+}
 
+func (c ChainInfo) selectRandom(endpoints []string) (string, error) {
 	if len(endpoints) == 0 {
 		return "", fmt.Errorf("no working RPCs found")
 	}
@@ -261,49 +341,54 @@ func (c ChainInfo) GetPreferredRPCEndpoint(ctx context.Context, preferredEndpoin
 	return endpoint, nil
 }
 
-func (c ChainInfo) GetRandomRPCEndpoint(ctx context.Context) (string, error) {
-	rpcs, err := c.GetRPCEndpoints(ctx)
+// ProbeRPCEndpoints probes every RPC endpoint of the chain for latency, history, tx indexing and version.
+func (c ChainInfo) ProbeRPCEndpoints(ctx context.Context) ([]EndpointProbe, error) {
+	endpoints := make([]Endpoint, 0, len(c.Apis.RPC))
+	for _, endpoint := range c.Apis.RPC {
+		addr, err := normalizeRPCAddress(endpoint.Address)
+		if err != nil {
+			return nil, err
+		}
+		endpoint.Address = addr
+		endpoints = append(endpoints, endpoint)
+	}
+	return NewEndpointProber(c.log, 5*time.Second).Probe(ctx, endpoints), nil
+}
+
+// GetRPCEndpointWithPolicy probes every RPC endpoint of the chain and selects the best one according to the policy.
+func (c ChainInfo) GetRPCEndpointWithPolicy(ctx context.Context, policy SelectionPolicy) (string, error) {
+	probes, err := c.ProbeRPCEndpoints(ctx)
 	if err != nil {
 		return "", err
 	}
-
-	if len(rpcs) == 0 {
-		return "", fmt.Errorf("no working RPCs found")
+	endpoint, err := SelectEndpoint(probes, policy)
+	if err != nil {
+		return "", err
 	}
-
-	randomGenerator := rand.New(rand.NewSource(time.Now().UnixNano()))
-	endpoint := rpcs[randomGenerator.Intn(len(rpcs))]
 	c.log.Info("Endpoint selected",
 		zap.String("chain_name", c.ChainName),
 		zap.String("endpoint", endpoint),
+		zap.String("policy", string(policy)),
 	)
 	return endpoint, nil
 }
 
+// GetAssetList returns the asset list of this chain from the registry the ChainInfo was read from.
 func (c ChainInfo) GetAssetList() (AssetList, error) {
-	chainRegURL := fmt.Sprintf("https://raw.githubusercontent.com/cosmos/chain-registry/master/%s/assetlist.json", c.ChainName)
-
-	res, err := http.Get(chainRegURL)
-	if err != nil {
-		return AssetList{}, err
-	}
-	defer res.Body.Close()
-	if res.StatusCode == http.StatusNotFound {
-		return AssetList{}, fmt.Errorf("chain not found on registry: response code: %d: GET failed: %s", res.StatusCode, chainRegURL)
-	}
-	if res.StatusCode != http.StatusOK {
-		return AssetList{}, fmt.Errorf("response code: %d: GET failed: %s", res.StatusCode, chainRegURL)
+	registry := c.registry
+	if registry == nil {
+		log := c.log
+		if log == nil {
+			log = zap.NewNop()
+		}
+		registry = DefaultChainRegistry(log)
 	}
-
-	body, err := ioutil.ReadAll(res.Body)
+	assetList, err := registry.GetAssetList(c.ChainName)
 	if err != nil {
 		return AssetList{}, err
 	}
-
-	var assetList AssetList
-	if err := json.Unmarshal([]byte(body), &assetList); err != nil {
-		return AssetList{}, err
+	if assetList.ChainID == "" {
+		assetList.ChainID = c.ChainID
 	}
 	return assetList, nil
-
 }
@@ -65,22 +65,57 @@ func TestGetAllRPCEndpoints(t *testing.T) {
 	}
 }
 
+func TestGasPrices(t *testing.T) {
+	testCases := map[string]struct {
+		feeTokens []FeeToken
+		level     string
+		expected  string
+	}{
+		"average gas price": {
+			feeTokens: []FeeToken{{Denom: "uatom", LowGasPrice: 0.005, AverageGasPrice: 0.025, HighGasPrice: 0.03}},
+			expected:  "0.025uatom",
+		},
+		"high gas price": {
+			feeTokens: []FeeToken{{Denom: "uatom", LowGasPrice: 0.005, AverageGasPrice: 0.025, HighGasPrice: 0.03}},
+			level:     GasPriceHigh,
+			expected:  "0.03uatom",
+		},
+		"missing level falls back": {
+			feeTokens: []FeeToken{{Denom: "uusdc", LowGasPrice: 0.1}},
+			level:     GasPriceHigh,
+			expected:  "0.1uusdc",
+		},
+		"fixed minimum gas price": {
+			feeTokens: []FeeToken{{Denom: "uosmo", FixedMinGasPrice: 0.0025, LowGasPrice: 0.001}},
+			level:     GasPriceLow,
+			expected:  "0.0025uosmo",
+		},
+		"zero fee chain": {
+			feeTokens: []FeeToken{{Denom: "ufree"}},
+			expected:  "0ufree",
+		},
+		"first fee token is preferred": {
+			feeTokens: []FeeToken{{Denom: "uosmo", AverageGasPrice: 0.025}, {Denom: "uion", AverageGasPrice: 1}},
+			expected:  "0.025uosmo",
+		},
+		"no fee tokens": {
+			expected: "",
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			c := ChainInfo{Fees: Fees{FeeTokens: tc.feeTokens}}
+			require.Equal(t, tc.expected, c.GasPrices(tc.level))
+		})
+	}
+}
+
 func ChainInfoWithRPCEndpoint(endpoint string) ChainInfo {
 	return ChainInfo{
-		Apis: struct {
-			RPC []struct {
-				Address  string `json:"address"`
-				Provider string `json:"provider"`
-			} `json:"rpc"`
-			Rest []struct {
-				Address  string `json:"address"`
-				Provider string `json:"provider"`
-			} `json:"rest"`
-		}{
-			RPC: []struct {
-				Address  string `json:"address"`
-				Provider string `json:"provider"`
-			}{
+		Apis: Apis{
+			RPC: []Endpoint{
 				{
 					Address:  endpoint,
 					Provider: "test",
@@ -2,16 +2,173 @@ package chain_registry
 
 import (
 	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/KyleMoser/cosmos-client/client/throttle"
+	"github.com/KyleMoser/cosmos-client/client/transport"
 	"go.uber.org/zap"
 )
 
 type ChainRegistry interface {
 	GetChain(name string) (ChainInfo, error)
 	ListChains(ctx context.Context) ([]string, error)
+	// GetAssetList returns the assetlist.json of the named chain.
+	GetAssetList(name string) (AssetList, error)
+	// GetIbcConfig returns the IBC connection details between two chains, oriented so that
+	// chainA is Chain1 regardless of how the file is named in the registry.
+	GetIbcConfig(chainA, chainB string) (IbcConfig, error)
+	// ListIbcPaths returns the chain pairs of every IBC config in the registry.
+	ListIbcPaths(ctx context.Context) ([]IbcPath, error)
 	SourceLink() string
 }
 
+const (
+	// RegistryTypeGithub reads the registry from github.com/cosmos/chain-registry.
+	RegistryTypeGithub = "github"
+	// RegistryTypeLocal reads the registry from a checkout of cosmos/chain-registry on disk.
+	RegistryTypeLocal = "local"
+	// RegistryTypeGit is a local registry which is cloned from URL first if Path does not exist yet.
+	RegistryTypeGit = "git"
+)
+
+const (
+	NetworkTypeMainnet = "mainnet"
+	NetworkTypeTestnet = "testnet"
+	NetworkTypeDevnet  = "devnet"
+)
+
+// networkDir returns the registry directory holding chains of the given network type.
+// Mainnet chains are at the root of the registry.
+func networkDir(networkType string) (string, error) {
+	switch networkType {
+	case "", NetworkTypeMainnet:
+		return "", nil
+	case NetworkTypeTestnet:
+		return "testnets", nil
+	case NetworkTypeDevnet:
+		return "devnets", nil
+	default:
+		return "", fmt.Errorf("unknown network type %q, expected %q, %q or %q", networkType, NetworkTypeMainnet, NetworkTypeTestnet, NetworkTypeDevnet)
+	}
+}
+
+// isChainDir reports whether the registry directory with the given name may hold a chain,
+// as opposed to e.g. _IBC, .github or the directories of other network types.
+func isChainDir(name string) bool {
+	if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+		return false
+	}
+	return name != "testnets" && name != "devnets"
+}
+
+// RegistryConfig selects the chain registry backend.
+type RegistryConfig struct {
+	// Name identifies the registry when several registries are configured, e.g. with lens chains add --registry.
+	// It defaults to the type, and must be unique as it also names the cache directory of the registry.
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+	Type string `yaml:"type" json:"type"`
+	// NetworkType selects mainnet (the default), testnet or devnet chains of the registry.
+	NetworkType string `yaml:"network-type,omitempty" json:"network-type,omitempty"`
+	// Path is the directory of the chain registry checkout, for the local and git types.
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+	// URL is the git remote cloned into Path, for the git type.
+	URL string `yaml:"url,omitempty" json:"url,omitempty"`
+	// Owner, Repo and Ref select the GitHub repository and branch (or tag, or commit) of the github type.
+	// They default to the master branch of github.com/cosmos/chain-registry.
+	Owner string `yaml:"owner,omitempty" json:"owner,omitempty"`
+	Repo  string `yaml:"repo,omitempty" json:"repo,omitempty"`
+	Ref   string `yaml:"ref,omitempty" json:"ref,omitempty"`
+	// BaseURL overrides the URL raw files of the github type are fetched from, e.g. for a mirror.
+	BaseURL string `yaml:"base-url,omitempty" json:"base-url,omitempty"`
+	// CacheTTL is how long remote registry data is cached on disk before it is revalidated, e.g. "1h".
+	// Defaults to DefaultRegistryCacheTTL.
+	CacheTTL string `yaml:"cache-ttl,omitempty" json:"cache-ttl,omitempty"`
+	// DisableCache fetches remote registry data on every call.
+	DisableCache bool `yaml:"disable-cache,omitempty" json:"disable-cache,omitempty"`
+	// Retry and RateLimit configure how requests of the github type are retried and rate limited.
+	Retry     *throttle.RetryConfig     `yaml:"retry,omitempty" json:"retry,omitempty"`
+	RateLimit *throttle.RateLimitConfig `yaml:"rate-limit,omitempty" json:"rate-limit,omitempty"`
+	// Transport sets headers, authentication, a proxy and TLS options for requests of the github type.
+	Transport *transport.Config `yaml:"transport,omitempty" json:"transport,omitempty"`
+}
+
+// IsRemote reports whether the registry is read over the network, as opposed to from a local checkout.
+func (cfg RegistryConfig) IsRemote() bool {
+	return cfg.Type == "" || cfg.Type == RegistryTypeGithub
+}
+
+// GetCacheTTL parses CacheTTL, returning DefaultRegistryCacheTTL if it is not set.
+func (cfg RegistryConfig) GetCacheTTL() (time.Duration, error) {
+	if cfg.CacheTTL == "" {
+		return DefaultRegistryCacheTTL, nil
+	}
+	ttl, err := time.ParseDuration(cfg.CacheTTL)
+	if err != nil {
+		return 0, fmt.Errorf("invalid registry cache-ttl %q: %w", cfg.CacheTTL, err)
+	}
+	return ttl, nil
+}
+
 func DefaultChainRegistry(log *zap.Logger) ChainRegistry {
 	return NewCosmosGithubRegistry(log.With(zap.String("registry", "cosmos_github")))
 }
+
+// GetName returns the name of the registry, which defaults to its type.
+func (cfg RegistryConfig) GetName() string {
+	if cfg.Name != "" {
+		return cfg.Name
+	}
+	if cfg.Type == "" {
+		return RegistryTypeGithub
+	}
+	return cfg.Type
+}
+
+// NewChainRegistry returns the ChainRegistry described by cfg.
+// An empty config returns the DefaultChainRegistry.
+func NewChainRegistry(ctx context.Context, log *zap.Logger, cfg RegistryConfig) (ChainRegistry, error) {
+	log = log.With(zap.String("registry", cfg.GetName()))
+	switch cfg.Type {
+	case "", RegistryTypeGithub:
+		policy, err := cfg.Retry.Policy()
+		if err != nil {
+			return nil, err
+		}
+		if err := cfg.RateLimit.Validate(); err != nil {
+			return nil, err
+		}
+		return NewGithubRegistry(log, GithubRegistryOptions{
+			Owner:       cfg.Owner,
+			Repo:        cfg.Repo,
+			Ref:         cfg.Ref,
+			BaseURL:     cfg.BaseURL,
+			NetworkType: cfg.NetworkType,
+			RetryPolicy: &policy,
+			Limiter:     cfg.RateLimit.NewLimiter(),
+			Transport:   cfg.Transport,
+		})
+	case RegistryTypeLocal:
+		return newLocalRegistryForNetwork(log, cfg.Path, cfg.NetworkType)
+	case RegistryTypeGit:
+		if err := CloneRegistry(ctx, cfg.URL, cfg.Path); err != nil {
+			return nil, err
+		}
+		return newLocalRegistryForNetwork(log, cfg.Path, cfg.NetworkType)
+	default:
+		return nil, fmt.Errorf("unknown chain registry type %q, expected %q, %q or %q", cfg.Type, RegistryTypeGithub, RegistryTypeLocal, RegistryTypeGit)
+	}
+}
+
+func newLocalRegistryForNetwork(log *zap.Logger, dir, networkType string) (ChainRegistry, error) {
+	sub, err := networkDir(networkType)
+	if err != nil {
+		return nil, err
+	}
+	if dir != "" && sub != "" {
+		dir = filepath.Join(dir, sub)
+	}
+	return NewLocalRegistry(log, dir)
+}
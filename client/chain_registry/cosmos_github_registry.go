@@ -3,43 +3,127 @@ package chain_registry
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"net/http"
+	"path"
 	"strings"
 	"time"
 
+	"github.com/KyleMoser/cosmos-client/client/throttle"
+	"github.com/KyleMoser/cosmos-client/client/transport"
 	"github.com/google/go-github/v43/github"
 	"go.uber.org/zap"
 )
 
+const (
+	defaultGithubOwner = "cosmos"
+	defaultGithubRepo  = "chain-registry"
+	defaultGithubRef   = "master"
+)
+
+// ErrNotFound is returned when a chain, asset list or IBC config does not exist in the registry.
+var ErrNotFound = errors.New("not found on chain registry")
+
+// GithubRegistryOptions selects the GitHub repository, branch and network a CosmosGithubRegistry reads from.
+// Empty fields default to the master branch of github.com/cosmos/chain-registry and mainnet chains.
+type GithubRegistryOptions struct {
+	Owner string
+	Repo  string
+	Ref   string
+	// BaseURL overrides the URL raw registry files are fetched from, e.g. a mirror of the registry.
+	// Defaults to https://raw.githubusercontent.com/<owner>/<repo>/<ref>.
+	BaseURL string
+	// NetworkType is one of NetworkTypeMainnet (the default), NetworkTypeTestnet or NetworkTypeDevnet.
+	NetworkType string
+	// RetryPolicy retries failed requests. Defaults to throttle.DefaultRetryPolicy.
+	RetryPolicy *throttle.RetryPolicy
+	// Limiter limits the rate of requests. If it is nil, requests are only paused when GitHub sends Retry-After.
+	Limiter *throttle.Limiter
+	// Transport sets headers, authentication, a proxy and TLS options for requests to GitHub or the mirror.
+	Transport *transport.Config
+}
+
 type CosmosGithubRegistry struct {
-	log *zap.Logger
+	log    *zap.Logger
+	client *http.Client
+	opts   GithubRegistryOptions
+	// dir is the registry directory holding chains of the configured network type.
+	dir string
 }
 
 func NewCosmosGithubRegistry(log *zap.Logger) CosmosGithubRegistry {
-	return CosmosGithubRegistry{log: log}
+	registry, _ := NewGithubRegistry(log, GithubRegistryOptions{})
+	return registry
+}
+
+// NewGithubRegistry returns a registry reading from the GitHub repository selected by opts.
+func NewGithubRegistry(log *zap.Logger, opts GithubRegistryOptions) (CosmosGithubRegistry, error) {
+	dir, err := networkDir(opts.NetworkType)
+	if err != nil {
+		return CosmosGithubRegistry{}, err
+	}
+	if opts.Owner == "" {
+		opts.Owner = defaultGithubOwner
+	}
+	if opts.Repo == "" {
+		opts.Repo = defaultGithubRepo
+	}
+	if opts.Ref == "" {
+		opts.Ref = defaultGithubRef
+	}
+	if opts.BaseURL == "" {
+		opts.BaseURL = fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s", opts.Owner, opts.Repo, opts.Ref)
+	}
+	opts.BaseURL = strings.TrimSuffix(opts.BaseURL, "/")
+	policy := throttle.DefaultRetryPolicy()
+	if opts.RetryPolicy != nil {
+		policy = *opts.RetryPolicy
+	}
+	if opts.Limiter == nil {
+		opts.Limiter = throttle.NewLimiter(0, 1)
+	}
+	base, err := opts.Transport.HTTPTransport(nil)
+	if err != nil {
+		return CosmosGithubRegistry{}, err
+	}
+
+	return CosmosGithubRegistry{
+		log: log,
+		client: &http.Client{
+			Timeout:   time.Minute,
+			Transport: &throttle.Transport{Base: base, Policy: policy, Limiter: opts.Limiter},
+		},
+		opts: opts,
+		dir:  dir,
+	}, nil
 }
 
 func (c CosmosGithubRegistry) ListChains(ctx context.Context) ([]string, error) {
-	client := github.NewClient(http.DefaultClient)
+	client := github.NewClient(c.client)
 	var chains []string
 
 	ctx, cancel := context.WithTimeout(ctx, time.Minute*5)
 	defer cancel()
 
+	// The tree of a subdirectory is addressed as <ref>:<path>.
+	treeSHA := c.opts.Ref
+	if c.dir != "" {
+		treeSHA += ":" + c.dir
+	}
 	tree, res, err := client.Git.GetTree(
 		ctx,
-		"cosmos",
-		"chain-registry",
-		"master",
+		c.opts.Owner,
+		c.opts.Repo,
+		treeSHA,
 		false)
 	if err != nil || res.StatusCode != 200 {
 		return chains, err
 	}
 
 	for _, entry := range tree.Entries {
-		if *entry.Type == "tree" && !strings.HasPrefix(*entry.Path, ".") {
+		if *entry.Type == "tree" && isChainDir(*entry.Path) {
 			chains = append(chains, *entry.Path)
 		}
 	}
@@ -47,31 +131,119 @@ func (c CosmosGithubRegistry) ListChains(ctx context.Context) ([]string, error)
 }
 
 func (c CosmosGithubRegistry) GetChain(name string) (ChainInfo, error) {
-	chainRegURL := fmt.Sprintf("https://raw.githubusercontent.com/cosmos/chain-registry/master/%s/chain.json", name)
+	result := NewChainInfo(c.log.With(zap.String("chain_name", name)))
+	if err := c.getJSON(name+"/chain.json", &result); err != nil {
+		return ChainInfo{}, err
+	}
+	result.registry = c
+	return result, nil
+}
+
+func (c CosmosGithubRegistry) GetAssetList(name string) (AssetList, error) {
+	var assetList AssetList
+	if err := c.getJSON(name+"/assetlist.json", &assetList); err != nil {
+		return AssetList{}, err
+	}
+	return assetList, nil
+}
 
-	res, err := http.Get(chainRegURL)
+func (c CosmosGithubRegistry) GetIbcConfig(chainA, chainB string) (IbcConfig, error) {
+	file, reversedFile := ibcConfigPaths(chainA, chainB)
+
+	var conf IbcConfig
+	err := c.getJSON(file, &conf)
+	if errors.Is(err, ErrNotFound) {
+		if err := c.getJSON(reversedFile, &conf); err != nil {
+			return IbcConfig{}, err
+		}
+		return conf.Reversed(), nil
+	}
 	if err != nil {
-		return ChainInfo{}, err
+		return IbcConfig{}, err
 	}
-	defer res.Body.Close()
-	if res.StatusCode == http.StatusNotFound {
-		return ChainInfo{}, fmt.Errorf("chain not found on registry: response code: %d: GET failed: %s", res.StatusCode, chainRegURL)
+	return conf, nil
+}
+
+func (c CosmosGithubRegistry) ListIbcPaths(ctx context.Context) ([]IbcPath, error) {
+	client := github.NewClient(c.client)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Minute*5)
+	defer cancel()
+
+	tree, res, err := client.Git.GetTree(
+		ctx,
+		c.opts.Owner,
+		c.opts.Repo,
+		c.opts.Ref+":"+path.Join(c.dir, "_IBC"),
+		false)
+	if res != nil && res.StatusCode == http.StatusNotFound {
+		return nil, nil
 	}
-	if res.StatusCode != http.StatusOK {
-		return ChainInfo{}, fmt.Errorf("response code: %d: GET failed: %s", res.StatusCode, chainRegURL)
+	if err != nil {
+		return nil, err
 	}
 
-	result := NewChainInfo(c.log.With(zap.String("chain_name", name)))
-	body, err := ioutil.ReadAll(res.Body)
+	var paths []IbcPath
+	for _, entry := range tree.Entries {
+		if *entry.Type != "blob" {
+			continue
+		}
+		if p, ok := parseIbcPath(*entry.Path); ok {
+			paths = append(paths, p)
+		}
+	}
+	sortIbcPaths(paths)
+	return paths, nil
+}
+
+// getJSON fetches the given file of the registry and unmarshals it into out.
+func (c CosmosGithubRegistry) getJSON(file string, out interface{}) error {
+	doc, err := c.FetchDocument(context.Background(), file, "")
 	if err != nil {
-		return ChainInfo{}, err
+		return err
 	}
-	if err := json.Unmarshal([]byte(body), &result); err != nil {
-		return ChainInfo{}, err
+	return json.Unmarshal(doc.Body, out)
+}
+
+// FetchDocument fetches the given file of the registry, relative to the directory of its network type. If etag is set the request
+// is conditional, and an unchanged file is reported with Document.NotModified instead of a body.
+func (c CosmosGithubRegistry) FetchDocument(ctx context.Context, file, etag string) (Document, error) {
+	chainRegURL := fmt.Sprintf("%s/%s", c.opts.BaseURL, path.Join(c.dir, file))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, chainRegURL, nil)
+	if err != nil {
+		return Document{}, err
 	}
-	return result, nil
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return Document{}, err
+	}
+	defer res.Body.Close()
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		return Document{ETag: etag, NotModified: true}, nil
+	case http.StatusNotFound:
+		return Document{}, fmt.Errorf("%w: response code: %d: GET failed: %s", ErrNotFound, res.StatusCode, chainRegURL)
+	default:
+		return Document{}, fmt.Errorf("response code: %d: GET failed: %s", res.StatusCode, chainRegURL)
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return Document{}, err
+	}
+	return Document{Body: body, ETag: res.Header.Get("ETag")}, nil
 }
 
 func (c CosmosGithubRegistry) SourceLink() string {
-	return "https://github.com/cosmos/chain-registry"
+	link := fmt.Sprintf("https://github.com/%s/%s", c.opts.Owner, c.opts.Repo)
+	if c.opts.Ref != defaultGithubRef || c.dir != "" {
+		link = fmt.Sprintf("%s/tree/%s/%s", link, c.opts.Ref, c.dir)
+	}
+	return strings.TrimSuffix(link, "/")
 }
@@ -0,0 +1,150 @@
+package chain_registry
+
+import (
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+)
+
+// ResolvedDenom describes a denom held on a chain, as far as it is known from the chain's asset list.
+type ResolvedDenom struct {
+	Denom    string `json:"denom"`
+	Symbol   string `json:"symbol,omitempty"`
+	Display  string `json:"display,omitempty"`
+	Exponent int    `json:"exponent"`
+	// BaseDenom is the denom of the asset on the chain it was created on.
+	BaseDenom string `json:"base_denom"`
+	// OriginChain is the chain an IBC denom was transferred from.
+	OriginChain string `json:"origin_chain,omitempty"`
+	// Path is the IBC denom trace path of an IBC denom, e.g. transfer/channel-0.
+	Path string `json:"path,omitempty"`
+	// Known is set when the denom is listed in the chain's asset list.
+	Known bool `json:"known"`
+}
+
+// DisplayCoin is a coin converted to the display unit of its asset, e.g. 1.5 ATOM instead of 1500000uatom.
+type DisplayCoin struct {
+	ResolvedDenom
+	Amount        string `json:"amount"`
+	DisplayAmount string `json:"display_amount"`
+}
+
+// String renders the coin in display units, e.g. "1.5 ATOM", falling back to the base amount and denom.
+func (c DisplayCoin) String() string {
+	switch {
+	case c.Symbol != "":
+		return c.DisplayAmount + " " + c.Symbol
+	case c.Display != "":
+		return c.DisplayAmount + " " + c.Display
+	default:
+		return c.Amount + c.Denom
+	}
+}
+
+// DenomResolver maps the denoms held on a chain, including ibc/HASH denoms, to the assets
+// of the chain's asset list.
+type DenomResolver struct {
+	denoms map[string]ResolvedDenom
+}
+
+// NewDenomResolver indexes the given asset list by denom. IBC assets are indexed by the
+// ibc/HASH denom derived from the trace path of the asset on the chain.
+func NewDenomResolver(assetList AssetList) *DenomResolver {
+	r := &DenomResolver{denoms: map[string]ResolvedDenom{}}
+	for _, asset := range assetList.Assets {
+		resolved := ResolvedDenom{
+			Denom:     asset.Base,
+			Symbol:    asset.Symbol,
+			Display:   asset.Display,
+			Exponent:  asset.DisplayExponent(),
+			BaseDenom: asset.Base,
+			Known:     true,
+		}
+
+		var trace transfertypes.DenomTrace
+		if t, ok := asset.IbcTrace(); ok {
+			resolved.OriginChain = t.Counterparty.ChainName
+			resolved.BaseDenom = t.Counterparty.BaseDenom
+			if t.Chain.Path != "" {
+				trace = transfertypes.ParseDenomTrace(t.Chain.Path)
+			} else if t.Chain.ChannelID != "" {
+				trace = transfertypes.DenomTrace{Path: portOrTransfer(t.Chain.Port) + "/" + t.Chain.ChannelID, BaseDenom: t.Counterparty.BaseDenom}
+			}
+		} else if asset.Ibc != nil {
+			trace = transfertypes.ParseDenomTrace(transfertypes.PortID + "/" + asset.Ibc.DstChannel + "/" + asset.Ibc.SourceDenom)
+		}
+		if trace.Path != "" {
+			resolved.Path = trace.Path
+			resolved.BaseDenom = trace.BaseDenom
+			if ibcDenom := trace.IBCDenom(); ibcDenom != asset.Base {
+				d := resolved
+				d.Denom = ibcDenom
+				r.denoms[ibcDenom] = d
+			}
+		}
+		r.denoms[asset.Base] = resolved
+	}
+	return r
+}
+
+func portOrTransfer(port string) string {
+	if port == "" {
+		return transfertypes.PortID
+	}
+	return port
+}
+
+// AddDenomTrace registers an IBC denom trace, typically queried from the chain, so that
+// ibc/HASH denoms missing from the asset list still resolve to their path and base denom.
+func (r *DenomResolver) AddDenomTrace(trace transfertypes.DenomTrace) {
+	denom := trace.IBCDenom()
+	if _, ok := r.denoms[denom]; ok {
+		return
+	}
+	r.denoms[denom] = ResolvedDenom{Denom: denom, BaseDenom: trace.BaseDenom, Path: trace.Path}
+}
+
+// Resolve returns what is known about the denom. Unknown denoms resolve to themselves with an exponent of 0.
+func (r *DenomResolver) Resolve(denom string) ResolvedDenom {
+	if resolved, ok := r.denoms[denom]; ok {
+		return resolved
+	}
+	return ResolvedDenom{Denom: denom, BaseDenom: denom}
+}
+
+// ToDisplay converts the coin to the display unit of its asset.
+func (r *DenomResolver) ToDisplay(coin sdk.Coin) DisplayCoin {
+	resolved := r.Resolve(coin.Denom)
+	amount := coin.Amount.String()
+	return DisplayCoin{
+		ResolvedDenom: resolved,
+		Amount:        amount,
+		DisplayAmount: shiftDecimal(amount, resolved.Exponent),
+	}
+}
+
+// Humanize converts every coin to the display unit of its asset.
+func (r *DenomResolver) Humanize(coins sdk.Coins) []DisplayCoin {
+	out := make([]DisplayCoin, len(coins))
+	for i, coin := range coins {
+		out[i] = r.ToDisplay(coin)
+	}
+	return out
+}
+
+// shiftDecimal divides the non-negative integer amount by 10^exp, without loss of precision,
+// and renders it without trailing zeros.
+func shiftDecimal(amount string, exp int) string {
+	if exp <= 0 {
+		return amount
+	}
+	if len(amount) <= exp {
+		amount = strings.Repeat("0", exp-len(amount)+1) + amount
+	}
+	whole, frac := amount[:len(amount)-exp], strings.TrimRight(amount[len(amount)-exp:], "0")
+	if frac == "" {
+		return whole
+	}
+	return whole + "." + frac
+}
@@ -0,0 +1,74 @@
+package chain_registry
+
+import (
+	"encoding/json"
+	"testing"
+
+	sdkmath "cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+	"github.com/stretchr/testify/require"
+)
+
+const osmosisAssetList = `{
+	"chain_name": "osmosis",
+	"assets": [
+		{
+			"base": "uosmo",
+			"display": "osmo",
+			"symbol": "OSMO",
+			"denom_units": [{"denom": "uosmo", "exponent": 0}, {"denom": "osmo", "exponent": 6}]
+		},
+		{
+			"base": "ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2",
+			"display": "atom",
+			"symbol": "ATOM",
+			"type_asset": "ics20",
+			"denom_units": [{"denom": "ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2", "exponent": 0, "aliases": ["uatom"]}, {"denom": "atom", "exponent": 6}],
+			"traces": [{
+				"type": "ibc",
+				"counterparty": {"chain_name": "cosmoshub", "base_denom": "uatom", "channel_id": "channel-141"},
+				"chain": {"channel_id": "channel-0", "path": "transfer/channel-0/uatom"}
+			}]
+		}
+	]
+}`
+
+func TestDenomResolver(t *testing.T) {
+	var assetList AssetList
+	require.NoError(t, json.Unmarshal([]byte(osmosisAssetList), &assetList))
+	resolver := NewDenomResolver(assetList)
+
+	atom := resolver.Resolve("ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2")
+	require.True(t, atom.Known)
+	require.Equal(t, "cosmoshub", atom.OriginChain)
+	require.Equal(t, "uatom", atom.BaseDenom)
+	require.Equal(t, "transfer/channel-0", atom.Path)
+	require.Equal(t, 6, atom.Exponent)
+
+	testCases := map[string]struct {
+		coin     sdk.Coin
+		expected string
+	}{
+		"native":              {coin: sdk.NewInt64Coin("uosmo", 1500000), expected: "1.5 OSMO"},
+		"whole amount":        {coin: sdk.NewInt64Coin("uosmo", 2000000), expected: "2 OSMO"},
+		"fraction":            {coin: sdk.NewInt64Coin("uosmo", 42), expected: "0.000042 OSMO"},
+		"ibc":                 {coin: sdk.NewInt64Coin(atom.Denom, 123456789), expected: "123.456789 ATOM"},
+		"unknown":             {coin: sdk.NewInt64Coin("ufoo", 5), expected: "5ufoo"},
+		"beyond int64 amount": {coin: sdk.NewCoin("uosmo", sdkmath.NewIntWithDecimal(1, 30)), expected: "1000000000000000000000000 OSMO"},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.expected, resolver.ToDisplay(tc.coin).String())
+		})
+	}
+
+	// Denom traces queried from the chain resolve ibc denoms that are not in the asset list.
+	trace := transfertypes.ParseDenomTrace("transfer/channel-42/ujuno")
+	resolver.AddDenomTrace(trace)
+	juno := resolver.Resolve(trace.IBCDenom())
+	require.False(t, juno.Known)
+	require.Equal(t, "ujuno", juno.BaseDenom)
+	require.Equal(t, "transfer/channel-42", juno.Path)
+}
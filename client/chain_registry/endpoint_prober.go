@@ -0,0 +1,163 @@
+package chain_registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/KyleMoser/cosmos-client/client/rpc"
+	"go.uber.org/zap"
+)
+
+// SelectionPolicy determines how probed endpoints are ranked.
+type SelectionPolicy string
+
+const (
+	// PolicyLatency prefers the endpoints that respond fastest.
+	PolicyLatency SelectionPolicy = "latency"
+	// PolicyArchive prefers the endpoints with the most history (the lowest earliest block height), then latency.
+	PolicyArchive SelectionPolicy = "archive"
+	// PolicyTxIndex only selects endpoints with tx indexing enabled, ranked by latency.
+	PolicyTxIndex SelectionPolicy = "tx-index"
+	// PolicyRandom selects uniformly among healthy endpoints.
+	PolicyRandom SelectionPolicy = "random"
+)
+
+// ParseSelectionPolicy validates the name of a selection policy. An empty name is PolicyLatency.
+func ParseSelectionPolicy(s string) (SelectionPolicy, error) {
+	switch p := SelectionPolicy(s); p {
+	case "":
+		return PolicyLatency, nil
+	case PolicyLatency, PolicyArchive, PolicyTxIndex, PolicyRandom:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown endpoint selection policy %q, expected %q, %q, %q or %q", s, PolicyLatency, PolicyArchive, PolicyTxIndex, PolicyRandom)
+	}
+}
+
+// EndpointProbe is the result of probing an RPC endpoint.
+type EndpointProbe struct {
+	Endpoint string `json:"endpoint"`
+	Provider string `json:"provider,omitempty"`
+	Healthy  bool   `json:"healthy"`
+	Error    string `json:"error,omitempty"`
+	// Latency is the round trip time of the status request.
+	Latency      time.Duration `json:"latency"`
+	LatestHeight int64         `json:"latest_height"`
+	// EarliestHeight is the first block the node still has. Pruned nodes have a recent earliest height.
+	EarliestHeight int64 `json:"earliest_height"`
+	// Archive is set when the registry lists the node as an archive node. It is not derived from
+	// EarliestHeight, as chains that were upgraded by a genesis export start at a height above 1.
+	Archive    bool   `json:"archive"`
+	TxIndex    bool   `json:"tx_index"`
+	CatchingUp bool   `json:"catching_up"`
+	Version    string `json:"version"`
+	AppVersion string `json:"app_version,omitempty"`
+}
+
+// EndpointProber probes RPC endpoints concurrently.
+type EndpointProber struct {
+	log     *zap.Logger
+	timeout time.Duration
+}
+
+// NewEndpointProber returns an EndpointProber whose requests time out after the given duration.
+func NewEndpointProber(log *zap.Logger, timeout time.Duration) *EndpointProber {
+	return &EndpointProber{log: log, timeout: timeout}
+}
+
+// Probe probes every endpoint concurrently. The results are in the order of the given endpoints.
+func (p *EndpointProber) Probe(ctx context.Context, endpoints []Endpoint) []EndpointProbe {
+	probes := make([]EndpointProbe, len(endpoints))
+	var wg sync.WaitGroup
+	for i, endpoint := range endpoints {
+		i, endpoint := i, endpoint
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			probes[i] = p.probe(ctx, endpoint)
+		}()
+	}
+	wg.Wait()
+	return probes
+}
+
+func (p *EndpointProber) probe(ctx context.Context, endpoint Endpoint) EndpointProbe {
+	probe := EndpointProbe{Endpoint: endpoint.Address, Provider: endpoint.Provider, Archive: endpoint.Archive}
+
+	cl, err := rpc.NewRPCClient(endpoint.Address, p.timeout)
+	if err != nil {
+		probe.Error = err.Error()
+		return probe
+	}
+
+	start := time.Now()
+	stat, err := cl.Status(ctx)
+	if err != nil {
+		probe.Error = err.Error()
+		p.log.Debug("Ignoring endpoint due to error", zap.String("endpoint", endpoint.Address), zap.Error(err))
+		return probe
+	}
+	probe.Latency = time.Since(start)
+
+	probe.LatestHeight = stat.SyncInfo.LatestBlockHeight
+	probe.EarliestHeight = stat.SyncInfo.EarliestBlockHeight
+	probe.TxIndex = stat.NodeInfo.Other.TxIndex == "on"
+	probe.CatchingUp = stat.SyncInfo.CatchingUp
+	probe.Version = stat.NodeInfo.Version
+	if probe.CatchingUp {
+		probe.Error = "still catching up"
+	} else {
+		probe.Healthy = true
+	}
+
+	// The application version is informational, so failing to get it does not make the endpoint unhealthy.
+	if info, err := cl.ABCIInfo(ctx); err == nil {
+		probe.AppVersion = info.Response.Version
+	}
+	return probe
+}
+
+// RankEndpoints returns the healthy probes that satisfy the policy, best first.
+func RankEndpoints(probes []EndpointProbe, policy SelectionPolicy) []EndpointProbe {
+	var ranked []EndpointProbe
+	for _, probe := range probes {
+		if !probe.Healthy || (policy == PolicyTxIndex && !probe.TxIndex) {
+			continue
+		}
+		ranked = append(ranked, probe)
+	}
+
+	switch policy {
+	case PolicyRandom:
+		rand.New(rand.NewSource(time.Now().UnixNano())).Shuffle(len(ranked), func(i, j int) {
+			ranked[i], ranked[j] = ranked[j], ranked[i]
+		})
+	case PolicyArchive:
+		sort.SliceStable(ranked, func(i, j int) bool {
+			if ranked[i].EarliestHeight != ranked[j].EarliestHeight {
+				return ranked[i].EarliestHeight < ranked[j].EarliestHeight
+			}
+			return ranked[i].Latency < ranked[j].Latency
+		})
+	default:
+		sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Latency < ranked[j].Latency })
+	}
+	return ranked
+}
+
+// SelectEndpoint returns the best endpoint according to the policy.
+func SelectEndpoint(probes []EndpointProbe, policy SelectionPolicy) (string, error) {
+	ranked := RankEndpoints(probes, policy)
+	if len(ranked) == 0 {
+		if policy == PolicyTxIndex {
+			return "", errors.New("no working RPCs with tx indexing found")
+		}
+		return "", errors.New("no working RPCs found")
+	}
+	return ranked[0].Endpoint, nil
+}
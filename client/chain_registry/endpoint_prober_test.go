@@ -0,0 +1,95 @@
+package chain_registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestRankEndpoints(t *testing.T) {
+	probes := []EndpointProbe{
+		{Endpoint: "pruned-fast", Healthy: true, Latency: 10 * time.Millisecond, EarliestHeight: 900},
+		{Endpoint: "archive-slow", Healthy: true, Latency: 50 * time.Millisecond, EarliestHeight: 1, Archive: true, TxIndex: true},
+		{Endpoint: "indexed", Healthy: true, Latency: 20 * time.Millisecond, EarliestHeight: 500, TxIndex: true},
+		{Endpoint: "down", Error: "connection refused"},
+	}
+
+	testCases := map[string]struct {
+		policy   SelectionPolicy
+		expected []string
+	}{
+		"latency":  {policy: PolicyLatency, expected: []string{"pruned-fast", "indexed", "archive-slow"}},
+		"archive":  {policy: PolicyArchive, expected: []string{"archive-slow", "indexed", "pruned-fast"}},
+		"tx index": {policy: PolicyTxIndex, expected: []string{"indexed", "archive-slow"}},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			var endpoints []string
+			for _, probe := range RankEndpoints(probes, tc.policy) {
+				endpoints = append(endpoints, probe.Endpoint)
+			}
+			require.Equal(t, tc.expected, endpoints)
+		})
+	}
+
+	require.Len(t, RankEndpoints(probes, PolicyRandom), 3)
+	_, err := SelectEndpoint(probes[3:], PolicyLatency)
+	require.Error(t, err)
+}
+
+func TestEndpointProber(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		var result string
+		switch req.Method {
+		case "status":
+			result = `{
+				"node_info": {"version": "0.38.7", "other": {"tx_index": "on"}},
+				"sync_info": {"latest_block_height": "1000", "earliest_block_height": "400", "catching_up": false}
+			}`
+		case "abci_info":
+			result = `{"response": {"version": "v25.0.0"}}`
+		default:
+			t.Errorf("unexpected method %s", req.Method)
+		}
+		fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": %s, "result": %s}`, req.ID, result)
+	}))
+	defer server.Close()
+
+	prober := NewEndpointProber(zaptest.NewLogger(t), 5*time.Second)
+	probes := prober.Probe(context.Background(), []Endpoint{
+		{Address: server.URL, Provider: "test"},
+		{Address: "http://127.0.0.1:1"},
+	})
+	require.Len(t, probes, 2)
+
+	probe := probes[0]
+	require.True(t, probe.Healthy, probe.Error)
+	require.Equal(t, "test", probe.Provider)
+	require.Equal(t, int64(1000), probe.LatestHeight)
+	require.Equal(t, int64(400), probe.EarliestHeight)
+	require.False(t, probe.Archive)
+	require.True(t, probe.TxIndex)
+	require.Equal(t, "0.38.7", probe.Version)
+	require.Equal(t, "v25.0.0", probe.AppVersion)
+
+	require.False(t, probes[1].Healthy)
+	require.NotEmpty(t, probes[1].Error)
+
+	endpoint, err := SelectEndpoint(probes, PolicyTxIndex)
+	require.NoError(t, err)
+	require.Equal(t, server.URL, endpoint)
+}
@@ -1,5 +1,13 @@
 package chain_registry
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
 // Defines IBC connection details between Cosmos chains.
 // From e.g. https://raw.githubusercontent.com/cosmos/chain-registry/master/_IBC/cosmoshub-osmosis.json
 type IbcConfig struct {
@@ -23,6 +31,13 @@ type IbcConfigChannelOuter struct {
 	} `json:"tags,omitempty"`
 }
 
+// Channel statuses, as found in the status tag of a channel.
+const (
+	ChannelStatusLive     = "live"
+	ChannelStatusUpcoming = "upcoming"
+	ChannelStatusKilled   = "killed"
+)
+
 type IbcConfigChain struct {
 	ChainName    string `json:"chain_name"`
 	ClientId     string `json:"client_id"`
@@ -33,3 +48,122 @@ type IbcConfigChannel struct {
 	ChannelId string `json:"channel_id"`
 	PortId    string `json:"port_id"`
 }
+
+// ibcConfigPaths returns the two registry paths an IBC config between chainA and chainB may be stored under,
+// e.g. _IBC/juno-osmosis.json or _IBC/osmosis-juno.json. The second path holds the config with the chains reversed.
+func ibcConfigPaths(chainA, chainB string) (path, reversedPath string) {
+	return fmt.Sprintf("_IBC/%s-%s.json", chainA, chainB), fmt.Sprintf("_IBC/%s-%s.json", chainB, chainA)
+}
+
+// Reversed returns a copy of the config with all references to chain1 and chain2 flipped.
+func (c IbcConfig) Reversed() IbcConfig {
+	out := c
+	out.Chain1, out.Chain2 = c.Chain2, c.Chain1
+	out.Channels = make([]IbcConfigChannelOuter, len(c.Channels))
+	for i, ch := range c.Channels {
+		ch.Chain1, ch.Chain2 = ch.Chain2, ch.Chain1
+		out.Channels[i] = ch
+	}
+	return out
+}
+
+// PreferredChannel returns the channel to use on the given port, e.g. transfer, or on any port if port is empty.
+// The channel tagged preferred is chosen first, then a live channel, then any channel that is not killed.
+func (c IbcConfig) PreferredChannel(port string) (IbcConfigChannelOuter, error) {
+	var candidates []IbcConfigChannelOuter
+	for _, ch := range c.Channels {
+		if port != "" && ch.Chain1.PortId != port {
+			continue
+		}
+		if ch.Tags.Status == ChannelStatusKilled {
+			continue
+		}
+		if ch.Tags.Preferred {
+			return ch, nil
+		}
+		candidates = append(candidates, ch)
+	}
+	for _, ch := range candidates {
+		if ch.Tags.Status == ChannelStatusLive {
+			return ch, nil
+		}
+	}
+	if len(candidates) > 0 {
+		return candidates[0], nil
+	}
+	if port == "" {
+		return IbcConfigChannelOuter{}, fmt.Errorf("no usable channel between %s and %s", c.Chain1.ChainName, c.Chain2.ChainName)
+	}
+	return IbcConfigChannelOuter{}, fmt.Errorf("no usable %s channel between %s and %s", port, c.Chain1.ChainName, c.Chain2.ChainName)
+}
+
+// IbcPath names the two chains of an IBC config in the _IBC directory of the registry.
+type IbcPath struct {
+	Chain1 string `json:"chain_1"`
+	Chain2 string `json:"chain_2"`
+}
+
+// parseIbcPath parses the name of an IBC config file, e.g. cosmoshub-osmosis.json.
+func parseIbcPath(file string) (IbcPath, bool) {
+	name, ok := strings.CutSuffix(file, ".json")
+	if !ok {
+		return IbcPath{}, false
+	}
+	chain1, chain2, ok := strings.Cut(name, "-")
+	if !ok || chain1 == "" || chain2 == "" || strings.Contains(chain2, "-") {
+		return IbcPath{}, false
+	}
+	return IbcPath{Chain1: chain1, Chain2: chain2}, true
+}
+
+// Counterparty returns the other chain of the path if chain is one of its chains.
+func (p IbcPath) Counterparty(chain string) (string, bool) {
+	switch chain {
+	case p.Chain1:
+		return p.Chain2, true
+	case p.Chain2:
+		return p.Chain1, true
+	default:
+		return "", false
+	}
+}
+
+// sortIbcPaths sorts the paths by their chain names.
+func sortIbcPaths(paths []IbcPath) {
+	sort.Slice(paths, func(i, j int) bool {
+		if paths[i].Chain1 != paths[j].Chain1 {
+			return paths[i].Chain1 < paths[j].Chain1
+		}
+		return paths[i].Chain2 < paths[j].Chain2
+	})
+}
+
+// GetIbcConfigs returns the IBC configs of every path of the chain in the registry, oriented so that
+// the chain is Chain1, sorted by the name of the counterparty chain.
+func GetIbcConfigs(ctx context.Context, r ChainRegistry, chain string) ([]IbcConfig, error) {
+	paths, err := r.ListIbcPaths(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var counterparties []string
+	for _, p := range paths {
+		if counterparty, ok := p.Counterparty(chain); ok {
+			counterparties = append(counterparties, counterparty)
+		}
+	}
+	sort.Strings(counterparties)
+
+	configs := make([]IbcConfig, 0, len(counterparties))
+	for _, counterparty := range counterparties {
+		conf, err := r.GetIbcConfig(chain, counterparty)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get IBC config between %s and %s: %w", chain, counterparty, err)
+		}
+		configs = append(configs, conf)
+	}
+	return configs, nil
+}
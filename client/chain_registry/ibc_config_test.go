@@ -0,0 +1,62 @@
+package chain_registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreferredChannel(t *testing.T) {
+	channel := func(id, port, status string, preferred bool) IbcConfigChannelOuter {
+		ch := IbcConfigChannelOuter{
+			Chain1: IbcConfigChannel{ChannelId: id, PortId: port},
+			Chain2: IbcConfigChannel{ChannelId: "channel-99", PortId: port},
+		}
+		ch.Tags.Status = status
+		ch.Tags.Preferred = preferred
+		return ch
+	}
+
+	testCases := map[string]struct {
+		channels []IbcConfigChannelOuter
+		port     string
+		expected string
+	}{
+		"preferred tag wins": {
+			channels: []IbcConfigChannelOuter{channel("channel-0", "transfer", ChannelStatusLive, false), channel("channel-1", "transfer", ChannelStatusLive, true)},
+			port:     "transfer",
+			expected: "channel-1",
+		},
+		"live before upcoming": {
+			channels: []IbcConfigChannelOuter{channel("channel-0", "transfer", ChannelStatusUpcoming, false), channel("channel-1", "transfer", ChannelStatusLive, false)},
+			port:     "transfer",
+			expected: "channel-1",
+		},
+		"killed channels are skipped": {
+			channels: []IbcConfigChannelOuter{channel("channel-0", "transfer", ChannelStatusKilled, true), channel("channel-1", "transfer", "", false)},
+			port:     "transfer",
+			expected: "channel-1",
+		},
+		"port filter": {
+			channels: []IbcConfigChannelOuter{channel("channel-0", "wasm.juno1abc", ChannelStatusLive, true), channel("channel-1", "transfer", ChannelStatusLive, false)},
+			port:     "transfer",
+			expected: "channel-1",
+		},
+		"no usable channel": {
+			channels: []IbcConfigChannelOuter{channel("channel-0", "transfer", ChannelStatusKilled, false)},
+			port:     "transfer",
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			ch, err := IbcConfig{Channels: tc.channels}.PreferredChannel(tc.port)
+			if tc.expected == "" {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, ch.Chain1.ChannelId)
+		})
+	}
+}
@@ -0,0 +1,320 @@
+package chain_registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+)
+
+// DefaultMaxRouteHops is the maximum number of hops of a route found by an IbcRouter, not counting the hops
+// needed to unwind a denom to its origin chain.
+const DefaultMaxRouteHops = 4
+
+// intermediateReceiver is the receiver on chains a packet is only forwarded through.
+// The packet forward middleware does not use it, but it must not be empty.
+const intermediateReceiver = "pfm"
+
+// IbcHop is a single IBC transfer of a route.
+type IbcHop struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Port and Channel are the source port and channel of the transfer on the From chain.
+	Port    string `json:"port"`
+	Channel string `json:"channel"`
+	// CounterpartyChannel is the channel the transfer is received on on the To chain.
+	CounterpartyChannel string `json:"counterparty_channel"`
+	// Denom is the denom that is sent, as held on the From chain.
+	Denom string `json:"denom"`
+	// Unwind is set when the hop sends the denom back towards its origin chain.
+	Unwind bool `json:"unwind"`
+}
+
+// IbcRoute is the sequence of IBC transfers that moves a denom from one chain to another.
+type IbcRoute struct {
+	From      string   `json:"from"`
+	To        string   `json:"to"`
+	BaseDenom string   `json:"base_denom"`
+	Hops      []IbcHop `json:"hops"`
+	// Denom is the denom that arrives on the To chain.
+	Denom string `json:"denom"`
+}
+
+// ForwardOptions configures the packet forward middleware for every forwarded hop.
+type ForwardOptions struct {
+	// Timeout is the timeout of every forwarded transfer. The middleware's default is used if it is zero.
+	Timeout time.Duration
+	// Retries is the number of times a forwarded transfer is retried on timeout. The middleware's default is used if it is nil.
+	Retries *uint8
+}
+
+// PacketForward is the memo of an IBC transfer which the packet forward middleware forwards to another chain.
+// See https://github.com/cosmos/ibc-apps/tree/main/middleware/packet-forward-middleware
+type PacketForward struct {
+	Forward ForwardMetadata `json:"forward"`
+}
+
+type ForwardMetadata struct {
+	Receiver string         `json:"receiver"`
+	Port     string         `json:"port"`
+	Channel  string         `json:"channel"`
+	Timeout  string         `json:"timeout,omitempty"`
+	Retries  *uint8         `json:"retries,omitempty"`
+	Next     *PacketForward `json:"next,omitempty"`
+}
+
+// Receiver returns the receiver of the first transfer of the route, which is the final receiver for
+// single hop routes. Multi-hop routes are received by the packet forward middleware of the next chain.
+func (r IbcRoute) Receiver(receiver string) string {
+	if len(r.Hops) > 1 {
+		return intermediateReceiver
+	}
+	return receiver
+}
+
+// ForwardMemo returns the memo of the first transfer of the route, which forwards the tokens over
+// the remaining hops to the receiver. Single hop routes need no memo, so the memo is empty.
+func (r IbcRoute) ForwardMemo(receiver string, opts ForwardOptions) (string, error) {
+	if len(r.Hops) < 2 {
+		return "", nil
+	}
+
+	var next *PacketForward
+	for i := len(r.Hops) - 1; i >= 1; i-- {
+		hop := r.Hops[i]
+		meta := ForwardMetadata{
+			Receiver: intermediateReceiver,
+			Port:     hop.Port,
+			Channel:  hop.Channel,
+			Retries:  opts.Retries,
+			Next:     next,
+		}
+		if i == len(r.Hops)-1 {
+			meta.Receiver = receiver
+		}
+		if opts.Timeout > 0 {
+			meta.Timeout = opts.Timeout.String()
+		}
+		next = &PacketForward{Forward: meta}
+	}
+
+	bz, err := json.Marshal(next)
+	if err != nil {
+		return "", err
+	}
+	return string(bz), nil
+}
+
+// IbcRouter plans IBC transfers between the chains of a registry. Denoms are first unwound to their
+// origin chain along the path they arrived by, and then sent to the destination over the fewest hops.
+type IbcRouter struct {
+	registry ChainRegistry
+	maxHops  int
+}
+
+// NewIbcRouter returns an IbcRouter finding routes of at most DefaultMaxRouteHops hops in the registry.
+func NewIbcRouter(registry ChainRegistry) *IbcRouter {
+	return &IbcRouter{registry: registry, maxHops: DefaultMaxRouteHops}
+}
+
+// WithMaxHops sets the maximum number of hops of a route, not counting unwinding hops.
+func (r *IbcRouter) WithMaxHops(maxHops int) *IbcRouter {
+	r.maxHops = maxHops
+	return r
+}
+
+// Route plans the transfer of the denom from one chain to another. The denom may be a native denom,
+// an ibc/HASH denom listed in the asset list of the from chain, or a full denom trace such as
+// transfer/channel-0/uatom.
+func (r *IbcRouter) Route(ctx context.Context, denom, from, to string) (IbcRoute, error) {
+	if strings.Contains(denom, "/") && !strings.HasPrefix(denom, "ibc/") {
+		return r.RouteTrace(ctx, transfertypes.ParseDenomTrace(denom), from, to)
+	}
+
+	var resolved ResolvedDenom
+	assetList, err := r.registry.GetAssetList(from)
+	switch {
+	case err == nil:
+		resolved = NewDenomResolver(assetList).Resolve(denom)
+	case errors.Is(err, ErrNotFound):
+		resolved = ResolvedDenom{Denom: denom, BaseDenom: denom}
+	default:
+		return IbcRoute{}, err
+	}
+
+	if strings.HasPrefix(denom, "ibc/") && resolved.Path == "" {
+		return IbcRoute{}, fmt.Errorf("denom trace of %s on %s is unknown, specify the full denom trace instead", denom, from)
+	}
+	return r.RouteTrace(ctx, transfertypes.DenomTrace{Path: resolved.Path, BaseDenom: resolved.BaseDenom}, from, to)
+}
+
+// RouteTrace plans the transfer of the denom with the given trace, as held on the from chain, to the to chain.
+func (r *IbcRouter) RouteTrace(ctx context.Context, trace transfertypes.DenomTrace, from, to string) (IbcRoute, error) {
+	route := IbcRoute{From: from, To: to, BaseDenom: trace.BaseDenom}
+
+	// Unwind the denom along the path it arrived by, so it is not wrapped again on the way.
+	// Unwinding stops early if the destination is on the path.
+	current := from
+	for trace.Path != "" && current != to {
+		port, channel, ok := firstTraceHop(trace.Path)
+		if !ok {
+			return IbcRoute{}, fmt.Errorf("invalid denom trace path %s", trace.Path)
+		}
+		conf, counterpartyChannel, err := r.configForChannel(ctx, current, port, channel)
+		if err != nil {
+			return IbcRoute{}, err
+		}
+		hop, next := newHop(current, conf.Chain2.ChainName, port, channel, counterpartyChannel, trace)
+		hop.Unwind = true
+		route.Hops = append(route.Hops, hop)
+		current, trace = hop.To, next
+	}
+
+	if current != to {
+		hops, next, err := r.shortestRoute(ctx, current, to, trace)
+		if err != nil {
+			return IbcRoute{}, err
+		}
+		route.Hops = append(route.Hops, hops...)
+		trace = next
+	}
+
+	route.Denom = trace.IBCDenom()
+	return route, nil
+}
+
+// shortestRoute finds the route with the fewest hops from one chain to another over channels on the transfer port,
+// and returns the trace of the denom on the destination. Connections without a usable transfer channel are skipped.
+func (r *IbcRouter) shortestRoute(ctx context.Context, from, to string, trace transfertypes.DenomTrace) ([]IbcHop, transfertypes.DenomTrace, error) {
+	paths, err := r.registry.ListIbcPaths(ctx)
+	if err != nil {
+		return nil, trace, err
+	}
+	graph := map[string][]string{}
+	for _, p := range paths {
+		graph[p.Chain1] = append(graph[p.Chain1], p.Chain2)
+		graph[p.Chain2] = append(graph[p.Chain2], p.Chain1)
+	}
+
+	// Edges without a usable transfer channel are removed and the search repeated.
+	unusable := map[IbcPath]bool{}
+	for {
+		chains := breadthFirst(graph, unusable, from, to, r.maxHops)
+		if chains == nil {
+			return nil, trace, fmt.Errorf("no IBC route from %s to %s within %d hops", from, to, r.maxHops)
+		}
+
+		var (
+			hops    []IbcHop
+			current = trace
+			retry   bool
+		)
+		for i := 0; i+1 < len(chains); i++ {
+			conf, err := r.registry.GetIbcConfig(chains[i], chains[i+1])
+			if err != nil {
+				return nil, trace, err
+			}
+			ch, err := conf.PreferredChannel(transfertypes.PortID)
+			if err != nil {
+				unusable[IbcPath{Chain1: chains[i], Chain2: chains[i+1]}] = true
+				retry = true
+				break
+			}
+			var hop IbcHop
+			hop, current = newHop(chains[i], chains[i+1], ch.Chain1.PortId, ch.Chain1.ChannelId, ch.Chain2.ChannelId, current)
+			hops = append(hops, hop)
+		}
+		if !retry {
+			return hops, current, nil
+		}
+	}
+}
+
+// configForChannel returns the IBC config of the chain with the given channel, oriented so that the chain is Chain1,
+// and the counterparty channel.
+func (r *IbcRouter) configForChannel(ctx context.Context, chain, port, channel string) (IbcConfig, string, error) {
+	configs, err := GetIbcConfigs(ctx, r.registry, chain)
+	if err != nil {
+		return IbcConfig{}, "", err
+	}
+	for _, conf := range configs {
+		for _, ch := range conf.Channels {
+			if ch.Chain1.PortId == port && ch.Chain1.ChannelId == channel {
+				return conf, ch.Chain2.ChannelId, nil
+			}
+		}
+	}
+	return IbcConfig{}, "", fmt.Errorf("%w: no IBC path of %s has channel %s/%s", ErrNotFound, chain, port, channel)
+}
+
+// newHop returns the hop sending the denom with the given trace over the channel, and the trace of the denom
+// on the receiving chain. Sending a denom back over the channel it arrived by removes that channel from the trace.
+func newHop(from, to, port, channel, counterpartyChannel string, trace transfertypes.DenomTrace) (IbcHop, transfertypes.DenomTrace) {
+	hop := IbcHop{
+		From:                from,
+		To:                  to,
+		Port:                port,
+		Channel:             channel,
+		CounterpartyChannel: counterpartyChannel,
+		Denom:               trace.IBCDenom(),
+	}
+
+	prefix := port + "/" + channel
+	next := trace
+	switch {
+	case trace.Path == prefix:
+		next.Path = ""
+	case strings.HasPrefix(trace.Path, prefix+"/"):
+		next.Path = strings.TrimPrefix(trace.Path, prefix+"/")
+	case trace.Path == "":
+		next.Path = transfertypes.PortID + "/" + counterpartyChannel
+	default:
+		next.Path = transfertypes.PortID + "/" + counterpartyChannel + "/" + trace.Path
+	}
+	return hop, next
+}
+
+// firstTraceHop returns the port and channel of the most recent hop of a denom trace path, which is the channel
+// the denom arrived on.
+func firstTraceHop(path string) (port, channel string, ok bool) {
+	parts := strings.SplitN(path, "/", 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// breadthFirst returns the chains of a shortest path from one chain to another with at most maxHops edges,
+// or nil if there is none.
+func breadthFirst(graph map[string][]string, unusable map[IbcPath]bool, from, to string, maxHops int) []string {
+	prev := map[string]string{from: ""}
+	frontier := []string{from}
+	for depth := 0; depth < maxHops && len(frontier) > 0; depth++ {
+		var next []string
+		for _, chain := range frontier {
+			for _, neighbor := range graph[chain] {
+				if _, seen := prev[neighbor]; seen {
+					continue
+				}
+				if unusable[IbcPath{Chain1: chain, Chain2: neighbor}] || unusable[IbcPath{Chain1: neighbor, Chain2: chain}] {
+					continue
+				}
+				prev[neighbor] = chain
+				if neighbor == to {
+					var chains []string
+					for c := to; c != ""; c = prev[c] {
+						chains = append([]string{c}, chains...)
+					}
+					return chains
+				}
+				next = append(next, neighbor)
+			}
+		}
+		frontier = next
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package chain_registry
+
+import (
+	"context"
+	"testing"
+
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestIbcRouter(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	writeRegistryFile(t, dir, "_IBC/cosmoshub-osmosis.json", `{
+		"chain_1": {"chain_name": "cosmoshub"},
+		"chain_2": {"chain_name": "osmosis"},
+		"channels": [{"chain_1": {"channel_id": "channel-141", "port_id": "transfer"}, "chain_2": {"channel_id": "channel-0", "port_id": "transfer"}}]
+	}`)
+	writeRegistryFile(t, dir, "_IBC/juno-osmosis.json", `{
+		"chain_1": {"chain_name": "juno"},
+		"chain_2": {"chain_name": "osmosis"},
+		"channels": [{"chain_1": {"channel_id": "channel-0", "port_id": "transfer"}, "chain_2": {"channel_id": "channel-42", "port_id": "transfer"}, "tags": {"preferred": true}}]
+	}`)
+	writeRegistryFile(t, dir, "_IBC/juno-stride.json", `{
+		"chain_1": {"chain_name": "juno"},
+		"chain_2": {"chain_name": "stride"},
+		"channels": [{"chain_1": {"channel_id": "channel-7", "port_id": "transfer"}, "chain_2": {"channel_id": "channel-8", "port_id": "transfer"}, "tags": {"status": "killed"}}]
+	}`)
+	registry, err := NewLocalRegistry(zaptest.NewLogger(t), dir)
+	require.NoError(t, err)
+	router := NewIbcRouter(registry)
+
+	atomOnJuno := "transfer/channel-0/transfer/channel-0/uatom"
+
+	testCases := map[string]struct {
+		denom, from, to string
+		channels        []string
+		denomTrace      string
+		unwind          []bool
+	}{
+		"native multi-hop": {
+			denom: "uatom", from: "cosmoshub", to: "juno",
+			channels:   []string{"channel-141", "channel-42"},
+			denomTrace: atomOnJuno,
+			unwind:     []bool{false, false},
+		},
+		"unwind to origin": {
+			denom: atomOnJuno, from: "juno", to: "cosmoshub",
+			channels:   []string{"channel-0", "channel-0"},
+			denomTrace: "uatom",
+			unwind:     []bool{true, true},
+		},
+		"unwind stops at destination": {
+			denom: atomOnJuno, from: "juno", to: "osmosis",
+			channels:   []string{"channel-0"},
+			denomTrace: "transfer/channel-0/uatom",
+			unwind:     []bool{true},
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			route, err := router.Route(ctx, tc.denom, tc.from, tc.to)
+			require.NoError(t, err)
+			require.Equal(t, "uatom", route.BaseDenom)
+			require.Equal(t, transfertypes.ParseDenomTrace(tc.denomTrace).IBCDenom(), route.Denom)
+
+			var channels []string
+			var unwind []bool
+			for _, hop := range route.Hops {
+				channels = append(channels, hop.Channel)
+				unwind = append(unwind, hop.Unwind)
+			}
+			require.Equal(t, tc.channels, channels)
+			require.Equal(t, tc.unwind, unwind)
+		})
+	}
+
+	route, err := router.Route(ctx, "uatom", "cosmoshub", "juno")
+	require.NoError(t, err)
+	require.Equal(t, "pfm", route.Receiver("juno1receiver"))
+	memo, err := route.ForwardMemo("juno1receiver", ForwardOptions{})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"forward": {"receiver": "juno1receiver", "port": "transfer", "channel": "channel-42"}}`, memo)
+
+	// The only channel to stride is killed.
+	_, err = router.Route(ctx, "uatom", "cosmoshub", "stride")
+	require.Error(t, err)
+
+	_, err = router.WithMaxHops(1).Route(ctx, "uatom", "cosmoshub", "juno")
+	require.Error(t, err)
+}
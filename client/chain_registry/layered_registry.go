@@ -0,0 +1,110 @@
+package chain_registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// LayeredRegistry combines several registries with precedence: every lookup returns the result
+// of the first registry that has the chain, so e.g. an in-house registry can add chains to,
+// or override chains of, the public registry listed after it.
+type LayeredRegistry struct {
+	registries []ChainRegistry
+}
+
+// NewLayeredRegistry returns a LayeredRegistry which looks up chains in the given registries in order.
+func NewLayeredRegistry(registries ...ChainRegistry) LayeredRegistry {
+	return LayeredRegistry{registries: registries}
+}
+
+// ListChains returns the chains of every registry, without duplicates.
+func (l LayeredRegistry) ListChains(ctx context.Context) ([]string, error) {
+	seen := map[string]bool{}
+	var chains []string
+	for _, r := range l.registries {
+		names, err := r.ListChains(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list chains of %s: %w", r.SourceLink(), err)
+		}
+		for _, name := range names {
+			if !seen[name] {
+				seen[name] = true
+				chains = append(chains, name)
+			}
+		}
+	}
+	sort.Strings(chains)
+	return chains, nil
+}
+
+func (l LayeredRegistry) GetChain(name string) (ChainInfo, error) {
+	return firstFound(l.registries, func(r ChainRegistry) (ChainInfo, error) {
+		return r.GetChain(name)
+	})
+}
+
+func (l LayeredRegistry) GetAssetList(name string) (AssetList, error) {
+	return firstFound(l.registries, func(r ChainRegistry) (AssetList, error) {
+		return r.GetAssetList(name)
+	})
+}
+
+func (l LayeredRegistry) GetIbcConfig(chainA, chainB string) (IbcConfig, error) {
+	return firstFound(l.registries, func(r ChainRegistry) (IbcConfig, error) {
+		return r.GetIbcConfig(chainA, chainB)
+	})
+}
+
+// ListIbcPaths returns the IBC paths of every registry, without duplicates.
+func (l LayeredRegistry) ListIbcPaths(ctx context.Context) ([]IbcPath, error) {
+	seen := map[IbcPath]bool{}
+	var paths []IbcPath
+	for _, r := range l.registries {
+		rpaths, err := r.ListIbcPaths(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list IBC paths of %s: %w", r.SourceLink(), err)
+		}
+		for _, p := range rpaths {
+			if !seen[p] && !seen[IbcPath{Chain1: p.Chain2, Chain2: p.Chain1}] {
+				seen[p] = true
+				paths = append(paths, p)
+			}
+		}
+	}
+	sortIbcPaths(paths)
+	return paths, nil
+}
+
+func (l LayeredRegistry) SourceLink() string {
+	links := make([]string, len(l.registries))
+	for i, r := range l.registries {
+		links[i] = r.SourceLink()
+	}
+	return strings.Join(links, ", ")
+}
+
+// firstFound returns the result of the first registry which does not return ErrNotFound.
+// If no registry has the result, the first error other than ErrNotFound is returned,
+// so that e.g. an unreachable registry is not reported as a missing chain.
+func firstFound[T any](registries []ChainRegistry, get func(ChainRegistry) (T, error)) (T, error) {
+	var (
+		zero     T
+		firstErr error
+	)
+	for _, r := range registries {
+		res, err := get(r)
+		if err == nil {
+			return res, nil
+		}
+		if firstErr == nil || (errors.Is(firstErr, ErrNotFound) && !errors.Is(err, ErrNotFound)) {
+			firstErr = err
+		}
+	}
+	if firstErr == nil {
+		firstErr = ErrNotFound
+	}
+	return zero, firstErr
+}
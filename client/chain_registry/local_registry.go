@@ -0,0 +1,152 @@
+package chain_registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// LocalRegistry reads chain information from a checkout of https://github.com/cosmos/chain-registry
+// on the local filesystem, so that no network access is required.
+type LocalRegistry struct {
+	log *zap.Logger
+	dir string
+}
+
+// NewLocalRegistry returns a LocalRegistry reading from the given chain registry checkout.
+func NewLocalRegistry(log *zap.Logger, dir string) (LocalRegistry, error) {
+	if dir == "" {
+		return LocalRegistry{}, errors.New("local chain registry path must be set")
+	}
+	fi, err := os.Stat(dir)
+	if err != nil {
+		return LocalRegistry{}, fmt.Errorf("failed to open local chain registry: %w", err)
+	}
+	if !fi.IsDir() {
+		return LocalRegistry{}, fmt.Errorf("local chain registry %s is not a directory", dir)
+	}
+	return LocalRegistry{log: log, dir: dir}, nil
+}
+
+// CloneRegistry performs a shallow git clone of the chain registry at url into dir.
+// Nothing is done if dir already exists, so an existing checkout is never modified.
+func CloneRegistry(ctx context.Context, url, dir string) error {
+	if _, err := os.Stat(dir); err == nil {
+		return nil
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	if url == "" {
+		return fmt.Errorf("chain registry %s does not exist and no git url is configured to clone it from", dir)
+	}
+
+	out, err := exec.CommandContext(ctx, "git", "clone", "--depth", "1", url, dir).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to clone chain registry %s: %w: %s", url, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// ListChains returns the name of every directory in the registry that contains a chain.json.
+func (r LocalRegistry) ListChains(_ context.Context) ([]string, error) {
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var chains []string
+	for _, entry := range entries {
+		if !entry.IsDir() || !isChainDir(entry.Name()) {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(r.dir, entry.Name(), "chain.json")); err == nil {
+			chains = append(chains, entry.Name())
+		}
+	}
+	sort.Strings(chains)
+	return chains, nil
+}
+
+func (r LocalRegistry) GetChain(name string) (ChainInfo, error) {
+	result := NewChainInfo(r.log.With(zap.String("chain_name", name)))
+	if err := r.readJSON(filepath.Join(name, "chain.json"), &result); err != nil {
+		return ChainInfo{}, err
+	}
+	result.registry = r
+	return result, nil
+}
+
+func (r LocalRegistry) GetAssetList(name string) (AssetList, error) {
+	var assetList AssetList
+	if err := r.readJSON(filepath.Join(name, "assetlist.json"), &assetList); err != nil {
+		return AssetList{}, err
+	}
+	return assetList, nil
+}
+
+func (r LocalRegistry) GetIbcConfig(chainA, chainB string) (IbcConfig, error) {
+	path, reversedPath := ibcConfigPaths(chainA, chainB)
+
+	var conf IbcConfig
+	err := r.readJSON(path, &conf)
+	if errors.Is(err, ErrNotFound) {
+		if err := r.readJSON(reversedPath, &conf); err != nil {
+			return IbcConfig{}, err
+		}
+		return conf.Reversed(), nil
+	}
+	if err != nil {
+		return IbcConfig{}, err
+	}
+	return conf, nil
+}
+
+func (r LocalRegistry) ListIbcPaths(_ context.Context) ([]IbcPath, error) {
+	entries, err := os.ReadDir(filepath.Join(r.dir, "_IBC"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []IbcPath
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if p, ok := parseIbcPath(entry.Name()); ok {
+			paths = append(paths, p)
+		}
+	}
+	sortIbcPaths(paths)
+	return paths, nil
+}
+
+// readJSON reads the file at the given path relative to the registry root and unmarshals it into out.
+func (r LocalRegistry) readJSON(path string, out interface{}) error {
+	file := filepath.Join(r.dir, filepath.FromSlash(path))
+	bz, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%w: %s", ErrNotFound, file)
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(bz, out); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+	return nil
+}
+
+func (r LocalRegistry) SourceLink() string {
+	return r.dir
+}
@@ -0,0 +1,124 @@
+package chain_registry
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestLocalRegistry(t *testing.T) {
+	dir := t.TempDir()
+	writeRegistryFile(t, dir, "osmosis/chain.json", `{"chain_name": "osmosis", "chain_id": "osmosis-1", "bech32_prefix": "osmo"}`)
+	writeRegistryFile(t, dir, "osmosis/assetlist.json", `{"chain_name": "osmosis", "assets": [{"base": "uosmo", "symbol": "OSMO"}]}`)
+	writeRegistryFile(t, dir, "cosmoshub/chain.json", `{"chain_name": "cosmoshub", "chain_id": "cosmoshub-4"}`)
+	writeRegistryFile(t, dir, "_IBC/cosmoshub-osmosis.json", `{
+		"chain_1": {"chain_name": "cosmoshub", "client_id": "07-tendermint-259", "connection_id": "connection-257"},
+		"chain_2": {"chain_name": "osmosis", "client_id": "07-tendermint-1", "connection_id": "connection-1"},
+		"channels": [{"chain_1": {"channel_id": "channel-141", "port_id": "transfer"}, "chain_2": {"channel_id": "channel-0", "port_id": "transfer"}}]
+	}`)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".github"), 0o755))
+
+	registry, err := NewLocalRegistry(zaptest.NewLogger(t), dir)
+	require.NoError(t, err)
+
+	chains, err := registry.ListChains(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"cosmoshub", "osmosis"}, chains)
+
+	chain, err := registry.GetChain("osmosis")
+	require.NoError(t, err)
+	require.Equal(t, "osmosis-1", chain.ChainID)
+	require.Equal(t, "osmo", chain.Bech32Prefix)
+
+	// The asset list must be read through the registry the chain came from.
+	assetList, err := chain.GetAssetList()
+	require.NoError(t, err)
+	require.Len(t, assetList.Assets, 1)
+	require.Equal(t, "uosmo", assetList.Assets[0].Base)
+	require.Equal(t, "osmosis-1", assetList.ChainID)
+
+	_, err = registry.GetChain("juno")
+	require.True(t, errors.Is(err, ErrNotFound))
+
+	conf, err := registry.GetIbcConfig("cosmoshub", "osmosis")
+	require.NoError(t, err)
+	require.Equal(t, "cosmoshub", conf.Chain1.ChainName)
+	require.Equal(t, "channel-141", conf.Channels[0].Chain1.ChannelId)
+
+	reversed, err := registry.GetIbcConfig("osmosis", "cosmoshub")
+	require.NoError(t, err)
+	require.Equal(t, "osmosis", reversed.Chain1.ChainName)
+	require.Equal(t, "07-tendermint-1", reversed.Chain1.ClientId)
+	require.Equal(t, "channel-0", reversed.Channels[0].Chain1.ChannelId)
+	require.Equal(t, "channel-141", reversed.Channels[0].Chain2.ChannelId)
+
+	_, err = registry.GetIbcConfig("osmosis", "juno")
+	require.True(t, errors.Is(err, ErrNotFound))
+
+	paths, err := registry.ListIbcPaths(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []IbcPath{{Chain1: "cosmoshub", Chain2: "osmosis"}}, paths)
+
+	configs, err := GetIbcConfigs(context.Background(), registry, "osmosis")
+	require.NoError(t, err)
+	require.Len(t, configs, 1)
+	require.Equal(t, "osmosis", configs[0].Chain1.ChainName)
+	require.Equal(t, "cosmoshub", configs[0].Chain2.ChainName)
+}
+
+func TestLayeredRegistry(t *testing.T) {
+	ctx := context.Background()
+	log := zaptest.NewLogger(t)
+
+	public := t.TempDir()
+	writeRegistryFile(t, public, "osmosis/chain.json", `{"chain_name": "osmosis", "chain_id": "osmosis-1"}`)
+	writeRegistryFile(t, public, "mychain/chain.json", `{"chain_name": "mychain", "chain_id": "public-1"}`)
+	writeRegistryFile(t, public, "testnets/osmosistestnet/chain.json", `{"chain_name": "osmosistestnet", "chain_id": "osmo-test-5"}`)
+
+	internal := t.TempDir()
+	writeRegistryFile(t, internal, "mychain/chain.json", `{"chain_name": "mychain", "chain_id": "internal-1"}`)
+	writeRegistryFile(t, internal, "mychain/assetlist.json", `{"chain_name": "mychain", "assets": [{"base": "umy"}]}`)
+
+	internalRegistry, err := NewChainRegistry(ctx, log, RegistryConfig{Name: "internal", Type: RegistryTypeLocal, Path: internal})
+	require.NoError(t, err)
+	publicRegistry, err := NewChainRegistry(ctx, log, RegistryConfig{Type: RegistryTypeLocal, Path: public})
+	require.NoError(t, err)
+	registry := NewLayeredRegistry(internalRegistry, publicRegistry)
+
+	chains, err := registry.ListChains(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"mychain", "osmosis"}, chains)
+
+	// The first registry takes precedence.
+	chain, err := registry.GetChain("mychain")
+	require.NoError(t, err)
+	require.Equal(t, "internal-1", chain.ChainID)
+	assetList, err := chain.GetAssetList()
+	require.NoError(t, err)
+	require.Equal(t, "umy", assetList.Assets[0].Base)
+
+	chain, err = registry.GetChain("osmosis")
+	require.NoError(t, err)
+	require.Equal(t, "osmosis-1", chain.ChainID)
+
+	_, err = registry.GetChain("juno")
+	require.True(t, errors.Is(err, ErrNotFound))
+
+	testnets, err := NewChainRegistry(ctx, log, RegistryConfig{Type: RegistryTypeLocal, Path: public, NetworkType: NetworkTypeTestnet})
+	require.NoError(t, err)
+	chains, err = testnets.ListChains(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"osmosistestnet"}, chains)
+}
+
+func writeRegistryFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	file := filepath.Join(dir, filepath.FromSlash(name))
+	require.NoError(t, os.MkdirAll(filepath.Dir(file), 0o755))
+	require.NoError(t, os.WriteFile(file, []byte(content), 0o644))
+}
@@ -0,0 +1,445 @@
+package chain_registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/KyleMoser/cosmos-client/client/rpc"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	"github.com/cosmos/gogoproto/proto"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	connectiontypes "github.com/cosmos/ibc-go/v8/modules/core/03-connection/types"
+	channeltypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/xeipuuv/gojsonschema"
+	"go.uber.org/zap"
+)
+
+// DefaultSchemaBaseURL is where schemas that are not found next to the validated files are fetched from.
+const DefaultSchemaBaseURL = "https://raw.githubusercontent.com/cosmos/chain-registry/master"
+
+// Validation checks, as reported in ValidationIssue.Check.
+const (
+	CheckSchema     = "schema"
+	CheckChainName  = "chain-name"
+	CheckBech32     = "bech32-prefix"
+	CheckDenomUnits = "denom-units"
+	CheckFileName   = "file-name"
+	CheckRPC        = "rpc"
+	CheckIbc        = "ibc"
+)
+
+// ValidationIssue is a problem found in a chain registry file.
+type ValidationIssue struct {
+	File    string `json:"file" yaml:"file"`
+	Check   string `json:"check" yaml:"check"`
+	Message string `json:"message" yaml:"message"`
+}
+
+// ValidationOptions configures a RegistryValidator.
+type ValidationOptions struct {
+	// Online enables the checks that query the chains: RPC endpoints must be reachable and report the chain ID,
+	// and the clients, connections and channels of IBC configs must exist on both chains.
+	Online bool
+	// SampleAddresses are addresses that must match the bech32 prefix of the validated chains. Addresses of
+	// cw20 tokens in the asset list of a chain are checked as well.
+	SampleAddresses []string
+	// SchemaBaseURL is where schemas that are not found next to the validated files are fetched from.
+	// Defaults to DefaultSchemaBaseURL.
+	SchemaBaseURL string
+	// Timeout is the timeout of every request of the online checks. Defaults to 10 seconds.
+	Timeout time.Duration
+}
+
+// RegistryValidator validates chain.json, assetlist.json and _IBC files of a chain registry checkout
+// against their JSON schemas and the semantic rules of the registry.
+type RegistryValidator struct {
+	log  *zap.Logger
+	opts ValidationOptions
+
+	mu      sync.Mutex
+	schemas map[string]*gojsonschema.Schema
+}
+
+// NewRegistryValidator returns a RegistryValidator with the given options.
+func NewRegistryValidator(log *zap.Logger, opts ValidationOptions) *RegistryValidator {
+	if opts.SchemaBaseURL == "" {
+		opts.SchemaBaseURL = DefaultSchemaBaseURL
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	return &RegistryValidator{log: log, opts: opts, schemas: map[string]*gojsonschema.Schema{}}
+}
+
+// Validate validates the file or directory at the given path, which may be a single registry file,
+// the directory of a chain, or a whole registry checkout. An error is only returned if the path cannot
+// be read; problems with the files are returned as issues.
+func (v *RegistryValidator) Validate(ctx context.Context, root string) ([]ValidationIssue, error) {
+	files, err := registryFiles(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []ValidationIssue
+	for _, file := range files {
+		issues = append(issues, v.ValidateFile(ctx, file)...)
+	}
+	return issues, nil
+}
+
+// ValidateFile validates a single registry file, which is recognized by its name and location.
+func (v *RegistryValidator) ValidateFile(ctx context.Context, file string) []ValidationIssue {
+	bz, err := os.ReadFile(file)
+	if err != nil {
+		return []ValidationIssue{{File: file, Check: CheckSchema, Message: err.Error()}}
+	}
+
+	issues := v.validateSchema(file, bz)
+	dir := filepath.Base(filepath.Dir(file))
+	switch {
+	case filepath.Base(file) == "chain.json":
+		var chain ChainInfo
+		if err := json.Unmarshal(bz, &chain); err != nil {
+			return append(issues, ValidationIssue{File: file, Check: CheckSchema, Message: err.Error()})
+		}
+		issues = append(issues, v.validateChain(ctx, file, dir, chain)...)
+	case filepath.Base(file) == "assetlist.json":
+		var assetList AssetList
+		if err := json.Unmarshal(bz, &assetList); err != nil {
+			return append(issues, ValidationIssue{File: file, Check: CheckSchema, Message: err.Error()})
+		}
+		issues = append(issues, validateAssetList(file, dir, assetList)...)
+	case dir == "_IBC":
+		var conf IbcConfig
+		if err := json.Unmarshal(bz, &conf); err != nil {
+			return append(issues, ValidationIssue{File: file, Check: CheckSchema, Message: err.Error()})
+		}
+		issues = append(issues, v.validateIbcConfig(ctx, file, conf)...)
+	}
+	return issues
+}
+
+// validateSchema validates the file against the schema referenced by its $schema field.
+func (v *RegistryValidator) validateSchema(file string, bz []byte) []ValidationIssue {
+	var doc struct {
+		Schema string `json:"$schema"`
+	}
+	if err := json.Unmarshal(bz, &doc); err != nil {
+		return []ValidationIssue{{File: file, Check: CheckSchema, Message: fmt.Sprintf("invalid JSON: %v", err)}}
+	}
+	if doc.Schema == "" {
+		return []ValidationIssue{{File: file, Check: CheckSchema, Message: "$schema is not set"}}
+	}
+
+	schema, err := v.schema(file, doc.Schema)
+	if err != nil {
+		return []ValidationIssue{{File: file, Check: CheckSchema, Message: fmt.Sprintf("failed to load schema %s: %v", doc.Schema, err)}}
+	}
+	res, err := schema.Validate(gojsonschema.NewBytesLoader(bz))
+	if err != nil {
+		return []ValidationIssue{{File: file, Check: CheckSchema, Message: err.Error()}}
+	}
+
+	var issues []ValidationIssue
+	for _, e := range res.Errors() {
+		issues = append(issues, ValidationIssue{File: file, Check: CheckSchema, Message: e.String()})
+	}
+	return issues
+}
+
+// schema returns the compiled schema referenced by the file. Relative references are resolved against
+// the directory of the file first, and fetched from SchemaBaseURL if they do not exist there.
+func (v *RegistryValidator) schema(file, ref string) (*gojsonschema.Schema, error) {
+	location := ref
+	if u, err := url.Parse(ref); err != nil || u.Scheme == "" {
+		local := filepath.Join(filepath.Dir(file), filepath.FromSlash(ref))
+		if _, err := os.Stat(local); err == nil {
+			abs, err := filepath.Abs(local)
+			if err != nil {
+				return nil, err
+			}
+			location = "file://" + filepath.ToSlash(abs)
+		} else {
+			location = strings.TrimSuffix(v.opts.SchemaBaseURL, "/") + "/" + path.Base(ref)
+		}
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if schema, ok := v.schemas[location]; ok {
+		return schema, nil
+	}
+	schema, err := gojsonschema.NewSchema(gojsonschema.NewReferenceLoader(location))
+	if err != nil {
+		return nil, err
+	}
+	v.schemas[location] = schema
+	return schema, nil
+}
+
+func (v *RegistryValidator) validateChain(ctx context.Context, file, dir string, chain ChainInfo) []ValidationIssue {
+	var issues []ValidationIssue
+	if chain.ChainName != dir {
+		issues = append(issues, ValidationIssue{File: file, Check: CheckChainName,
+			Message: fmt.Sprintf("chain_name %s does not match directory %s", chain.ChainName, dir)})
+	}
+
+	addresses := append([]string{}, v.opts.SampleAddresses...)
+	var assetList AssetList
+	if bz, err := os.ReadFile(filepath.Join(filepath.Dir(file), "assetlist.json")); err == nil && json.Unmarshal(bz, &assetList) == nil {
+		for _, asset := range assetList.Assets {
+			if asset.TypeAsset == AssetTypeCw20 && asset.Address != "" {
+				addresses = append(addresses, asset.Address)
+			}
+		}
+	}
+	for _, addr := range addresses {
+		hrp, _, err := bech32.DecodeAndConvert(addr)
+		if err != nil {
+			issues = append(issues, ValidationIssue{File: file, Check: CheckBech32, Message: fmt.Sprintf("invalid address %s: %v", addr, err)})
+			continue
+		}
+		if hrp != chain.Bech32Prefix {
+			issues = append(issues, ValidationIssue{File: file, Check: CheckBech32,
+				Message: fmt.Sprintf("bech32_prefix %s does not match address %s", chain.Bech32Prefix, addr)})
+		}
+	}
+
+	if v.opts.Online {
+		issues = append(issues, v.validateRPCEndpoints(ctx, file, chain)...)
+	}
+	return issues
+}
+
+// validateRPCEndpoints checks concurrently that every RPC endpoint is reachable and reports the chain ID.
+func (v *RegistryValidator) validateRPCEndpoints(ctx context.Context, file string, chain ChainInfo) []ValidationIssue {
+	results := make([]*ValidationIssue, len(chain.Apis.RPC))
+	var wg sync.WaitGroup
+	for i, endpoint := range chain.Apis.RPC {
+		i, endpoint := i, endpoint
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := v.checkRPCEndpoint(ctx, endpoint.Address, chain.ChainID); err != nil {
+				results[i] = &ValidationIssue{File: file, Check: CheckRPC, Message: fmt.Sprintf("%s: %v", endpoint.Address, err)}
+			}
+		}()
+	}
+	wg.Wait()
+
+	var issues []ValidationIssue
+	for _, issue := range results {
+		if issue != nil {
+			issues = append(issues, *issue)
+		}
+	}
+	return issues
+}
+
+func (v *RegistryValidator) checkRPCEndpoint(ctx context.Context, address, chainID string) error {
+	addr, err := normalizeRPCAddress(address)
+	if err != nil {
+		return err
+	}
+	cl, err := rpc.NewRPCClient(addr, v.opts.Timeout)
+	if err != nil {
+		return err
+	}
+	stat, err := cl.Status(ctx)
+	if err != nil {
+		return err
+	}
+	if stat.NodeInfo.Network != chainID {
+		return fmt.Errorf("reports chain ID %s, expected %s", stat.NodeInfo.Network, chainID)
+	}
+	return nil
+}
+
+func validateAssetList(file, dir string, assetList AssetList) []ValidationIssue {
+	var issues []ValidationIssue
+	if assetList.ChainName != dir {
+		issues = append(issues, ValidationIssue{File: file, Check: CheckChainName,
+			Message: fmt.Sprintf("chain_name %s does not match directory %s", assetList.ChainName, dir)})
+	}
+
+	for _, asset := range assetList.Assets {
+		exponents := map[string]int{}
+		for _, u := range asset.DenomUnits {
+			exponents[u.Denom] = u.Exponent
+		}
+		if exp, ok := exponents[asset.Base]; !ok || exp != 0 {
+			issues = append(issues, ValidationIssue{File: file, Check: CheckDenomUnits,
+				Message: fmt.Sprintf("asset %s: base denom must be a denom unit with exponent 0", asset.Base)})
+		}
+		if _, ok := exponents[asset.Display]; !ok {
+			issues = append(issues, ValidationIssue{File: file, Check: CheckDenomUnits,
+				Message: fmt.Sprintf("asset %s: display denom %s is not a denom unit", asset.Base, asset.Display)})
+		}
+	}
+	return issues
+}
+
+func (v *RegistryValidator) validateIbcConfig(ctx context.Context, file string, conf IbcConfig) []ValidationIssue {
+	var issues []ValidationIssue
+	// Registry convention is that chain_1 is the chain whose name sorts first, and the file is named after both.
+	// A file with the chains in the wrong order is also misnamed, so that is reported as a single issue.
+	first, second := conf.Chain1.ChainName, conf.Chain2.ChainName
+	if first > second {
+		first, second = second, first
+	}
+	expected, _ := ibcConfigPaths(first, second)
+	if conf.Chain1.ChainName > conf.Chain2.ChainName {
+		issues = append(issues, ValidationIssue{File: file, Check: CheckFileName,
+			Message: fmt.Sprintf("chain_1 %s must sort before chain_2 %s in %s", conf.Chain1.ChainName, conf.Chain2.ChainName, path.Base(expected))})
+	} else if path.Base(expected) != filepath.Base(file) {
+		issues = append(issues, ValidationIssue{File: file, Check: CheckFileName,
+			Message: fmt.Sprintf("file must be named %s", path.Base(expected))})
+	}
+	if !v.opts.Online {
+		return issues
+	}
+
+	// The chains of the config are looked up in the registry the _IBC directory belongs to.
+	registry, err := NewLocalRegistry(v.log, filepath.Dir(filepath.Dir(file)))
+	if err != nil {
+		return append(issues, ValidationIssue{File: file, Check: CheckIbc, Message: err.Error()})
+	}
+	for _, side := range []struct {
+		chain    IbcConfigChain
+		channels func(IbcConfigChannelOuter) IbcConfigChannel
+	}{
+		{conf.Chain1, func(ch IbcConfigChannelOuter) IbcConfigChannel { return ch.Chain1 }},
+		{conf.Chain2, func(ch IbcConfigChannelOuter) IbcConfigChannel { return ch.Chain2 }},
+	} {
+		channels := make([]IbcConfigChannel, len(conf.Channels))
+		for i, ch := range conf.Channels {
+			channels[i] = side.channels(ch)
+		}
+		for _, err := range v.checkIbcIDs(ctx, registry, side.chain, channels) {
+			issues = append(issues, ValidationIssue{File: file, Check: CheckIbc, Message: fmt.Sprintf("%s: %v", side.chain.ChainName, err)})
+		}
+	}
+	return issues
+}
+
+// checkIbcIDs checks that the client, connection and channels exist on the chain and refer to each other.
+func (v *RegistryValidator) checkIbcIDs(ctx context.Context, registry ChainRegistry, chain IbcConfigChain, channels []IbcConfigChannel) []error {
+	info, err := registry.GetChain(chain.ChainName)
+	if err != nil {
+		return []error{err}
+	}
+	q, err := v.reachableQuerier(ctx, info)
+	if err != nil {
+		return []error{err}
+	}
+
+	var errs []error
+	if err := q.query(ctx, "/ibc.core.client.v1.Query/ClientState",
+		&clienttypes.QueryClientStateRequest{ClientId: chain.ClientId}, &clienttypes.QueryClientStateResponse{}); err != nil {
+		errs = append(errs, fmt.Errorf("client %s: %w", chain.ClientId, err))
+	}
+
+	var conn connectiontypes.QueryConnectionResponse
+	if err := q.query(ctx, "/ibc.core.connection.v1.Query/Connection",
+		&connectiontypes.QueryConnectionRequest{ConnectionId: chain.ConnectionId}, &conn); err != nil {
+		errs = append(errs, fmt.Errorf("connection %s: %w", chain.ConnectionId, err))
+	} else if conn.Connection.ClientId != chain.ClientId {
+		errs = append(errs, fmt.Errorf("connection %s uses client %s, expected %s", chain.ConnectionId, conn.Connection.ClientId, chain.ClientId))
+	}
+
+	for _, ch := range channels {
+		var res channeltypes.QueryChannelResponse
+		if err := q.query(ctx, "/ibc.core.channel.v1.Query/Channel",
+			&channeltypes.QueryChannelRequest{PortId: ch.PortId, ChannelId: ch.ChannelId}, &res); err != nil {
+			errs = append(errs, fmt.Errorf("channel %s/%s: %w", ch.PortId, ch.ChannelId, err))
+			continue
+		}
+		if len(res.Channel.ConnectionHops) == 0 || res.Channel.ConnectionHops[0] != chain.ConnectionId {
+			errs = append(errs, fmt.Errorf("channel %s/%s uses connection hops %v, expected %s",
+				ch.PortId, ch.ChannelId, res.Channel.ConnectionHops, chain.ConnectionId))
+		}
+	}
+	return errs
+}
+
+// abciQuerier runs gRPC queries over the ABCI query endpoint of an RPC node.
+type abciQuerier struct {
+	address string
+	timeout time.Duration
+}
+
+// reachableQuerier returns a querier for the first RPC endpoint of the chain that reports its chain ID.
+func (v *RegistryValidator) reachableQuerier(ctx context.Context, chain ChainInfo) (*abciQuerier, error) {
+	for _, endpoint := range chain.Apis.RPC {
+		if err := v.checkRPCEndpoint(ctx, endpoint.Address, chain.ChainID); err != nil {
+			v.log.Debug("Ignoring endpoint due to error", zap.String("endpoint", endpoint.Address), zap.Error(err))
+			continue
+		}
+		addr, _ := normalizeRPCAddress(endpoint.Address)
+		return &abciQuerier{address: addr, timeout: v.opts.Timeout}, nil
+	}
+	return nil, fmt.Errorf("no working RPCs found")
+}
+
+func (q *abciQuerier) query(ctx context.Context, method string, req, res proto.Message) error {
+	cl, err := rpc.NewRPCClient(q.address, q.timeout)
+	if err != nil {
+		return err
+	}
+	bz, err := proto.Marshal(req)
+	if err != nil {
+		return err
+	}
+	result, err := cl.ABCIQuery(ctx, method, bz)
+	if err != nil {
+		return err
+	}
+	if !result.Response.IsOK() {
+		return fmt.Errorf("%s", result.Response.Log)
+	}
+	return proto.Unmarshal(result.Response.Value, res)
+}
+
+// registryFiles returns the registry files to validate at the given path: the file itself, the chain.json and
+// assetlist.json of a chain directory, or every such file and _IBC config of a registry checkout.
+func registryFiles(root string) ([]string, error) {
+	fi, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return []string{root}, nil
+	}
+
+	var files []string
+	err = filepath.WalkDir(root, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		name := d.Name()
+		if name == "chain.json" || name == "assetlist.json" || (filepath.Base(filepath.Dir(p)) == "_IBC" && strings.HasSuffix(name, ".json")) {
+			files = append(files, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
@@ -0,0 +1,67 @@
+package chain_registry
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+const testChainSchema = `{
+	"$schema": "http://json-schema.org/draft-07/schema#",
+	"type": "object",
+	"required": ["chain_name", "chain_id", "bech32_prefix"],
+	"properties": {
+		"chain_name": {"type": "string", "pattern": "[a-z0-9]+"},
+		"chain_id": {"type": "string"},
+		"bech32_prefix": {"type": "string"}
+	}
+}`
+
+func TestRegistryValidator(t *testing.T) {
+	dir := t.TempDir()
+	writeRegistryFile(t, dir, "chain.schema.json", testChainSchema)
+	writeRegistryFile(t, dir, "assetlist.schema.json", `{"$schema": "http://json-schema.org/draft-07/schema#", "type": "object", "required": ["chain_name", "assets"]}`)
+	writeRegistryFile(t, dir, "ibc_data.schema.json", `{"$schema": "http://json-schema.org/draft-07/schema#", "type": "object", "required": ["chain_1", "chain_2", "channels"]}`)
+
+	writeRegistryFile(t, dir, "osmosis/chain.json", `{"$schema": "../chain.schema.json", "chain_name": "osmosis", "chain_id": "osmosis-1", "bech32_prefix": "osmo"}`)
+	writeRegistryFile(t, dir, "osmosis/assetlist.json", `{"$schema": "../assetlist.schema.json", "chain_name": "osmosis", "assets": [
+		{"base": "uosmo", "display": "osmo", "denom_units": [{"denom": "uosmo", "exponent": 0}, {"denom": "osmo", "exponent": 6}]}
+	]}`)
+	writeRegistryFile(t, dir, "juno/chain.json", `{"$schema": "../chain.schema.json", "chain_name": "junoo", "bech32_prefix": "juno"}`)
+	writeRegistryFile(t, dir, "juno/assetlist.json", `{"$schema": "../assetlist.schema.json", "chain_name": "juno", "assets": [
+		{"base": "ujuno", "display": "juno", "denom_units": [{"denom": "ujuno", "exponent": 0}]}
+	]}`)
+	writeRegistryFile(t, dir, "_IBC/osmosis-juno.json", `{"$schema": "../ibc_data.schema.json",
+		"chain_1": {"chain_name": "osmosis"}, "chain_2": {"chain_name": "juno"}, "channels": []}`)
+	writeRegistryFile(t, dir, "_IBC/juno_osmosis.json", `{"$schema": "../ibc_data.schema.json",
+		"chain_1": {"chain_name": "juno"}, "chain_2": {"chain_name": "osmosis"}, "channels": []}`)
+
+	osmoAddr, err := bech32.ConvertAndEncode("osmo", make([]byte, 20))
+	require.NoError(t, err)
+	validator := NewRegistryValidator(zaptest.NewLogger(t), ValidationOptions{SampleAddresses: []string{osmoAddr}})
+
+	issues, err := validator.Validate(context.Background(), filepath.Join(dir, "osmosis"))
+	require.NoError(t, err)
+	require.Empty(t, issues)
+
+	issues, err = validator.Validate(context.Background(), dir)
+	require.NoError(t, err)
+
+	checks := map[string][]string{}
+	for _, issue := range issues {
+		rel, err := filepath.Rel(dir, issue.File)
+		require.NoError(t, err)
+		checks[filepath.ToSlash(rel)] = append(checks[filepath.ToSlash(rel)], issue.Check)
+	}
+	require.Equal(t, map[string][]string{
+		// chain_id is missing, the directory name does not match and the sample address is an osmosis address.
+		"juno/chain.json":        {CheckSchema, CheckChainName, CheckBech32},
+		"juno/assetlist.json":    {CheckDenomUnits},
+		"_IBC/osmosis-juno.json": {CheckFileName},
+		"_IBC/juno_osmosis.json": {CheckFileName},
+	}, checks)
+}
@@ -6,6 +6,8 @@ import (
 	"time"
 
 	registry "github.com/KyleMoser/cosmos-client/client/chain_registry"
+	"github.com/KyleMoser/cosmos-client/client/throttle"
+	"github.com/KyleMoser/cosmos-client/client/transport"
 
 	"github.com/cosmos/cosmos-sdk/types/module"
 	"github.com/spf13/viper"
@@ -17,6 +19,7 @@ type ChainClientConfig struct {
 	Key            string                  `json:"key" yaml:"key"`
 	ChainID        string                  `json:"chain-id" yaml:"chain-id"`
 	RPCAddr        string                  `json:"rpc-addr" yaml:"rpc-addr"`
+	RPCAddrs       []string                `json:"rpc-addrs,omitempty" yaml:"rpc-addrs,omitempty"`
 	GRPCAddr       string                  `json:"grpc-addr" yaml:"grpc-addr"`
 	AccountPrefix  string                  `json:"account-prefix" yaml:"account-prefix"`
 	KeyringBackend string                  `json:"keyring-backend" yaml:"keyring-backend"`
@@ -30,14 +33,66 @@ type ChainClientConfig struct {
 	OutputFormat   string                  `json:"output-format" yaml:"output-format"`
 	SignModeStr    string                  `json:"sign-mode" yaml:"sign-mode"`
 	ExtraCodecs    []string                `json:"extra-codecs" yaml:"extra-codecs"`
+	KeyAlgos       []string                `json:"key-algos,omitempty" yaml:"key-algos,omitempty"`
 	Modules        []module.AppModuleBasic `json:"-" yaml:"-"`
 	Slip44         int                     `json:"slip44" yaml:"slip44"`
+	// QueryRoute selects whether queries use ABCI over RPC (the default), the gRPC address, or gRPC with
+	// a fallback to RPC. See the QueryRoute constants.
+	QueryRoute string `json:"query-route,omitempty" yaml:"query-route,omitempty"`
+	// Verify enables verification of store queries against a light client, which starts from the trusted
+	// height and hash and trusts headers for the trust period (DefaultTrustPeriod if empty). The witness
+	// addresses are RPC endpoints used to cross-check the RPC address.
+	Verify        bool     `json:"verify,omitempty" yaml:"verify,omitempty"`
+	TrustPeriod   string   `json:"trust-period,omitempty" yaml:"trust-period,omitempty"`
+	TrustedHeight int64    `json:"trusted-height,omitempty" yaml:"trusted-height,omitempty"`
+	TrustedHash   string   `json:"trusted-hash,omitempty" yaml:"trusted-hash,omitempty"`
+	WitnessAddrs  []string `json:"witness-addrs,omitempty" yaml:"witness-addrs,omitempty"`
+	// Metrics enables the Prometheus metrics of the client, which are registered with the default registerer.
+	Metrics bool `json:"metrics,omitempty" yaml:"metrics,omitempty"`
+	// Retry is the policy retrying failed requests to the chain's endpoints, throttle.DefaultRetryPolicy if unset.
+	Retry *throttle.RetryConfig `json:"retry,omitempty" yaml:"retry,omitempty"`
+	// RateLimit limits the rate of requests to every RPC endpoint. Retry-After headers are honoured regardless.
+	RateLimit *throttle.RateLimitConfig `json:"rate-limit,omitempty" yaml:"rate-limit,omitempty"`
+	// Transport sets headers, authentication, a proxy and TLS options for the RPC, websocket and gRPC endpoints.
+	Transport *transport.Config `json:"transport,omitempty" yaml:"transport,omitempty"`
+	// QueryCache caches the responses of queries at a height, and optionally of queries at the latest height.
+	QueryCache *QueryCacheConfig `json:"query-cache,omitempty" yaml:"query-cache,omitempty"`
+	// BatchSize is the maximum number of calls of a JSON-RPC batch request, rpc.DefaultBatchSize if unset.
+	BatchSize int `json:"batch-size,omitempty" yaml:"batch-size,omitempty"`
 }
 
 func (ccc *ChainClientConfig) Validate() error {
 	if _, err := time.ParseDuration(ccc.Timeout); err != nil {
 		return err
 	}
+	route, err := ParseQueryRoute(ccc.QueryRoute)
+	if err != nil {
+		return err
+	}
+	if route != QueryRouteRPC && ccc.GRPCAddr == "" {
+		return fmt.Errorf("query route %s requires a grpc-addr", route)
+	}
+	if err := ccc.validateVerifyConfig(); err != nil {
+		return err
+	}
+	if _, err := ccc.Retry.Policy(); err != nil {
+		return err
+	}
+	if err := ccc.RateLimit.Validate(); err != nil {
+		return err
+	}
+	if err := ccc.Transport.Validate(); err != nil {
+		return fmt.Errorf("invalid transport: %w", err)
+	}
+	if err := ccc.QueryCache.Validate(); err != nil {
+		return err
+	}
+	if ccc.BatchSize < 0 {
+		return fmt.Errorf("invalid batch-size %d", ccc.BatchSize)
+	}
+	if err := ValidateKeyAlgos(ccc.ChainID, ccc.KeyAlgos); err != nil {
+		return err
+	}
 	if ccc.BlockTimeout != "" {
 		if _, err := time.ParseDuration(ccc.BlockTimeout); err != nil {
 			return err
@@ -88,19 +143,13 @@ func GetChainConfigWithOpts(ctx context.Context, c registry.ChainInfo, opts *Cha
 	debug := viper.GetBool("debug")
 	home := viper.GetString("home")
 
-	assetList, err := c.GetAssetList()
-	if err != nil {
+	if err := ValidateKeyAlgos(c.ChainID, c.KeyAlgos); err != nil {
 		return nil, err
 	}
 
-	var gasPrices string
-	if len(assetList.Assets) > 0 {
-		gasPrices = fmt.Sprintf("%.2f%s", 0.01, assetList.Assets[0].Base)
-	}
-
-	// TODO: better bugfix for scenario where chainclient is noble
-	if c.ChainName == "noble" {
-		gasPrices = fmt.Sprintf("%.2f%s", 0.1, "uusdc")
+	gasPrices, err := chainGasPrices(c, opts)
+	if err != nil {
+		return nil, err
 	}
 
 	var rpc string
@@ -113,10 +162,27 @@ func GetChainConfigWithOpts(ctx context.Context, c registry.ChainInfo, opts *Cha
 	}
 
 	if err != nil || rpc == "" {
-		rpc, err = c.GetRandomRPCEndpoint(ctx)
+		if opts != nil && opts.EndpointPolicy != "" {
+			rpc, err = c.GetRPCEndpointWithPolicy(ctx, opts.EndpointPolicy)
+		} else {
+			rpc, err = c.GetRandomRPCEndpoint(ctx)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var rpcAddrs []string
+	if opts != nil && opts.FallbackRPCEndpoints > 0 {
+		endpoints, err := c.GetRPCEndpoints(ctx)
 		if err != nil {
 			return nil, err
 		}
+		for _, endpoint := range endpoints {
+			if len(rpcAddrs) < opts.FallbackRPCEndpoints && !sameAddr(endpoint, rpc) {
+				rpcAddrs = append(rpcAddrs, endpoint)
+			}
+		}
 	}
 
 	return &ChainClientConfig{
@@ -124,6 +190,8 @@ func GetChainConfigWithOpts(ctx context.Context, c registry.ChainInfo, opts *Cha
 		Key:            "default",
 		ChainID:        c.ChainID,
 		RPCAddr:        rpc,
+		RPCAddrs:       rpcAddrs,
+		GRPCAddr:       c.GetGRPCEndpoint(),
 		AccountPrefix:  c.Bech32Prefix,
 		KeyringBackend: "test",
 		GasAdjustment:  1.2,
@@ -133,17 +201,52 @@ func GetChainConfigWithOpts(ctx context.Context, c registry.ChainInfo, opts *Cha
 		Timeout:        "20s",
 		OutputFormat:   "json",
 		SignModeStr:    "direct",
+		ExtraCodecs:    c.ExtraCodecs,
+		KeyAlgos:       c.KeyAlgos,
 		Slip44:         c.Slip44,
 	}, nil
 }
 
+// chainGasPrices returns the gas prices of the chain at the level selected by opts.
+func chainGasPrices(c registry.ChainInfo, opts *ChainConfigOptions) (string, error) {
+	var gasPriceLevel string
+	if opts != nil {
+		gasPriceLevel = opts.GasPriceLevel
+	}
+	gasPrices := c.GasPrices(gasPriceLevel)
+	if gasPrices == "" {
+		// Chains that do not list their fee tokens most likely accept the first asset for fees.
+		assetList, err := c.GetAssetList()
+		if err != nil {
+			return "", err
+		}
+		if len(assetList.Assets) > 0 {
+			gasPrices = fmt.Sprintf("%.2f%s", 0.01, assetList.Assets[0].Base)
+		}
+	}
+	return gasPrices, nil
+}
+
 type ChainConfigOptions struct {
 	PreferredRpcHosts   []string
 	PreferredRpcDomains []string
+	// Registry is the chain registry to read the chain from. Defaults to the cosmos/chain-registry on GitHub.
+	Registry registry.ChainRegistry
+	// GasPriceLevel selects the low, average (the default) or high gas price published by the chain.
+	GasPriceLevel string
+	// EndpointPolicy ranks the chain's RPC endpoints by probing them when no preferred endpoint is usable.
+	// A random healthy endpoint is selected when it is not set.
+	EndpointPolicy registry.SelectionPolicy
+	// FallbackRPCEndpoints is the maximum number of other healthy RPC endpoints of the chain listed in RPCAddrs,
+	// which the client fails over to and balances load across. No other endpoints are listed if it is zero.
+	FallbackRPCEndpoints int
 }
 
 func GetChain(ctx context.Context, chainName string, logger *zap.Logger, options *ChainConfigOptions) (*ChainClientConfig, error) {
 	registry := registry.DefaultChainRegistry(logger)
+	if options != nil && options.Registry != nil {
+		registry = options.Registry
+	}
 	chainInfo, err := registry.GetChain(chainName)
 	if err != nil {
 		logger.Info(
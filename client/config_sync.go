@@ -0,0 +1,201 @@
+package client
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	registry "github.com/KyleMoser/cosmos-client/client/chain_registry"
+)
+
+// Fields of ChainClientConfig that are synced from the chain registry, named after their config file keys.
+const (
+	SyncFieldChainID       = "chain-id"
+	SyncFieldRPCAddr       = "rpc-addr"
+	SyncFieldRPCAddrs      = "rpc-addrs"
+	SyncFieldGRPCAddr      = "grpc-addr"
+	SyncFieldAccountPrefix = "account-prefix"
+	SyncFieldSlip44        = "slip44"
+	SyncFieldGasPrices     = "gas-prices"
+	SyncFieldExtraCodecs   = "extra-codecs"
+	SyncFieldKeyAlgos      = "key-algos"
+)
+
+// SyncFields lists every field synced from the chain registry. Fields that users set themselves,
+// such as the key, keyring backend, key directory and timeouts, are never synced.
+var SyncFields = []string{
+	SyncFieldChainID,
+	SyncFieldRPCAddr,
+	SyncFieldRPCAddrs,
+	SyncFieldGRPCAddr,
+	SyncFieldAccountPrefix,
+	SyncFieldSlip44,
+	SyncFieldGasPrices,
+	SyncFieldExtraCodecs,
+	SyncFieldKeyAlgos,
+}
+
+// ConfigChange is a difference between a saved chain config and the chain registry.
+type ConfigChange struct {
+	Field string `json:"field" yaml:"field"`
+	Old   string `json:"old" yaml:"old"`
+	New   string `json:"new" yaml:"new"`
+
+	apply func(*ChainClientConfig)
+}
+
+// Apply applies the change to the config.
+func (c ConfigChange) Apply(ccc *ChainClientConfig) {
+	c.apply(ccc)
+}
+
+// DiffChainConfig compares the saved config with the chain in the registry and returns the changes
+// needed to bring it up to date. The RPC and gRPC addresses are only changed when the saved address
+// is no longer listed in the registry, in which case a new RPC endpoint is selected as in GetChainConfigWithOpts.
+// Fallback RPC addresses that are no longer listed are removed and, if opts.FallbackRPCEndpoints is set,
+// replaced by other healthy endpoints. Extra codecs and key algorithms are only synced when the registry lists any.
+func DiffChainConfig(ctx context.Context, saved *ChainClientConfig, c registry.ChainInfo, opts *ChainConfigOptions) ([]ConfigChange, error) {
+	var changes []ConfigChange
+	diff := func(field, oldValue, newValue string, apply func(*ChainClientConfig)) {
+		if oldValue != newValue {
+			changes = append(changes, ConfigChange{Field: field, Old: oldValue, New: newValue, apply: apply})
+		}
+	}
+
+	diff(SyncFieldChainID, saved.ChainID, c.ChainID, func(ccc *ChainClientConfig) { ccc.ChainID = c.ChainID })
+
+	endpoints, err := c.GetAllRPCEndpoints()
+	if err != nil {
+		return nil, err
+	}
+	rpcAddr := saved.RPCAddr
+	if !addrListed(endpoints, rpcAddr) {
+		// The saved endpoint was removed from the registry, so select a healthy one.
+		fresh, err := GetChainConfigWithOpts(ctx, c, opts)
+		if err != nil {
+			return nil, err
+		}
+		rpcAddr = fresh.RPCAddr
+		diff(SyncFieldRPCAddr, saved.RPCAddr, rpcAddr, func(ccc *ChainClientConfig) { ccc.RPCAddr = rpcAddr })
+	}
+
+	rpcAddrs, err := syncRPCAddrs(ctx, c, endpoints, rpcAddr, saved.RPCAddrs, opts)
+	if err != nil {
+		return nil, err
+	}
+	diff(SyncFieldRPCAddrs, strings.Join(saved.RPCAddrs, ","), strings.Join(rpcAddrs, ","), func(ccc *ChainClientConfig) {
+		ccc.RPCAddrs = rpcAddrs
+	})
+
+	if !grpcAddrListed(c, saved.GRPCAddr) {
+		grpc := c.GetGRPCEndpoint()
+		diff(SyncFieldGRPCAddr, saved.GRPCAddr, grpc, func(ccc *ChainClientConfig) { ccc.GRPCAddr = grpc })
+	}
+
+	diff(SyncFieldAccountPrefix, saved.AccountPrefix, c.Bech32Prefix, func(ccc *ChainClientConfig) { ccc.AccountPrefix = c.Bech32Prefix })
+	diff(SyncFieldSlip44, strconv.Itoa(saved.Slip44), strconv.Itoa(c.Slip44), func(ccc *ChainClientConfig) { ccc.Slip44 = c.Slip44 })
+
+	gasPrices, err := chainGasPrices(c, opts)
+	if err != nil {
+		return nil, err
+	}
+	if gasPrices != "" {
+		diff(SyncFieldGasPrices, saved.GasPrices, gasPrices, func(ccc *ChainClientConfig) { ccc.GasPrices = gasPrices })
+	}
+
+	// Most chains do not list codecs or key algorithms, which must not clear the ones configured by hand.
+	if len(c.ExtraCodecs) > 0 {
+		diff(SyncFieldExtraCodecs, strings.Join(saved.ExtraCodecs, ","), strings.Join(c.ExtraCodecs, ","), func(ccc *ChainClientConfig) {
+			ccc.ExtraCodecs = c.ExtraCodecs
+		})
+	}
+	if len(c.KeyAlgos) > 0 {
+		diff(SyncFieldKeyAlgos, strings.Join(saved.KeyAlgos, ","), strings.Join(c.KeyAlgos, ","), func(ccc *ChainClientConfig) {
+			ccc.KeyAlgos = c.KeyAlgos
+		})
+	}
+	return changes, nil
+}
+
+// ApplyConfigChanges applies the changes of the given fields to the config, or every change if no fields are given.
+// It returns the changes that were applied.
+func ApplyConfigChanges(ccc *ChainClientConfig, changes []ConfigChange, fields ...string) []ConfigChange {
+	var applied []ConfigChange
+	for _, change := range changes {
+		if len(fields) > 0 && !containsString(fields, change.Field) {
+			continue
+		}
+		change.Apply(ccc)
+		applied = append(applied, change)
+	}
+	return applied
+}
+
+// syncRPCAddrs returns the saved fallback RPC addresses that are still listed in the registry and differ from
+// the RPC address. If opts.FallbackRPCEndpoints is set, they are topped up with other healthy endpoints.
+func syncRPCAddrs(ctx context.Context, c registry.ChainInfo, endpoints []string, rpcAddr string, saved []string, opts *ChainConfigOptions) ([]string, error) {
+	var rpcAddrs []string
+	add := func(addr string) {
+		if !sameAddr(addr, rpcAddr) && !addrListed(rpcAddrs, addr) {
+			rpcAddrs = append(rpcAddrs, addr)
+		}
+	}
+	for _, addr := range saved {
+		if addrListed(endpoints, addr) {
+			add(addr)
+		}
+	}
+
+	if opts != nil && len(rpcAddrs) < opts.FallbackRPCEndpoints {
+		healthy, err := c.GetRPCEndpoints(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, addr := range healthy {
+			if len(rpcAddrs) < opts.FallbackRPCEndpoints {
+				add(addr)
+			}
+		}
+	}
+	return rpcAddrs, nil
+}
+
+// addrListed reports whether the address is one of the given endpoints.
+func addrListed(endpoints []string, addr string) bool {
+	if addr == "" {
+		return false
+	}
+	for _, endpoint := range endpoints {
+		if sameAddr(endpoint, addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// grpcAddrListed reports whether the address is one of the gRPC endpoints of the chain in the registry.
+// An empty address is considered listed if the chain has no gRPC endpoints.
+func grpcAddrListed(c registry.ChainInfo, addr string) bool {
+	if addr == "" {
+		return len(c.Apis.GRPC) == 0
+	}
+	for _, endpoint := range c.Apis.GRPC {
+		if sameAddr(endpoint.Address, addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameAddr compares two endpoint addresses, ignoring the scheme, default ports and trailing slashes.
+func sameAddr(a, b string) bool {
+	normalize := func(addr string) string {
+		addr = strings.ToLower(strings.TrimSuffix(addr, "/"))
+		for _, scheme := range []string{"https://", "http://", "grpcs://", "grpc://", "tcp://"} {
+			addr = strings.TrimPrefix(addr, scheme)
+		}
+		addr = strings.TrimSuffix(addr, ":443")
+		return strings.TrimSuffix(addr, ":80")
+	}
+	return normalize(a) == normalize(b)
+}
@@ -0,0 +1,79 @@
+package client_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/KyleMoser/cosmos-client/client"
+	registry "github.com/KyleMoser/cosmos-client/client/chain_registry"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestDiffChainConfig(t *testing.T) {
+	chain := registry.NewChainInfo(zaptest.NewLogger(t))
+	chain.ChainName = "osmosis"
+	chain.ChainID = "osmosis-1"
+	chain.Bech32Prefix = "osmo"
+	chain.Slip44 = 118
+	chain.Apis.RPC = []registry.Endpoint{{Address: "https://rpc.osmosis.zone"}, {Address: "https://rpc2.osmosis.zone"}}
+	chain.Apis.GRPC = []registry.Endpoint{{Address: "grpc.osmosis.zone:9090"}}
+	chain.Fees.FeeTokens = []registry.FeeToken{{Denom: "uosmo", AverageGasPrice: 0.025}}
+
+	saved := client.GetOsmosisConfig("/keys", false)
+	saved.RPCAddr = "https://rpc.osmosis.zone:443"
+	saved.RPCAddrs = []string{"https://rpc.osmosis.zone", "https://rpc2.osmosis.zone", "https://removed.example.com"}
+	saved.ExtraCodecs = []string{"osmosis"}
+	saved.KeyAlgos = []string{"secp256k1"}
+	saved.Key = "mykey"
+	saved.KeyringBackend = "os"
+
+	changes, err := client.DiffChainConfig(context.Background(), saved, chain, nil)
+	require.NoError(t, err)
+
+	fields := map[string]client.ConfigChange{}
+	for _, change := range changes {
+		fields[change.Field] = change
+	}
+	// The saved RPC address is still listed, so it is kept.
+	require.NotContains(t, fields, client.SyncFieldRPCAddr)
+	// Fallback addresses that are no longer listed, or are the RPC address, are removed.
+	require.Equal(t, "https://rpc2.osmosis.zone", fields[client.SyncFieldRPCAddrs].New)
+	// The registry lists no codecs or key algorithms, so the configured ones are kept.
+	require.NotContains(t, fields, client.SyncFieldExtraCodecs)
+	require.NotContains(t, fields, client.SyncFieldKeyAlgos)
+	require.Equal(t, "grpc.osmosis.zone:9090", fields[client.SyncFieldGRPCAddr].New)
+	require.Equal(t, "0.025uosmo", fields[client.SyncFieldGasPrices].New)
+	require.Equal(t, "118", fields[client.SyncFieldSlip44].New)
+
+	applied := client.ApplyConfigChanges(saved, changes, client.SyncFieldGasPrices)
+	require.Len(t, applied, 1)
+	require.Equal(t, "0.025uosmo", saved.GasPrices)
+	require.NotEqual(t, 118, saved.Slip44)
+
+	client.ApplyConfigChanges(saved, changes)
+	require.Equal(t, "grpc.osmosis.zone:9090", saved.GRPCAddr)
+	require.Equal(t, []string{"https://rpc2.osmosis.zone"}, saved.RPCAddrs)
+	require.Equal(t, []string{"osmosis"}, saved.ExtraCodecs)
+	require.Equal(t, 118, saved.Slip44)
+	require.Equal(t, "mykey", saved.Key)
+	require.Equal(t, "os", saved.KeyringBackend)
+}
+
+func TestDiffChainConfigKeyAlgos(t *testing.T) {
+	chain := registry.NewChainInfo(zaptest.NewLogger(t))
+	chain.ChainName = "osmosis"
+	chain.ChainID = "osmosis-1"
+	chain.Apis.RPC = []registry.Endpoint{{Address: "https://rpc.osmosis.zone"}}
+	chain.Fees.FeeTokens = []registry.FeeToken{{Denom: "uosmo", AverageGasPrice: 0.025}}
+	chain.KeyAlgos = []string{"secp256k1"}
+
+	saved := client.GetOsmosisConfig("/keys", false)
+	saved.RPCAddr = "https://rpc.osmosis.zone"
+
+	changes, err := client.DiffChainConfig(context.Background(), saved, chain, nil)
+	require.NoError(t, err)
+
+	client.ApplyConfigChanges(saved, changes, client.SyncFieldKeyAlgos)
+	require.Equal(t, []string{"secp256k1"}, saved.KeyAlgos)
+}
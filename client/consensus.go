@@ -0,0 +1,338 @@
+package client
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	tmtypes "github.com/cometbft/cometbft/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"go.uber.org/zap"
+)
+
+// maxValidatorsPerPage is the largest page size accepted by CometBFT's validators endpoint.
+const maxValidatorsPerPage = 100
+
+// ValidatorInfo describes a member of the CometBFT validator set, annotated with
+// the staking module's view of the same validator where available.
+type ValidatorInfo struct {
+	Address          string `json:"address"`
+	ConsAddress      string `json:"cons_address"`
+	Moniker          string `json:"moniker,omitempty"`
+	OperatorAddress  string `json:"operator_address,omitempty"`
+	VotingPower      int64  `json:"voting_power"`
+	ProposerPriority int64  `json:"proposer_priority"`
+}
+
+// ValidatorSet is the CometBFT validator set at a given height.
+type ValidatorSet struct {
+	BlockHeight      int64           `json:"block_height"`
+	TotalVotingPower int64           `json:"total_voting_power"`
+	Validators       []ValidatorInfo `json:"validators"`
+}
+
+// stakingValidator is the subset of staking module information used to annotate consensus validators.
+type stakingValidator struct {
+	Moniker         string
+	OperatorAddress string
+}
+
+// QueryValidatorSet returns the complete CometBFT validator set at the given height
+// (or the latest height if zero), fetching every page. Validators are annotated with
+// their staking moniker and operator address when the staking module can be queried.
+func (cc *ChainClient) QueryValidatorSet(ctx context.Context, height int64) (*ValidatorSet, error) {
+	vals, blockHeight, err := cc.queryCometValidators(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+
+	staking, err := cc.queryStakingValidatorsByConsAddress(ctx)
+	if err != nil {
+		// The validator set is still useful without monikers, e.g. on chains without x/staking.
+		cc.log.Debug("Failed to query staking validators", zap.Error(err))
+		staking = map[string]stakingValidator{}
+	}
+
+	out := &ValidatorSet{BlockHeight: blockHeight, Validators: make([]ValidatorInfo, len(vals))}
+	for i, v := range vals {
+		out.TotalVotingPower += v.VotingPower
+		out.Validators[i] = cc.validatorInfo(v, staking)
+	}
+	return out, nil
+}
+
+func (cc *ChainClient) validatorInfo(v *tmtypes.Validator, staking map[string]stakingValidator) ValidatorInfo {
+	info := ValidatorInfo{
+		Address:          v.Address.String(),
+		VotingPower:      v.VotingPower,
+		ProposerPriority: v.ProposerPriority,
+	}
+	if consAddr, err := cc.EncodeBech32ConsAddr(sdk.AccAddress(v.Address)); err == nil {
+		info.ConsAddress = consAddr
+	}
+	if sv, ok := staking[info.Address]; ok {
+		info.Moniker = sv.Moniker
+		info.OperatorAddress = sv.OperatorAddress
+	}
+	return info
+}
+
+func (cc *ChainClient) queryCometValidators(ctx context.Context, height int64) ([]*tmtypes.Validator, int64, error) {
+	var heightPtr *int64
+	if height > 0 {
+		heightPtr = &height
+	}
+
+	var (
+		vals        []*tmtypes.Validator
+		blockHeight int64
+	)
+	perPage := maxValidatorsPerPage
+	for page := 1; ; page++ {
+		res, err := cc.RPCClient.Validators(ctx, heightPtr, &page, &perPage)
+		if err != nil {
+			return nil, 0, err
+		}
+		// Pin the remaining pages to the height of the first page in case a block is committed meanwhile.
+		blockHeight = res.BlockHeight
+		heightPtr = &blockHeight
+
+		vals = append(vals, res.Validators...)
+		if len(res.Validators) == 0 || len(vals) >= res.Total {
+			break
+		}
+	}
+	return vals, blockHeight, nil
+}
+
+// queryStakingValidatorsByConsAddress returns every staking validator (in any bond status)
+// keyed by the upper case hex encoding of its consensus address.
+func (cc *ChainClient) queryStakingValidatorsByConsAddress(ctx context.Context) (map[string]stakingValidator, error) {
+	out := map[string]stakingValidator{}
+	queryClient := stakingtypes.NewQueryClient(cc)
+	pageReq := DefaultPageRequest()
+	pageReq.CountTotal = false
+	for {
+		res, err := queryClient.Validators(ctx, &stakingtypes.QueryValidatorsRequest{Pagination: pageReq})
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range res.Validators {
+			consAddr, err := v.GetConsAddr()
+			if err != nil {
+				return nil, fmt.Errorf("validator %s: %w", v.OperatorAddress, err)
+			}
+			out[fmt.Sprintf("%X", consAddr)] = stakingValidator{
+				Moniker:         v.GetMoniker(),
+				OperatorAddress: v.OperatorAddress,
+			}
+		}
+		if res.Pagination == nil || len(res.Pagination.NextKey) == 0 {
+			return out, nil
+		}
+		pageReq.Key = res.Pagination.NextKey
+	}
+}
+
+// VoteStatus is a validator's vote in a single round of consensus.
+type VoteStatus string
+
+const (
+	VoteAbsent VoteStatus = "absent"
+	VoteNil    VoteStatus = "nil"
+	VoteBlock  VoteStatus = "block"
+)
+
+const (
+	// nilVoteFingerprint is how CometBFT renders the block hash fingerprint of a vote for nil.
+	nilVoteFingerprint = "000000000000"
+	// absentVote is how CometBFT renders the vote of a validator that has not voted.
+	absentVote = "nil-Vote"
+)
+
+// ValidatorParticipation is a validator's prevote and precommit in a consensus round.
+type ValidatorParticipation struct {
+	ValidatorInfo
+	Prevote   VoteStatus `json:"prevote"`
+	Precommit VoteStatus `json:"precommit"`
+	// BlockHash is the fingerprint of the block hash voted for, if any.
+	BlockHash string `json:"block_hash,omitempty"`
+}
+
+// RoundParticipation summarizes the votes cast in one round of consensus.
+type RoundParticipation struct {
+	Round              int32                    `json:"round"`
+	PrevotePower       int64                    `json:"prevote_power"`
+	PrecommitPower     int64                    `json:"precommit_power"`
+	PrevotesBitArray   string                   `json:"prevotes_bit_array"`
+	PrecommitsBitArray string                   `json:"precommits_bit_array"`
+	Validators         []ValidatorParticipation `json:"validators"`
+}
+
+// ConsensusParticipation is a view of the node's current consensus state showing
+// which validators have prevoted and precommitted in each round of the current height.
+type ConsensusParticipation struct {
+	Height           int64                `json:"height"`
+	Round            int32                `json:"round"`
+	Step             string               `json:"step"`
+	ProposerAddress  string               `json:"proposer_address"`
+	TotalVotingPower int64                `json:"total_voting_power"`
+	Rounds           []RoundParticipation `json:"rounds"`
+}
+
+// roundStateSimple mirrors the JSON rendering of CometBFT's consensus RoundStateSimple.
+type roundStateSimple struct {
+	HeightRoundStep string `json:"height/round/step"`
+	Votes           []struct {
+		Round              int32    `json:"round"`
+		Prevotes           []string `json:"prevotes"`
+		PrevotesBitArray   string   `json:"prevotes_bit_array"`
+		Precommits         []string `json:"precommits"`
+		PrecommitsBitArray string   `json:"precommits_bit_array"`
+	} `json:"height_vote_set"`
+	Proposer struct {
+		Address string `json:"address"`
+	} `json:"proposer"`
+}
+
+// QueryConsensusParticipation reads the node's current consensus state and reports the
+// prevote and precommit of every validator in each round of the height being decided.
+// This is mostly useful to diagnose a chain halt, where it shows who is missing.
+func (cc *ChainClient) QueryConsensusParticipation(ctx context.Context) (*ConsensusParticipation, error) {
+	res, err := cc.RPCClient.ConsensusState(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var rs roundStateSimple
+	if err := json.Unmarshal(res.RoundState, &rs); err != nil {
+		return nil, fmt.Errorf("failed to parse consensus state: %w", err)
+	}
+	height, round, step, err := parseHeightRoundStep(rs.HeightRoundStep)
+	if err != nil {
+		return nil, err
+	}
+
+	// Votes are indexed by the position of the validator in the validator set of the height being decided.
+	valSet, err := cc.QueryValidatorSet(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &ConsensusParticipation{
+		Height:           height,
+		Round:            round,
+		Step:             step,
+		ProposerAddress:  rs.Proposer.Address,
+		TotalVotingPower: valSet.TotalVotingPower,
+	}
+	for _, rv := range rs.Votes {
+		rp := RoundParticipation{
+			Round:              rv.Round,
+			PrevotesBitArray:   rv.PrevotesBitArray,
+			PrecommitsBitArray: rv.PrecommitsBitArray,
+			Validators:         make([]ValidatorParticipation, len(valSet.Validators)),
+		}
+		for i, v := range valSet.Validators {
+			vp := ValidatorParticipation{ValidatorInfo: v, Prevote: VoteAbsent, Precommit: VoteAbsent}
+			if i < len(rv.Prevotes) {
+				if vp.Prevote, vp.BlockHash, err = parseVote(rv.Prevotes[i]); err != nil {
+					return nil, fmt.Errorf("prevote of validator %d in round %d: %w", i, rv.Round, err)
+				}
+			}
+			if i < len(rv.Precommits) {
+				var hash string
+				if vp.Precommit, hash, err = parseVote(rv.Precommits[i]); err != nil {
+					return nil, fmt.Errorf("precommit of validator %d in round %d: %w", i, rv.Round, err)
+				}
+				if hash != "" {
+					vp.BlockHash = hash
+				}
+			}
+
+			if vp.Prevote != VoteAbsent {
+				rp.PrevotePower += v.VotingPower
+			}
+			if vp.Precommit != VoteAbsent {
+				rp.PrecommitPower += v.VotingPower
+			}
+			rp.Validators[i] = vp
+		}
+		out.Rounds = append(out.Rounds, rp)
+	}
+
+	sort.Slice(out.Rounds, func(i, j int) bool { return out.Rounds[i].Round < out.Rounds[j].Round })
+	return out, nil
+}
+
+// parseHeightRoundStep parses CometBFT's "height/round/step" representation, e.g. "1234/0/1".
+func parseHeightRoundStep(hrs string) (height int64, round int32, step string, err error) {
+	parts := strings.Split(hrs, "/")
+	if len(parts) != 3 {
+		return 0, 0, "", fmt.Errorf("unexpected height/round/step %q", hrs)
+	}
+	if height, err = strconv.ParseInt(parts[0], 10, 64); err != nil || height <= 0 {
+		return 0, 0, "", fmt.Errorf("unexpected height in %q", hrs)
+	}
+	r, err := strconv.ParseInt(parts[1], 10, 32)
+	if err != nil || r < 0 {
+		return 0, 0, "", fmt.Errorf("unexpected round in %q", hrs)
+	}
+	if _, err := strconv.ParseUint(parts[2], 10, 8); err != nil {
+		return 0, 0, "", fmt.Errorf("unexpected step in %q", hrs)
+	}
+	return height, int32(r), roundStepName(parts[2]), nil
+}
+
+// roundStepName converts CometBFT's numeric round step into a readable name.
+func roundStepName(step string) string {
+	switch step {
+	case "1":
+		return "NewHeight"
+	case "2":
+		return "NewRound"
+	case "3":
+		return "Propose"
+	case "4":
+		return "Prevote"
+	case "5":
+		return "PrevoteWait"
+	case "6":
+		return "Precommit"
+	case "7":
+		return "PrecommitWait"
+	case "8":
+		return "Commit"
+	default:
+		return step
+	}
+}
+
+// parseVote parses the string rendering of a CometBFT vote, e.g.
+// "Vote{0:ABCDEF123456 1234/00/SIGNED_MSG_TYPE_PREVOTE(Prevote) 8E4F12345678 ...}" or "nil-Vote",
+// returning the fingerprint of the block hash voted for, if any.
+func parseVote(vote string) (VoteStatus, string, error) {
+	if vote == absentVote {
+		return VoteAbsent, "", nil
+	}
+	if !strings.HasPrefix(vote, "Vote{") || !strings.HasSuffix(vote, "}") {
+		return "", "", fmt.Errorf("unexpected vote %q", vote)
+	}
+	fields := strings.Fields(strings.TrimSuffix(strings.TrimPrefix(vote, "Vote{"), "}"))
+	if len(fields) < 3 || !strings.Contains(fields[0], ":") {
+		return "", "", fmt.Errorf("unexpected vote %q", vote)
+	}
+	hash := fields[2]
+	if _, err := hex.DecodeString(hash); err != nil || len(hash) != len(nilVoteFingerprint) {
+		return "", "", fmt.Errorf("unexpected block hash in vote %q", vote)
+	}
+	if hash == nilVoteFingerprint {
+		return VoteNil, "", nil
+	}
+	return VoteBlock, hash, nil
+}
@@ -0,0 +1,161 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/KyleMoser/cosmos-client/client"
+	"github.com/cometbft/cometbft/libs/bytes"
+	rpcclient "github.com/cometbft/cometbft/rpc/client"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	tmtypes "github.com/cometbft/cometbft/types"
+	"github.com/stretchr/testify/require"
+)
+
+// consensusRPC serves a consensus state dump and a validator set of three validators without staking validators.
+type consensusRPC struct {
+	rpcclient.Client
+	roundState string
+}
+
+func (r *consensusRPC) ConsensusState(context.Context) (*coretypes.ResultConsensusState, error) {
+	return &coretypes.ResultConsensusState{RoundState: json.RawMessage(r.roundState)}, nil
+}
+
+func (r *consensusRPC) Validators(_ context.Context, height *int64, _, _ *int) (*coretypes.ResultValidators, error) {
+	vals := []*tmtypes.Validator{
+		{Address: bytes.HexBytes("validator_0_________"), VotingPower: 50},
+		{Address: bytes.HexBytes("validator_1_________"), VotingPower: 30},
+		{Address: bytes.HexBytes("validator_2_________"), VotingPower: 20},
+	}
+	return &coretypes.ResultValidators{BlockHeight: *height, Validators: vals, Count: len(vals), Total: len(vals)}, nil
+}
+
+func (r *consensusRPC) ABCIQueryWithOptions(context.Context, string, bytes.HexBytes, rpcclient.ABCIQueryOptions) (*coretypes.ResultABCIQuery, error) {
+	return &coretypes.ResultABCIQuery{}, nil
+}
+
+// roundState is a consensus state dump of a CometBFT node at height 1262197, round 1, in the precommit step.
+const roundState = `{
+  "height/round/step": "1262197/1/6",
+  "start_time": "2019-08-01T11:52:38.962730289Z",
+  "proposal_block_hash": "634ADAF1F402663BEC2ABC340ECE8B4B45AA906FA603272ACC5F5EED3097E009",
+  "locked_block_hash": "",
+  "valid_block_hash": "",
+  "height_vote_set": [
+    {
+      "round": 1,
+      "prevotes": [
+        "Vote{0:76616C696461 1262197/01/SIGNED_MSG_TYPE_PREVOTE(Prevote) 634ADAF1F402 7BB974E1BA40 000000000000 @ 2019-08-01T11:52:41.513572509Z}",
+        "nil-Vote",
+        "Vote{2:76616C696461 1262197/01/SIGNED_MSG_TYPE_PREVOTE(Prevote) 634ADAF1F402 BF1D2A2F0F05 000000000000 @ 2019-08-01T11:52:41.514126231Z}"
+      ],
+      "prevotes_bit_array": "BA{3:x_x} 70/100 = 0.70",
+      "precommits": [
+        "Vote{0:76616C696461 1262197/01/SIGNED_MSG_TYPE_PRECOMMIT(Precommit) 634ADAF1F402 2C35DB4CA1FF 000000000000 @ 2019-08-01T11:52:42.513572509Z}",
+        "nil-Vote",
+        "nil-Vote"
+      ],
+      "precommits_bit_array": "BA{3:x__} 50/100 = 0.50"
+    },
+    {
+      "round": 0,
+      "prevotes": [
+        "Vote{0:76616C696461 1262197/00/SIGNED_MSG_TYPE_PREVOTE(Prevote) 000000000000 4C1C4A8E3F28 000000000000 @ 2019-08-01T11:52:35.513572509Z}",
+        "Vote{1:76616C696461 1262197/00/SIGNED_MSG_TYPE_PREVOTE(Prevote) 000000000000 57B2B37A5E8F 000000000000 @ 2019-08-01T11:52:35.613572509Z}",
+        "nil-Vote"
+      ],
+      "prevotes_bit_array": "BA{3:xx_} 80/100 = 0.80",
+      "precommits": [
+        "Vote{0:76616C696461 1262197/00/SIGNED_MSG_TYPE_PRECOMMIT(Precommit) 000000000000 FB0E0D6C7E33 000000000000 @ 2019-08-01T11:52:36.513572509Z}",
+        "Vote{1:76616C696461 1262197/00/SIGNED_MSG_TYPE_PRECOMMIT(Precommit) 000000000000 0DA6C5B1F1A1 000000000000 @ 2019-08-01T11:52:36.613572509Z}"
+      ],
+      "precommits_bit_array": "BA{3:xx_} 80/100 = 0.80"
+    }
+  ],
+  "proposer": {
+    "address": "76616C696461746F725F305F5F5F5F5F5F5F5F5F",
+    "index": 0
+  }
+}`
+
+func TestQueryConsensusParticipation(t *testing.T) {
+	cc := &client.ChainClient{
+		Config:    &client.ChainClientConfig{AccountPrefix: "cosmos"},
+		RPCClient: &consensusRPC{roundState: roundState},
+	}
+	res, err := cc.QueryConsensusParticipation(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(1262197), res.Height)
+	require.Equal(t, int32(1), res.Round)
+	require.Equal(t, "Precommit", res.Step)
+	require.Equal(t, int64(100), res.TotalVotingPower)
+	require.Len(t, res.Rounds, 2)
+
+	votes := func(round client.RoundParticipation) (prevotes, precommits []client.VoteStatus, hashes []string) {
+		for _, v := range round.Validators {
+			prevotes = append(prevotes, v.Prevote)
+			precommits = append(precommits, v.Precommit)
+			hashes = append(hashes, v.BlockHash)
+		}
+		return prevotes, precommits, hashes
+	}
+
+	// Rounds are sorted, and votes for nil are told apart from missing votes.
+	round0 := res.Rounds[0]
+	require.Equal(t, int32(0), round0.Round)
+	prevotes, precommits, hashes := votes(round0)
+	require.Equal(t, []client.VoteStatus{client.VoteNil, client.VoteNil, client.VoteAbsent}, prevotes)
+	require.Equal(t, []client.VoteStatus{client.VoteNil, client.VoteNil, client.VoteAbsent}, precommits)
+	require.Equal(t, []string{"", "", ""}, hashes)
+	require.Equal(t, int64(80), round0.PrevotePower)
+	require.Equal(t, int64(80), round0.PrecommitPower)
+
+	round1 := res.Rounds[1]
+	require.Equal(t, int32(1), round1.Round)
+	prevotes, precommits, hashes = votes(round1)
+	require.Equal(t, []client.VoteStatus{client.VoteBlock, client.VoteAbsent, client.VoteBlock}, prevotes)
+	require.Equal(t, []client.VoteStatus{client.VoteBlock, client.VoteAbsent, client.VoteAbsent}, precommits)
+	require.Equal(t, []string{"634ADAF1F402", "", "634ADAF1F402"}, hashes)
+	require.Equal(t, int64(70), round1.PrevotePower)
+	require.Equal(t, int64(50), round1.PrecommitPower)
+	require.Equal(t, "BA{3:x_x} 70/100 = 0.70", round1.PrevotesBitArray)
+}
+
+func TestQueryConsensusParticipationMalformed(t *testing.T) {
+	withVote := func(vote string) string {
+		return `{"height/round/step": "10/0/4", "height_vote_set": [{"round": 0, "prevotes": [` + vote + `]}]}`
+	}
+	testCases := map[string]struct {
+		roundState  string
+		expectedErr string
+	}{
+		"not json":             {roundState: `Vote{`, expectedErr: "failed to parse consensus state"},
+		"missing step":         {roundState: `{"height/round/step": "10/0"}`, expectedErr: "unexpected height/round/step"},
+		"empty":                {roundState: `{}`, expectedErr: "unexpected height/round/step"},
+		"non-numeric height":   {roundState: `{"height/round/step": "ten/0/4"}`, expectedErr: "unexpected height"},
+		"zero height":          {roundState: `{"height/round/step": "0/0/4"}`, expectedErr: "unexpected height"},
+		"negative round":       {roundState: `{"height/round/step": "10/-1/4"}`, expectedErr: "unexpected round"},
+		"empty step":           {roundState: `{"height/round/step": "10/0/"}`, expectedErr: "unexpected step"},
+		"unterminated vote":    {roundState: withVote(`"Vote{0:76616C696461 10/00/SIGNED_MSG_TYPE_PREVOTE(Prevote) 634ADAF1F402"`), expectedErr: "prevote of validator 0 in round 0: unexpected vote"},
+		"empty vote":           {roundState: withVote(`"Vote{}"`), expectedErr: "unexpected vote"},
+		"truncated vote":       {roundState: withVote(`"Vote{0:76616C696461 10/00/SIGNED_MSG_TYPE_PREVOTE(Prevote)}"`), expectedErr: "unexpected vote"},
+		"unknown vote":         {roundState: withVote(`"garbage"`), expectedErr: "unexpected vote"},
+		"empty vote string":    {roundState: withVote(`""`), expectedErr: "unexpected vote"},
+		"invalid block hash":   {roundState: withVote(`"Vote{0:76616C696461 10/00/SIGNED_MSG_TYPE_PREVOTE(Prevote) XYZ @ 2019-08-01T11:52:35Z}"`), expectedErr: "unexpected block hash"},
+		"truncated block hash": {roundState: withVote(`"Vote{0:76616C696461 10/00/SIGNED_MSG_TYPE_PREVOTE(Prevote) 634ADA 7BB974E1BA40 @ 2019-08-01T11:52:35Z}"`), expectedErr: "unexpected block hash"},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			cc := &client.ChainClient{
+				Config:    &client.ChainClientConfig{AccountPrefix: "cosmos"},
+				RPCClient: &consensusRPC{roundState: tc.roundState},
+			}
+			_, err := cc.QueryConsensusParticipation(context.Background())
+			require.ErrorContains(t, err, tc.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package client
+
+import (
+	"context"
+	"strings"
+
+	registry "github.com/KyleMoser/cosmos-client/client/chain_registry"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+	"go.uber.org/zap"
+)
+
+// QueryDenomTrace returns the denom trace of an ibc/HASH denom.
+func (cc *ChainClient) QueryDenomTrace(ctx context.Context, denom string) (*transfertypes.DenomTrace, error) {
+	res, err := transfertypes.NewQueryClient(cc).DenomTrace(ctx, &transfertypes.QueryDenomTraceRequest{
+		Hash: strings.TrimPrefix(denom, "ibc/"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.DenomTrace, nil
+}
+
+// NewDenomResolver returns a resolver for the denoms held on this chain, built from the chain's asset list in the registry.
+func (cc *ChainClient) NewDenomResolver() (*registry.DenomResolver, error) {
+	assetList, err := cc.ChainRegistry().GetAssetList(cc.Config.ChainName)
+	if err != nil {
+		return nil, err
+	}
+	return registry.NewDenomResolver(assetList), nil
+}
+
+// HumanizeCoins converts the coins to the display units of their assets. The denom traces of IBC denoms
+// that are missing from the resolver are queried from the chain, so they at least show their base denom.
+func (cc *ChainClient) HumanizeCoins(ctx context.Context, resolver *registry.DenomResolver, coins sdk.Coins) []registry.DisplayCoin {
+	for _, coin := range coins {
+		if !strings.HasPrefix(coin.Denom, "ibc/") || resolver.Resolve(coin.Denom).Path != "" {
+			continue
+		}
+		trace, err := cc.QueryDenomTrace(ctx, coin.Denom)
+		if err != nil {
+			cc.log.Debug("Failed to query denom trace", zap.String("denom", coin.Denom), zap.Error(err))
+			continue
+		}
+		resolver.AddDenomTrace(*trace)
+	}
+	return resolver.Humanize(coins)
+}
@@ -0,0 +1,214 @@
+package client
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/KyleMoser/cosmos-client/client/rpc"
+	abci "github.com/cometbft/cometbft/abci/types"
+	ctypes "github.com/cometbft/cometbft/rpc/core/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// DecodedTx is a transaction decoded into proto JSON, together with its execution result.
+type DecodedTx struct {
+	Hash     string    `json:"hash"`
+	Height   int64     `json:"height"`
+	Index    uint32    `json:"index"`
+	Signers  []string  `json:"signers"`
+	Messages []string  `json:"messages"`
+	Fee      sdk.Coins `json:"fee"`
+	Memo     string    `json:"memo,omitempty"`
+	// Tx is the proto JSON representation of the transaction, or nil if it could not be decoded.
+	Tx          interface{}  `json:"tx"`
+	DecodeError string       `json:"decode_error,omitempty"`
+	Code        uint32       `json:"code"`
+	Codespace   string       `json:"codespace,omitempty"`
+	Log         string       `json:"log,omitempty"`
+	GasWanted   int64        `json:"gas_wanted"`
+	GasUsed     int64        `json:"gas_used"`
+	Events      []abci.Event `json:"events"`
+}
+
+// DecodedBlock is a block whose transactions have been decoded and matched with their results.
+type DecodedBlock struct {
+	ChainID             string       `json:"chain_id"`
+	Height              int64        `json:"height"`
+	Hash                string       `json:"hash"`
+	Time                time.Time    `json:"time"`
+	Proposer            string       `json:"proposer"`
+	NumTxs              int          `json:"num_txs"`
+	Txs                 []DecodedTx  `json:"txs"`
+	FinalizeBlockEvents []abci.Event `json:"finalize_block_events"`
+}
+
+// QueryDecodedBlock fetches the block and block results at the given height (or the latest block
+// if height is zero) and decodes every transaction in the block.
+func (cc *ChainClient) QueryDecodedBlock(ctx context.Context, height int64) (*DecodedBlock, error) {
+	var heightPtr *int64
+	if height > 0 {
+		heightPtr = &height
+	}
+
+	block, err := cc.RPCClient.Block(ctx, heightPtr)
+	if err != nil {
+		return nil, err
+	}
+	results, err := cc.RPCClient.BlockResults(ctx, &block.Block.Height)
+	if err != nil {
+		return nil, err
+	}
+	return cc.decodeBlock(block, results)
+}
+
+// QueryDecodedBlocks fetches and decodes the blocks at the given heights, in the order of the heights.
+// If the RPC client supports batch requests, the blocks and their results are fetched in batches of
+// Config.BatchSize calls instead of one request per call.
+func (cc *ChainClient) QueryDecodedBlocks(ctx context.Context, heights []int64) ([]*DecodedBlock, error) {
+	batch, ok := cc.NewRPCBatch()
+	if !ok {
+		blocks := make([]*DecodedBlock, len(heights))
+		for i, height := range heights {
+			block, err := cc.QueryDecodedBlock(ctx, height)
+			if err != nil {
+				return nil, fmt.Errorf("block %d: %w", height, err)
+			}
+			blocks[i] = block
+		}
+		return blocks, nil
+	}
+
+	blockCalls := make([]*rpc.BatchCall[*ctypes.ResultBlock], len(heights))
+	resultCalls := make([]*rpc.BatchCall[*ctypes.ResultBlockResults], len(heights))
+	for i := range heights {
+		blockCalls[i] = batch.Block(&heights[i])
+		resultCalls[i] = batch.BlockResults(&heights[i])
+	}
+	if err := batch.Send(ctx); err != nil {
+		return nil, err
+	}
+
+	blocks := make([]*DecodedBlock, len(heights))
+	for i, height := range heights {
+		if err := errors.Join(blockCalls[i].Err, resultCalls[i].Err); err != nil {
+			return nil, fmt.Errorf("block %d: %w", height, err)
+		}
+		block, err := cc.decodeBlock(blockCalls[i].Result, resultCalls[i].Result)
+		if err != nil {
+			return nil, err
+		}
+		blocks[i] = block
+	}
+	return blocks, nil
+}
+
+// NewRPCBatch returns a batch of calls to the RPC client, sent in batch requests of up to Config.BatchSize
+// calls. It returns false if the RPC client does not support batch requests.
+func (cc *ChainClient) NewRPCBatch() (*rpc.Batch, bool) {
+	caller, ok := cc.RPCClient.(rpc.BatchCaller)
+	if !ok {
+		return nil, false
+	}
+	return rpc.NewBatch(caller, cc.Config.BatchSize), true
+}
+
+func (cc *ChainClient) decodeBlock(block *ctypes.ResultBlock, results *ctypes.ResultBlockResults) (*DecodedBlock, error) {
+	if len(results.TxsResults) != len(block.Block.Txs) {
+		return nil, fmt.Errorf("block %d has %d txs but %d results", block.Block.Height, len(block.Block.Txs), len(results.TxsResults))
+	}
+
+	out := &DecodedBlock{
+		ChainID:             block.Block.ChainID,
+		Height:              block.Block.Height,
+		Hash:                block.BlockID.Hash.String(),
+		Time:                block.Block.Time,
+		Proposer:            block.Block.ProposerAddress.String(),
+		NumTxs:              len(block.Block.Txs),
+		Txs:                 make([]DecodedTx, len(block.Block.Txs)),
+		FinalizeBlockEvents: results.FinalizeBlockEvents,
+	}
+	for i, txBz := range block.Block.Txs {
+		out.Txs[i] = cc.decodeTxWithResult(txBz, block.Block.Height, uint32(i), results.TxsResults[i])
+	}
+	return out, nil
+}
+
+// QueryDecodedTx fetches the tx with the given hex encoded hash and decodes it.
+func (cc *ChainClient) QueryDecodedTx(ctx context.Context, hashHex string) (*DecodedTx, error) {
+	if _, err := hex.DecodeString(hashHex); err != nil {
+		return nil, fmt.Errorf("invalid tx hash %q: %w", hashHex, err)
+	}
+
+	res, err := cc.QueryTx(ctx, hashHex, false)
+	if err != nil {
+		return nil, err
+	}
+	decoded := cc.DecodeResultTx(res)
+	return &decoded, nil
+}
+
+// DecodeResultTx decodes a tx returned by the Tx or TxSearch RPC endpoints.
+func (cc *ChainClient) DecodeResultTx(res *ctypes.ResultTx) DecodedTx {
+	return cc.decodeTxWithResult(res.Tx, res.Height, res.Index, &res.TxResult)
+}
+
+func (cc *ChainClient) decodeTxWithResult(txBz []byte, height int64, index uint32, result *abci.ExecTxResult) DecodedTx {
+	summary := cc.SummarizeTx(txBz)
+	out := DecodedTx{
+		Hash:        summary.Hash,
+		Height:      height,
+		Index:       index,
+		Signers:     summary.Signers,
+		Messages:    summary.Messages,
+		Fee:         summary.Fee,
+		Memo:        summary.Memo,
+		DecodeError: summary.Error,
+	}
+	if result != nil {
+		out.Code = result.Code
+		out.Codespace = result.Codespace
+		out.Log = result.Log
+		out.GasWanted = result.GasWanted
+		out.GasUsed = result.GasUsed
+		out.Events = result.Events
+	}
+
+	if summary.Error == "" {
+		txJSON, err := cc.TxToJSON(txBz)
+		if err != nil {
+			out.DecodeError = err.Error()
+		} else {
+			out.Tx = txJSON
+		}
+	}
+	return out
+}
+
+// TxToJSON decodes the raw transaction bytes and returns the generic JSON representation
+// of the proto transaction, as produced by the chain's codec.
+func (cc *ChainClient) TxToJSON(txBz []byte) (interface{}, error) {
+	decoded, err := cc.DecodeTx(txBz)
+	if err != nil {
+		return nil, err
+	}
+	protoProvider, ok := decoded.(protoTxProvider)
+	if !ok {
+		return nil, fmt.Errorf("cannot convert %T to a proto transaction", decoded)
+	}
+
+	bz, err := cc.Codec.Marshaler.MarshalJSON(protoProvider.GetProtoTx())
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal into a generic value so the tx renders naturally in both JSON and YAML output.
+	var out interface{}
+	if err := json.Unmarshal(bz, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
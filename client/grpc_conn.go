@@ -0,0 +1,172 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/KyleMoser/cosmos-client/client/transport"
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/codec/types"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// QueryRoute selects the transport of state queries.
+type QueryRoute string
+
+const (
+	// QueryRouteRPC runs queries as ABCI queries over CometBFT RPC. It is the default.
+	QueryRouteRPC QueryRoute = "rpc"
+	// QueryRouteGRPC runs queries over the native gRPC connection only.
+	QueryRouteGRPC QueryRoute = "grpc"
+	// QueryRoutePreferGRPC runs queries over the native gRPC connection, falling back to ABCI queries
+	// over RPC when the gRPC endpoint is unavailable or does not implement the method.
+	QueryRoutePreferGRPC QueryRoute = "prefer-grpc"
+)
+
+// ParseQueryRoute validates the name of a query route. An empty name is QueryRouteRPC.
+func ParseQueryRoute(s string) (QueryRoute, error) {
+	switch r := QueryRoute(s); r {
+	case "":
+		return QueryRouteRPC, nil
+	case QueryRouteRPC, QueryRouteGRPC, QueryRoutePreferGRPC:
+		return r, nil
+	default:
+		return "", fmt.Errorf("unknown query route %q, expected %q, %q or %q", s, QueryRouteRPC, QueryRouteGRPC, QueryRoutePreferGRPC)
+	}
+}
+
+// QueryRouteCallOption overrides the query route of the client for a single call.
+type QueryRouteCallOption struct {
+	grpc.EmptyCallOption
+	Route QueryRoute
+}
+
+// WithQueryRoute returns a call option that overrides the query route of the client for a single call.
+func WithQueryRoute(route QueryRoute) grpc.CallOption {
+	return QueryRouteCallOption{Route: route}
+}
+
+type queryRouteKey struct{}
+
+// SetQueryRouteOnContext overrides the query route of the client for every call made with the context.
+func SetQueryRouteOnContext(ctx context.Context, route QueryRoute) context.Context {
+	return context.WithValue(ctx, queryRouteKey{}, route)
+}
+
+// queryRoute returns the route of a call: the call option takes precedence over the context,
+// which takes precedence over the client config.
+func (cc *ChainClient) queryRoute(ctx context.Context, opts []grpc.CallOption) QueryRoute {
+	for _, opt := range opts {
+		if o, ok := opt.(QueryRouteCallOption); ok {
+			return o.Route
+		}
+	}
+	if route, ok := ctx.Value(queryRouteKey{}).(QueryRoute); ok {
+		return route
+	}
+	route, _ := ParseQueryRoute(cc.Config.QueryRoute) // The route is validated in the config so no error check
+	return route
+}
+
+// queryEndpoint returns the address queries are sent to, which is the gRPC address unless the route is rpc.
+func (cc *ChainClient) queryEndpoint(ctx context.Context, opts []grpc.CallOption) string {
+	if cc.GRPCConn != nil && cc.queryRoute(ctx, opts) != QueryRouteRPC {
+		return cc.Config.GRPCAddr
+	}
+	return cc.Config.RPCAddr
+}
+
+// invokeGRPC runs the query over the native gRPC connection.
+func (cc *ChainClient) invokeGRPC(ctx context.Context, method string, req, reply interface{}, opts ...grpc.CallOption) error {
+	if cc.GRPCConn == nil {
+		return status.Error(codes.Unavailable, "no gRPC address is configured")
+	}
+	return cc.GRPCConn.Invoke(ctx, method, req, reply, opts...)
+}
+
+// shouldFallbackToRPC reports whether a failed gRPC query may succeed as an ABCI query over RPC.
+func shouldFallbackToRPC(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.Unimplemented, codes.ResourceExhausted, codes.DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}
+
+// dialGRPC returns a gRPC connection to the address, which is established lazily on the first call.
+// Addresses with an https or grpcs scheme, or without a scheme on port 443, use TLS. Other addresses,
+// e.g. the host:9090 endpoints common in the chain registry, use plaintext. The transport config sets
+// the metadata of every call, the proxy and the TLS config.
+func dialGRPC(log *zap.Logger, addr string, transportConfig *transport.Config, interfaceRegistry types.InterfaceRegistry) (*grpc.ClientConn, error) {
+	target, useTLS, err := parseGRPCAddr(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	creds := insecure.NewCredentials()
+	if useTLS {
+		tlsConfig, err := transportConfig.TLSConfig()
+		if err != nil {
+			return nil, err
+		}
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		creds = credentials.NewTLS(tlsConfig)
+	}
+	opts, err := transportConfig.GRPCDialOptions()
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(codec.NewProtoCodec(interfaceRegistry).GRPCCodec())),
+	)
+	log.Debug("Dialing gRPC endpoint", zap.String("target", target), zap.Bool("tls", useTLS))
+	return grpc.Dial(target, opts...)
+}
+
+// parseGRPCAddr returns the host:port target of the gRPC address and whether it uses TLS.
+func parseGRPCAddr(addr string) (target string, useTLS bool, err error) {
+	if !strings.Contains(addr, "://") {
+		_, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return "", false, fmt.Errorf("invalid gRPC address %s: %w", addr, err)
+		}
+		return addr, port == "443", nil
+	}
+
+	u, err := url.Parse(addr)
+	if err != nil {
+		return "", false, fmt.Errorf("invalid gRPC address %s: %w", addr, err)
+	}
+	switch u.Scheme {
+	case "https", "grpcs":
+		useTLS = true
+	case "http", "grpc", "tcp":
+	default:
+		return "", false, fmt.Errorf("invalid gRPC address %s: unsupported scheme %s", addr, u.Scheme)
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if useTLS {
+			port = "443"
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port), useTLS, nil
+}
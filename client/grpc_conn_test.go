@@ -0,0 +1,55 @@
+package client_test
+
+import (
+	"testing"
+
+	"github.com/KyleMoser/cosmos-client/client"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestQueryRouteConfig(t *testing.T) {
+	for name, tc := range map[string]struct {
+		route    string
+		grpcAddr string
+		err      bool
+	}{
+		"default":                     {route: "", grpcAddr: ""},
+		"rpc":                         {route: "rpc", grpcAddr: ""},
+		"grpc":                        {route: "grpc", grpcAddr: "grpc.osmosis.zone:9090"},
+		"prefer grpc":                 {route: "prefer-grpc", grpcAddr: "https://grpc.osmosis.zone"},
+		"grpc without address":        {route: "grpc", grpcAddr: "", err: true},
+		"prefer grpc without address": {route: "prefer-grpc", grpcAddr: "", err: true},
+		"unknown route":               {route: "rest", grpcAddr: "grpc.osmosis.zone:9090", err: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			conf := client.GetOsmosisConfig("/keys", false)
+			conf.QueryRoute = tc.route
+			conf.GRPCAddr = tc.grpcAddr
+			err := conf.Validate()
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestGRPCConnWithRPCRoute(t *testing.T) {
+	homepath := t.TempDir()
+	conf := client.GetOsmosisConfig(homepath, false)
+	conf.QueryRoute = "rpc"
+	conf.GRPCAddr = "grpc.osmosis.zone:9090"
+
+	// Calls can select the gRPC route even if the configured route is rpc.
+	cl, err := client.NewChainClient(zaptest.NewLogger(t), conf, homepath, nil, nil)
+	require.NoError(t, err)
+	require.NotNil(t, cl.GRPCConn)
+	require.NoError(t, cl.GRPCConn.Close())
+
+	conf.GRPCAddr = ""
+	cl, err = client.NewChainClient(zaptest.NewLogger(t), conf, homepath, nil, nil)
+	require.NoError(t, err)
+	require.Nil(t, cl.GRPCConn)
+}
@@ -2,12 +2,13 @@ package client
 
 import (
 	"context"
-	"fmt"
 	"reflect"
 	"strconv"
 
+	"github.com/KyleMoser/cosmos-client/client/telemetry"
 	abci "github.com/cometbft/cometbft/abci/types"
 	gogogrpc "github.com/cosmos/gogoproto/grpc"
+	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/encoding"
@@ -27,6 +28,9 @@ var protoCodec = encoding.GetCodec(proto.Name)
 
 // Invoke implements the grpc ClientConn.Invoke method
 func (cc *ChainClient) Invoke(ctx context.Context, method string, req, reply interface{}, opts ...grpc.CallOption) (err error) {
+	ctx, done := cc.instrument(ctx, telemetry.OperationInvoke, method, cc.queryEndpoint(ctx, opts))
+	defer func() { done(err) }()
+
 	// Two things can happen here:
 	// 1. either we're broadcasting a Tx, in which call we call Tendermint's broadcast endpoint directly,
 	// 2. or we are querying for state, in which case we call ABCI's Querier.
@@ -54,8 +58,28 @@ func (cc *ChainClient) Invoke(ctx context.Context, method string, req, reply int
 		return err
 	}
 
-	// Case 2. Querying state.
+	// Case 2. Querying state, over the native gRPC connection if the query route selects it.
 	inMd, _ := metadata.FromOutgoingContext(ctx)
+	if prove, _ := GetProveFromMetadata(inMd); !prove {
+		// Proofs are only returned by ABCI queries, so proven queries always use RPC.
+		switch route := cc.queryRoute(ctx, opts); route {
+		case QueryRouteGRPC:
+			if err := cc.invokeGRPC(ctx, method, req, reply, opts...); err != nil {
+				return err
+			}
+			return cc.unpackReply(reply)
+		case QueryRoutePreferGRPC:
+			err := cc.invokeGRPC(ctx, method, req, reply, opts...)
+			if err == nil {
+				return cc.unpackReply(reply)
+			}
+			if !shouldFallbackToRPC(ctx, err) {
+				return err
+			}
+			cc.log.Debug("Falling back to RPC for query", zap.String("method", method), zap.Error(err))
+		}
+	}
+
 	abciRes, outMd, err := cc.RunGRPCQuery(ctx, method, req, inMd)
 	if err != nil {
 		return err
@@ -74,6 +98,11 @@ func (cc *ChainClient) Invoke(ctx context.Context, method string, req, reply int
 		*header.HeaderAddr = outMd
 	}
 
+	return cc.unpackReply(reply)
+}
+
+// unpackReply unpacks the Any fields of a query reply so that their cached values can be used.
+func (cc *ChainClient) unpackReply(reply interface{}) error {
 	if cc.Codec.InterfaceRegistry != nil {
 		return types.UnpackInterfaces(reply, cc.Codec.Marshaler)
 	}
@@ -81,15 +110,10 @@ func (cc *ChainClient) Invoke(ctx context.Context, method string, req, reply int
 	return nil
 }
 
-// NewStream implements the grpc ClientConn.NewStream method
-func (cc *ChainClient) NewStream(context.Context, *grpc.StreamDesc, string, ...grpc.CallOption) (grpc.ClientStream, error) {
-	return nil, fmt.Errorf("streaming rpc not supported")
-}
-
 // RunGRPCQuery runs a gRPC query from the clientCtx, given all necessary
 // arguments for the gRPC method, and returns the ABCI response. It is used
 // to factorize code between client (Invoke) and server (RegisterGRPCServer)
-// gRPC handlers.
+// gRPC handlers. Responses are served from the QueryCache if one is set.
 func (cc *ChainClient) RunGRPCQuery(ctx context.Context, method string, req interface{}, md metadata.MD) (abci.ResponseQuery, metadata.MD, error) {
 	reqBz, err := protoCodec.Marshal(req)
 	if err != nil {
@@ -125,9 +149,18 @@ func (cc *ChainClient) RunGRPCQuery(ctx context.Context, method string, req inte
 		Prove:  prove,
 	}
 
-	abciRes, err := cc.QueryABCI(ctx, abciReq)
-	if err != nil {
-		return abci.ResponseQuery{}, nil, err
+	abciRes, cached := cc.QueryCache.Get(abciReq)
+	if cc.QueryCache.cacheable(abciReq) {
+		cc.Metrics.ObserveCacheLookup(cc.Config.ChainID, method, cached)
+	}
+	if !cached {
+		abciRes, err = cc.QueryABCI(ctx, abciReq)
+		if err != nil {
+			return abci.ResponseQuery{}, nil, err
+		}
+		if err := cc.QueryCache.Set(abciReq, abciRes); err != nil {
+			cc.log.Warn("Failed to cache query response", zap.String("method", method), zap.Error(err))
+		}
 	}
 
 	// Create header metadata. For now the headers contain:
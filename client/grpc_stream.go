@@ -0,0 +1,143 @@
+package client
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"strconv"
+
+	grpctypes "github.com/cosmos/cosmos-sdk/types/grpc"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	gogoproto "github.com/cosmos/gogoproto/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// NewStream implements the grpc ClientConn.NewStream method. Streams use the native gRPC connection
+// unless the query route is rpc. Over RPC, server streaming is emulated for unary queries: the request
+// is sent as an ABCI query and every page of a paginated response is received as a separate message,
+// all queried at the height of the first page. Client streaming is not supported over RPC.
+func (cc *ChainClient) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	if cc.GRPCConn != nil && cc.queryRoute(ctx, opts) != QueryRouteRPC {
+		return cc.GRPCConn.NewStream(ctx, desc, method, opts...)
+	}
+	if desc.ClientStreams {
+		return nil, status.Errorf(codes.Unimplemented, "client streaming rpc %s is not supported over RPC", method)
+	}
+	return &abciStream{cc: cc, ctx: ctx, method: method, opts: opts}, nil
+}
+
+// abciStream is a server stream emulated over ABCI queries, receiving one message per page of the response.
+type abciStream struct {
+	cc     *ChainClient
+	ctx    context.Context
+	method string
+	opts   []grpc.CallOption
+
+	req    gogoproto.Message
+	header metadata.MD
+	done   bool
+}
+
+var _ grpc.ClientStream = &abciStream{}
+
+func (s *abciStream) Header() (metadata.MD, error) { return s.header, nil }
+
+func (s *abciStream) Trailer() metadata.MD { return nil }
+
+func (s *abciStream) CloseSend() error { return nil }
+
+func (s *abciStream) Context() context.Context { return s.ctx }
+
+// SendMsg sets the request of the stream. The request is copied, as the pagination is advanced on every page.
+func (s *abciStream) SendMsg(m interface{}) error {
+	if s.req != nil {
+		return status.Error(codes.Unimplemented, "client streaming is not supported over RPC")
+	}
+	msg, ok := m.(gogoproto.Message)
+	if !ok {
+		return status.Errorf(codes.InvalidArgument, "request %T is not a proto message", m)
+	}
+	s.req = gogoproto.Clone(msg)
+	return nil
+}
+
+// RecvMsg queries the next page of the response. It returns io.EOF after the last page.
+func (s *abciStream) RecvMsg(m interface{}) error {
+	if s.done {
+		return io.EOF
+	}
+	if s.req == nil {
+		return status.Error(codes.Internal, "no request was sent on the stream")
+	}
+
+	var header metadata.MD
+	opts := append(s.opts[:len(s.opts):len(s.opts)], grpc.Header(&header), WithQueryRoute(QueryRouteRPC))
+	if err := s.cc.Invoke(s.ctx, s.method, s.req, m, opts...); err != nil {
+		s.done = true
+		return err
+	}
+	if s.header == nil {
+		s.header = header
+		s.ctx = pinQueryHeight(s.ctx, header)
+	}
+
+	nextKey := nextPageKey(m)
+	if len(nextKey) == 0 || !setPageKey(s.req, nextKey) {
+		s.done = true
+	}
+	return nil
+}
+
+// pinQueryHeight sets the height of the context to the height of the response header if it is unset,
+// so that every page of a stream is queried at the same height.
+func pinQueryHeight(ctx context.Context, header metadata.MD) context.Context {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	if height, err := GetHeightFromMetadata(md); err != nil || height > 0 {
+		return ctx
+	}
+	heights := header.Get(grpctypes.GRPCBlockHeightHeader)
+	if len(heights) == 0 {
+		return ctx
+	}
+	if height, err := strconv.ParseInt(heights[0], 10, 64); err != nil || height <= 0 {
+		return ctx
+	}
+	md = md.Copy()
+	md.Set(grpctypes.GRPCBlockHeightHeader, heights[0])
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// nextPageKey returns the next key of a paginated response, or nil if the response is not paginated.
+func nextPageKey(res interface{}) []byte {
+	if p, ok := res.(interface{ GetPagination() *query.PageResponse }); ok {
+		return p.GetPagination().GetNextKey()
+	}
+	return nil
+}
+
+// setPageKey sets the key of the pagination of a request, clearing its offset. It reports false if
+// the request is not paginated.
+func setPageKey(req interface{}, key []byte) bool {
+	v := reflect.ValueOf(req)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return false
+	}
+	field := v.Elem().FieldByName("Pagination")
+	if !field.IsValid() || field.Type() != reflect.TypeOf(&query.PageRequest{}) {
+		return false
+	}
+
+	page := &query.PageRequest{}
+	if current := field.Interface().(*query.PageRequest); current != nil {
+		*page = *current
+	}
+	page.Key = key
+	page.Offset = 0
+	// The total is only counted for the first page.
+	page.CountTotal = false
+	field.Set(reflect.ValueOf(page))
+	return true
+}
@@ -0,0 +1,78 @@
+package client_test
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/KyleMoser/cosmos-client/client"
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/libs/bytes"
+	rpcclient "github.com/cometbft/cometbft/rpc/client"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// balancesRPC serves paginated bank balance queries of one coin per page.
+type balancesRPC struct {
+	rpcclient.Client
+	coins   sdk.Coins
+	heights []int64
+}
+
+func (r *balancesRPC) ABCIQueryWithOptions(_ context.Context, path string, data bytes.HexBytes, opts rpcclient.ABCIQueryOptions) (*coretypes.ResultABCIQuery, error) {
+	r.heights = append(r.heights, opts.Height)
+
+	var req banktypes.QueryAllBalancesRequest
+	if err := req.Unmarshal(data); err != nil {
+		return nil, err
+	}
+	i := 0
+	if len(req.Pagination.GetKey()) > 0 {
+		i = int(req.Pagination.Key[0])
+	}
+	res := banktypes.QueryAllBalancesResponse{Balances: r.coins[i : i+1], Pagination: &query.PageResponse{}}
+	if i+1 < len(r.coins) {
+		res.Pagination.NextKey = []byte{byte(i + 1)}
+	}
+	bz, err := res.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return &coretypes.ResultABCIQuery{Response: abci.ResponseQuery{Value: bz, Height: 100}}, nil
+}
+
+func TestABCIStream(t *testing.T) {
+	rpc := &balancesRPC{coins: sdk.NewCoins(sdk.NewInt64Coin("uatom", 1), sdk.NewInt64Coin("uosmo", 2), sdk.NewInt64Coin("ustake", 3))}
+	cc := &client.ChainClient{Config: &client.ChainClientConfig{}, RPCClient: rpc}
+
+	desc := &grpc.StreamDesc{StreamName: "AllBalances", ServerStreams: true}
+	stream, err := cc.NewStream(context.Background(), desc, "/cosmos.bank.v1beta1.Query/AllBalances")
+	require.NoError(t, err)
+	req := &banktypes.QueryAllBalancesRequest{Address: "cosmos1", Pagination: &query.PageRequest{Limit: 1, CountTotal: true}}
+	require.NoError(t, stream.SendMsg(req))
+	require.NoError(t, stream.CloseSend())
+
+	var received sdk.Coins
+	for {
+		var res banktypes.QueryAllBalancesResponse
+		err := stream.RecvMsg(&res)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		received = append(received, res.Balances...)
+	}
+	require.Equal(t, rpc.coins, received)
+	// Later pages are queried at the height of the first page.
+	require.Equal(t, []int64{0, 100, 100}, rpc.heights)
+	// The request of the caller is not modified.
+	require.Empty(t, req.Pagination.Key)
+
+	_, err = cc.NewStream(context.Background(), &grpc.StreamDesc{ClientStreams: true}, "/cosmos.bank.v1beta1.Query/AllBalances")
+	require.Error(t, err)
+}
@@ -0,0 +1,269 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultHealthInterval is the interval between health checks of a HealthMonitor.
+	DefaultHealthInterval = 10 * time.Second
+	// DefaultStaleAfter is the age of the latest block after which a node is considered stale.
+	DefaultStaleAfter = time.Minute
+	// DefaultMaxHeightLag is the number of blocks a node may be behind the highest node of the same chain.
+	DefaultMaxHeightLag = 5
+)
+
+// HealthStatus is the result of the latest health check of a ChainClient's RPC node.
+type HealthStatus struct {
+	ChainID     string    `json:"chain_id"`
+	RPCAddr     string    `json:"rpc_addr"`
+	Healthy     bool      `json:"healthy"`
+	CatchingUp  bool      `json:"catching_up"`
+	Height      int64     `json:"height"`
+	BlockTime   time.Time `json:"block_time"`
+	LastChecked time.Time `json:"last_checked"`
+	LastHealthy time.Time `json:"last_healthy,omitempty"`
+	// HeightLag is the number of blocks the node is behind the highest node of the same chain.
+	HeightLag int64 `json:"height_lag"`
+	// Reason explains why the node is unhealthy.
+	Reason string `json:"reason,omitempty"`
+}
+
+// rpcLiveness is the health of the RPC node of a ChainClient, updated by a HealthMonitor.
+type rpcLiveness struct {
+	mu          sync.RWMutex
+	isActive    bool
+	lastChecked time.Time
+	lastActive  time.Time
+}
+
+// IsActive reports whether the RPC node passed its latest health check.
+// It is always false unless the client is monitored by a HealthMonitor.
+func (cc *ChainClient) IsActive() bool {
+	cc.rpcLiveness.mu.RLock()
+	defer cc.rpcLiveness.mu.RUnlock()
+	return cc.rpcLiveness.isActive
+}
+
+func (cc *ChainClient) setLiveness(status HealthStatus) {
+	cc.rpcLiveness.mu.Lock()
+	defer cc.rpcLiveness.mu.Unlock()
+	cc.rpcLiveness.isActive = status.Healthy
+	cc.rpcLiveness.lastChecked = status.LastChecked
+	if status.Healthy {
+		cc.rpcLiveness.lastActive = status.LastChecked
+	}
+}
+
+// HealthMonitor periodically checks the RPC nodes of ChainClients. A node is healthy if it responds, is not
+// catching up, has produced a block recently and is not too far behind the other nodes of the same chain.
+type HealthMonitor struct {
+	clients        []*ChainClient
+	interval       time.Duration
+	staleAfter     time.Duration
+	maxHeightLag   int64
+	onStatusChange func(cc *ChainClient, status HealthStatus)
+
+	mu       sync.RWMutex
+	statuses map[*ChainClient]HealthStatus
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// NewHealthMonitor returns a HealthMonitor of the ChainClients with the default interval and thresholds.
+func NewHealthMonitor(chainClients ...*ChainClient) *HealthMonitor {
+	return &HealthMonitor{
+		clients:      chainClients,
+		interval:     DefaultHealthInterval,
+		staleAfter:   DefaultStaleAfter,
+		maxHeightLag: DefaultMaxHeightLag,
+		statuses:     map[*ChainClient]HealthStatus{},
+	}
+}
+
+// WithInterval sets the interval between health checks.
+func (m *HealthMonitor) WithInterval(interval time.Duration) *HealthMonitor {
+	m.interval = interval
+	return m
+}
+
+// WithStaleAfter sets the age of the latest block after which a node is unhealthy.
+func (m *HealthMonitor) WithStaleAfter(staleAfter time.Duration) *HealthMonitor {
+	m.staleAfter = staleAfter
+	return m
+}
+
+// WithMaxHeightLag sets the number of blocks a node may be behind the highest node of the same chain.
+func (m *HealthMonitor) WithMaxHeightLag(maxHeightLag int64) *HealthMonitor {
+	m.maxHeightLag = maxHeightLag
+	return m
+}
+
+// OnStatusChange sets a callback that is called after the first check of every client and whenever
+// a client becomes healthy or unhealthy. It is called from the monitor's goroutine.
+func (m *HealthMonitor) OnStatusChange(fn func(cc *ChainClient, status HealthStatus)) *HealthMonitor {
+	m.onStatusChange = fn
+	return m
+}
+
+// Start checks the clients immediately and then at every interval in a goroutine, until the context
+// is done or Stop is called.
+func (m *HealthMonitor) Start(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.cancel != nil {
+		return errors.New("health monitor already started")
+	}
+	ctx, m.cancel = context.WithCancel(ctx)
+	m.done = make(chan struct{})
+
+	go func() {
+		defer close(m.done)
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+		for {
+			m.Check(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop stops the health checks and waits for the running check to finish. The monitor can be started again.
+func (m *HealthMonitor) Stop() {
+	m.mu.Lock()
+	cancel, done := m.cancel, m.done
+	m.cancel, m.done = nil, nil
+	m.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// Status returns the latest health status of the client, if it was checked.
+func (m *HealthMonitor) Status(cc *ChainClient) (HealthStatus, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	status, ok := m.statuses[cc]
+	return status, ok
+}
+
+// Statuses returns the latest health status of every checked client, in the order of the clients.
+func (m *HealthMonitor) Statuses() []HealthStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	statuses := make([]HealthStatus, 0, len(m.clients))
+	for _, cc := range m.clients {
+		if status, ok := m.statuses[cc]; ok {
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses
+}
+
+// Check checks every client once, updating their statuses.
+func (m *HealthMonitor) Check(ctx context.Context) {
+	// Every check must finish before the next one starts.
+	ctx, cancel := context.WithTimeout(ctx, m.interval)
+	defer cancel()
+
+	statuses := make([]HealthStatus, len(m.clients))
+	var wg sync.WaitGroup
+	for i, cc := range m.clients {
+		wg.Add(1)
+		go func(i int, cc *ChainClient) {
+			defer wg.Done()
+			statuses[i] = m.checkNode(ctx, cc)
+		}(i, cc)
+	}
+	wg.Wait()
+	if ctx.Err() != nil && errors.Is(context.Cause(ctx), context.Canceled) {
+		// The monitor was stopped, so the failures say nothing about the nodes.
+		return
+	}
+
+	highest := map[string]int64{}
+	for _, status := range statuses {
+		if status.Height > highest[status.ChainID] {
+			highest[status.ChainID] = status.Height
+		}
+	}
+
+	type change struct {
+		cc     *ChainClient
+		status HealthStatus
+	}
+	var changes []change
+
+	m.mu.Lock()
+	for i, cc := range m.clients {
+		status := statuses[i]
+		if status.Height > 0 {
+			status.HeightLag = highest[status.ChainID] - status.Height
+			if status.Healthy && status.HeightLag > m.maxHeightLag {
+				status.Healthy = false
+				status.Reason = "behind other nodes of the chain"
+			}
+		}
+
+		prev, checked := m.statuses[cc]
+		status.LastHealthy = prev.LastHealthy
+		if status.Healthy {
+			status.LastHealthy = status.LastChecked
+		}
+		m.statuses[cc] = status
+		cc.setLiveness(status)
+		if !checked || prev.Healthy != status.Healthy {
+			changes = append(changes, change{cc: cc, status: status})
+		}
+	}
+	m.mu.Unlock()
+
+	if m.onStatusChange != nil {
+		for _, c := range changes {
+			m.onStatusChange(c.cc, c.status)
+		}
+	}
+}
+
+// checkNode checks the status of the client's RPC node, except for its height lag.
+func (m *HealthMonitor) checkNode(ctx context.Context, cc *ChainClient) HealthStatus {
+	status := HealthStatus{ChainID: cc.Config.ChainID, RPCAddr: cc.Config.RPCAddr, LastChecked: time.Now()}
+	res, err := cc.RPCClient.Status(ctx)
+	if err != nil {
+		status.Reason = err.Error()
+		return status
+	}
+
+	status.Height = res.SyncInfo.LatestBlockHeight
+	status.BlockTime = res.SyncInfo.LatestBlockTime
+	status.CatchingUp = res.SyncInfo.CatchingUp
+	switch {
+	case status.CatchingUp:
+		status.Reason = "catching up"
+	case m.staleAfter > 0 && status.LastChecked.Sub(status.BlockTime) > m.staleAfter:
+		status.Reason = "no recent blocks"
+	default:
+		status.Healthy = true
+	}
+	return status
+}
+
+// HealthChecks starts a HealthMonitor of the given ChainClients with the default interval and thresholds.
+// If a ChainClient's RPC node is healthy, chainClient.IsActive() will return true.
+//
+// Deprecated: use NewHealthMonitor, which can be configured and stopped.
+func HealthChecks(chainClients ...*ChainClient) *HealthMonitor {
+	m := NewHealthMonitor(chainClients...)
+	_ = m.Start(context.Background()) // A new monitor cannot already be started.
+	return m
+}
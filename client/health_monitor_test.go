@@ -0,0 +1,86 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/KyleMoser/cosmos-client/client"
+	rpcclient "github.com/cometbft/cometbft/rpc/client"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// statusRPC answers status calls with a fixed sync info, or an error.
+type statusRPC struct {
+	rpcclient.Client
+	mu   sync.Mutex
+	info coretypes.SyncInfo
+	err  error
+}
+
+func (r *statusRPC) Status(context.Context) (*coretypes.ResultStatus, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return nil, r.err
+	}
+	return &coretypes.ResultStatus{SyncInfo: r.info}, nil
+}
+
+func (r *statusRPC) setErr(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.err = err
+}
+
+func TestHealthMonitor(t *testing.T) {
+	now := time.Now()
+	nodes := map[string]*statusRPC{
+		"healthy":     {info: coretypes.SyncInfo{LatestBlockHeight: 100, LatestBlockTime: now}},
+		"lagging":     {info: coretypes.SyncInfo{LatestBlockHeight: 90, LatestBlockTime: now}},
+		"catching up": {info: coretypes.SyncInfo{LatestBlockHeight: 100, LatestBlockTime: now, CatchingUp: true}},
+		"stale":       {info: coretypes.SyncInfo{LatestBlockHeight: 100, LatestBlockTime: now.Add(-time.Hour)}},
+		"down":        {err: errors.New("connection refused")},
+	}
+	clients := map[string]*client.ChainClient{}
+	var all []*client.ChainClient
+	for name, rpc := range nodes {
+		cc := &client.ChainClient{Config: &client.ChainClientConfig{ChainID: "osmosis-1", RPCAddr: name}, RPCClient: rpc}
+		clients[name] = cc
+		all = append(all, cc)
+	}
+
+	var mu sync.Mutex
+	changes := map[string][]bool{}
+	m := client.NewHealthMonitor(all...).
+		WithInterval(10 * time.Millisecond).
+		OnStatusChange(func(cc *client.ChainClient, status client.HealthStatus) {
+			mu.Lock()
+			defer mu.Unlock()
+			changes[status.RPCAddr] = append(changes[status.RPCAddr], status.Healthy)
+		})
+
+	m.Check(context.Background())
+	for name, cc := range clients {
+		status, ok := m.Status(cc)
+		require.True(t, ok)
+		require.Equal(t, name == "healthy", status.Healthy, name)
+		require.Equal(t, name == "healthy", cc.IsActive(), name)
+	}
+	lagging, _ := m.Status(clients["lagging"])
+	require.Equal(t, int64(10), lagging.HeightLag)
+
+	require.NoError(t, m.Start(context.Background()))
+	require.Error(t, m.Start(context.Background()))
+	nodes["healthy"].setErr(errors.New("connection refused"))
+	require.Eventually(t, func() bool { return !clients["healthy"].IsActive() }, time.Second, 10*time.Millisecond)
+	m.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []bool{true, false}, changes["healthy"])
+	require.Equal(t, []bool{false}, changes["down"])
+}
@@ -0,0 +1,180 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	registry "github.com/KyleMoser/cosmos-client/client/chain_registry"
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	connectiontypes "github.com/cosmos/ibc-go/v8/modules/core/03-connection/types"
+	channeltypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/cosmos/ibc-go/v8/modules/core/exported"
+	tmclient "github.com/cosmos/ibc-go/v8/modules/light-clients/07-tendermint"
+)
+
+// IbcPathCheck is the result of checking one end of an IBC path against the live state of its chain.
+type IbcPathCheck struct {
+	Chain  string `json:"chain" yaml:"chain"`
+	Check  string `json:"check" yaml:"check"`
+	OK     bool   `json:"ok" yaml:"ok"`
+	Detail string `json:"detail,omitempty" yaml:"detail,omitempty"`
+}
+
+// IbcPathValidation is the result of validating an IBC path from the registry against both chains.
+type IbcPathValidation struct {
+	Config  registry.IbcConfig             `json:"config" yaml:"config"`
+	Channel registry.IbcConfigChannelOuter `json:"channel" yaml:"channel"`
+	Checks  []IbcPathCheck                 `json:"checks" yaml:"checks"`
+	Valid   bool                           `json:"valid" yaml:"valid"`
+}
+
+// Err returns an error describing every failed check, or nil if the path is valid.
+func (v IbcPathValidation) Err() error {
+	var failed []string
+	for _, c := range v.Checks {
+		if !c.OK {
+			failed = append(failed, fmt.Sprintf("%s %s: %s", c.Chain, c.Check, c.Detail))
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid IBC path %s/%s between %s and %s: %s",
+		v.Channel.Chain1.PortId, v.Channel.Chain1.ChannelId, v.Config.Chain1.ChainName, v.Config.Chain2.ChainName,
+		strings.Join(failed, "; "))
+}
+
+// ValidateIbcPath validates the preferred transfer channel between this chain and the counterparty
+// against the live state of both chains, e.g. before sending funds over it.
+func (cc *ChainClient) ValidateIbcPath(ctx context.Context, counterparty *ChainClient) (*IbcPathValidation, error) {
+	conf, err := cc.GetIbcConfig(counterparty.Config.ChainName)
+	if err != nil {
+		return nil, err
+	}
+	channel, err := conf.PreferredChannel(transfertypes.PortID)
+	if err != nil {
+		return nil, err
+	}
+	return cc.ValidateIbcChannel(ctx, counterparty, conf, channel), nil
+}
+
+// ValidateIbcChannel checks on both chains that the channel and its connection are open and refer to each other,
+// and that the light clients are active and track the counterparty chain. Chain1 of the config must be this chain.
+func (cc *ChainClient) ValidateIbcChannel(
+	ctx context.Context,
+	counterparty *ChainClient,
+	conf registry.IbcConfig,
+	channel registry.IbcConfigChannelOuter,
+) *IbcPathValidation {
+	v := &IbcPathValidation{Config: conf, Channel: channel}
+	v.Checks = append(v.Checks, cc.checkIbcEnd(ctx, conf.Chain1, channel.Chain1, conf.Chain2, channel.Chain2, counterparty.Config.ChainID)...)
+	v.Checks = append(v.Checks, counterparty.checkIbcEnd(ctx, conf.Chain2, channel.Chain2, conf.Chain1, channel.Chain1, cc.Config.ChainID)...)
+
+	v.Valid = true
+	for _, c := range v.Checks {
+		v.Valid = v.Valid && c.OK
+	}
+	return v
+}
+
+// checkIbcEnd checks the channel, connection and client of this end of an IBC path.
+func (cc *ChainClient) checkIbcEnd(
+	ctx context.Context,
+	self registry.IbcConfigChain,
+	selfChannel registry.IbcConfigChannel,
+	other registry.IbcConfigChain,
+	otherChannel registry.IbcConfigChannel,
+	otherChainID string,
+) []IbcPathCheck {
+	check := func(name string, err error) IbcPathCheck {
+		c := IbcPathCheck{Chain: self.ChainName, Check: name, OK: err == nil}
+		if err != nil {
+			c.Detail = err.Error()
+		}
+		return c
+	}
+
+	return []IbcPathCheck{
+		check("channel", cc.checkChannel(ctx, self, selfChannel, otherChannel)),
+		check("connection", cc.checkConnection(ctx, self, other)),
+		check("client", cc.checkClient(ctx, self.ClientId, otherChainID)),
+	}
+}
+
+func (cc *ChainClient) checkChannel(
+	ctx context.Context,
+	self registry.IbcConfigChain,
+	selfChannel, otherChannel registry.IbcConfigChannel,
+) error {
+	res, err := channeltypes.NewQueryClient(cc).Channel(ctx, &channeltypes.QueryChannelRequest{
+		PortId:    selfChannel.PortId,
+		ChannelId: selfChannel.ChannelId,
+	})
+	if err != nil {
+		return err
+	}
+
+	ch := res.Channel
+	switch {
+	case ch.State != channeltypes.OPEN:
+		return fmt.Errorf("channel %s is %s", selfChannel.ChannelId, ch.State)
+	case ch.Counterparty.PortId != otherChannel.PortId || ch.Counterparty.ChannelId != otherChannel.ChannelId:
+		return fmt.Errorf("counterparty is %s/%s, expected %s/%s",
+			ch.Counterparty.PortId, ch.Counterparty.ChannelId, otherChannel.PortId, otherChannel.ChannelId)
+	case len(ch.ConnectionHops) == 0 || ch.ConnectionHops[0] != self.ConnectionId:
+		return fmt.Errorf("connection hops are %v, expected %s", ch.ConnectionHops, self.ConnectionId)
+	}
+	return nil
+}
+
+func (cc *ChainClient) checkConnection(ctx context.Context, self, other registry.IbcConfigChain) error {
+	res, err := connectiontypes.NewQueryClient(cc).Connection(ctx, &connectiontypes.QueryConnectionRequest{
+		ConnectionId: self.ConnectionId,
+	})
+	if err != nil {
+		return err
+	}
+
+	conn := res.Connection
+	switch {
+	case conn.State != connectiontypes.OPEN:
+		return fmt.Errorf("connection %s is %s", self.ConnectionId, conn.State)
+	case conn.ClientId != self.ClientId:
+		return fmt.Errorf("client is %s, expected %s", conn.ClientId, self.ClientId)
+	case conn.Counterparty.ConnectionId != other.ConnectionId || conn.Counterparty.ClientId != other.ClientId:
+		return fmt.Errorf("counterparty is %s (client %s), expected %s (client %s)",
+			conn.Counterparty.ConnectionId, conn.Counterparty.ClientId, other.ConnectionId, other.ClientId)
+	}
+	return nil
+}
+
+func (cc *ChainClient) checkClient(ctx context.Context, clientID, otherChainID string) error {
+	queryClient := clienttypes.NewQueryClient(cc)
+	status, err := queryClient.ClientStatus(ctx, &clienttypes.QueryClientStatusRequest{ClientId: clientID})
+	if err != nil {
+		return err
+	}
+	if status.Status != exported.Active.String() {
+		return fmt.Errorf("client %s is %s", clientID, status.Status)
+	}
+
+	res, err := queryClient.ClientState(ctx, &clienttypes.QueryClientStateRequest{ClientId: clientID})
+	if err != nil {
+		return err
+	}
+	var clientState exported.ClientState
+	if err := cc.Codec.InterfaceRegistry.UnpackAny(res.ClientState, &clientState); err != nil {
+		return err
+	}
+	tmClientState, ok := clientState.(*tmclient.ClientState)
+	if !ok {
+		// Only tendermint clients record the chain ID they track.
+		return nil
+	}
+	if otherChainID != "" && tmClientState.ChainId != otherChainID {
+		return fmt.Errorf("client %s tracks chain %s, expected %s", clientID, tmClientState.ChainId, otherChainID)
+	}
+	return nil
+}
@@ -2,7 +2,9 @@ package client
 
 import (
 	"errors"
+	"fmt"
 	"os"
+	"strings"
 
 	ckeys "github.com/cosmos/cosmos-sdk/client/keys"
 	"github.com/cosmos/cosmos-sdk/crypto/hd"
@@ -11,18 +13,16 @@ import (
 )
 
 var (
-	// SupportedAlgorithms defines the list of signing algorithms used on Evmos:
-	//  - secp256k1     (Cosmos)
-	//  - eth_secp256k1 (Ethereum)
+	// SupportedAlgorithms defines the list of signing algorithms keys can be created with:
+	//  - secp256k1 (Cosmos)
+	// Chains whose keys use other algorithms, such as ethsecp256k1 on Evmos, are not supported.
 	SupportedAlgorithms = keyring.SigningAlgoList{hd.Secp256k1}
-	// SupportedAlgorithmsLedger defines the list of signing algorithms used on Evmos for the Ledger device:
-	//  - secp256k1     (Cosmos)
-	//  - eth_secp256k1 (Ethereum)
+	// SupportedAlgorithmsLedger defines the list of signing algorithms used for the Ledger device:
+	//  - secp256k1 (Cosmos)
 	SupportedAlgorithmsLedger = keyring.SigningAlgoList{hd.Secp256k1}
 )
 
-// Option defines a function keys options for the ethereum Secp256k1 curve.
-// It supports secp256k1 and eth_secp256k1 keys for accounts.
+// LensKeyringAlgoOptions restricts the keyring to the SupportedAlgorithms.
 func LensKeyringAlgoOptions() keyring.Option {
 	return func(options *keyring.Options) {
 		options.SupportedAlgos = SupportedAlgorithms
@@ -123,8 +123,10 @@ func (cc *ChainClient) ExportPrivKeyArmor(keyName string) (armor string, err err
 
 func (cc *ChainClient) KeyAddOrRestore(keyName string, coinType uint32, mnemonic ...string) (*KeyOutput, error) {
 	var mnemonicStr string
-	var err error
-	algo := keyring.SignatureAlgo(hd.Secp256k1)
+	algo, err := cc.keyAlgo()
+	if err != nil {
+		return nil, err
+	}
 
 	if len(mnemonic) > 0 {
 		mnemonicStr = mnemonic[0]
@@ -152,6 +154,41 @@ func (cc *ChainClient) KeyAddOrRestore(keyName string, coinType uint32, mnemonic
 	return &KeyOutput{Mnemonic: mnemonicStr, Address: out}, nil
 }
 
+// keyAlgo returns the first of the chain's key algorithms that is supported, defaulting to secp256k1.
+func (cc *ChainClient) keyAlgo() (keyring.SignatureAlgo, error) {
+	if len(cc.Config.KeyAlgos) == 0 {
+		return hd.Secp256k1, nil
+	}
+	for _, name := range cc.Config.KeyAlgos {
+		if algo, err := keyring.NewSigningAlgoFromString(name, SupportedAlgorithms); err == nil {
+			return algo, nil
+		}
+	}
+	return nil, ValidateKeyAlgos(cc.Config.ChainID, cc.Config.KeyAlgos)
+}
+
+// ValidateKeyAlgos returns an error naming the key algorithms of the chain if none of them is supported.
+// Chains which list no key algorithms use secp256k1.
+func ValidateKeyAlgos(chainID string, algos []string) error {
+	if len(algos) == 0 {
+		return nil
+	}
+	for _, name := range algos {
+		if _, err := keyring.NewSigningAlgoFromString(name, SupportedAlgorithms); err == nil {
+			return nil
+		}
+	}
+
+	supported := make([]string, len(SupportedAlgorithms))
+	for i, algo := range SupportedAlgorithms {
+		supported[i] = string(algo.Name())
+	}
+	if len(algos) == 1 {
+		return fmt.Errorf("key algorithm %s of chain %s is not supported, supported key algorithms are: %s", algos[0], chainID, strings.Join(supported, ", "))
+	}
+	return fmt.Errorf("key algorithms %s of chain %s are not supported, supported key algorithms are: %s", strings.Join(algos, ", "), chainID, strings.Join(supported, ", "))
+}
+
 // KeyOutput contains mnemonic and address of key
 type KeyOutput struct {
 	Mnemonic string `json:"mnemonic" yaml:"mnemonic"`
@@ -115,3 +115,40 @@ func TestKeyRestoreInj(t *testing.T) {
 		t.Fatalf("Error deleting key: %v", err)
 	}
 }
+
+// TestKeyRestoreUnsupportedAlgo rejects chains whose key algorithms are not supported
+func TestKeyRestoreUnsupportedAlgo(t *testing.T) {
+	mnemonic := "three elevator silk family street child flip also leaf inmate call frame shock little legal october vivid enable fetch siege sell burger dolphin green"
+
+	homepath := t.TempDir()
+	config := &client.ChainClientConfig{
+		Key:            "default",
+		ChainID:        "evmos_9001-2",
+		AccountPrefix:  "evmos",
+		KeyringBackend: "test",
+		GasAdjustment:  1.2,
+		GasPrices:      "0.01uevmos",
+		Timeout:        "20s",
+		OutputFormat:   "json",
+		SignModeStr:    "direct",
+		KeyAlgos:       []string{"ethsecp256k1"},
+	}
+	expected := "key algorithm ethsecp256k1 of chain evmos_9001-2 is not supported, supported key algorithms are: secp256k1"
+	if err := config.Validate(); err == nil || err.Error() != expected {
+		t.Fatalf("Expected config error %q, got: %v", expected, err)
+	}
+
+	cl, err := client.NewChainClient(zaptest.NewLogger(t), config, homepath, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cl.RestoreKey("test_key", mnemonic, 60); err == nil || err.Error() != expected {
+		t.Fatalf("Expected key error %q, got: %v", expected, err)
+	}
+
+	// Chains listing a supported algorithm next to an unsupported one use the supported algorithm.
+	config.KeyAlgos = []string{"ethsecp256k1", "secp256k1"}
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,193 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"cosmossdk.io/store/rootmulti"
+	"github.com/KyleMoser/cosmos-client/client/rpc"
+	dbm "github.com/cometbft/cometbft-db"
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/crypto/merkle"
+	"github.com/cometbft/cometbft/light"
+	provtypes "github.com/cometbft/cometbft/light/provider"
+	prov "github.com/cometbft/cometbft/light/provider/http"
+	lightdb "github.com/cometbft/cometbft/light/store/db"
+	"github.com/cometbft/cometbft/types"
+	"go.uber.org/zap"
+)
+
+// DefaultTrustPeriod is the trust period of the light client if none is configured. It must be shorter than
+// the unbonding period of the chain, which is three weeks on most chains.
+const DefaultTrustPeriod = "168h"
+
+// ErrVerification is returned when a verified query cannot be verified against the light client.
+// The query result is never returned in that case.
+var ErrVerification = errors.New("query verification failed")
+
+// lightVerifier lazily creates the light client of a ChainClient, as creating it fetches the trusted header.
+type lightVerifier struct {
+	mu     sync.Mutex
+	client *light.Client
+}
+
+// validateVerifyConfig validates the trust options of the light client when verification is enabled.
+func (ccc *ChainClientConfig) validateVerifyConfig() error {
+	if !ccc.Verify {
+		return nil
+	}
+	if _, err := ccc.trustOptions(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// trustOptions returns the trust options of the light client from the config.
+func (ccc *ChainClientConfig) trustOptions() (light.TrustOptions, error) {
+	period := ccc.TrustPeriod
+	if period == "" {
+		period = DefaultTrustPeriod
+	}
+	trustPeriod, err := time.ParseDuration(period)
+	if err != nil {
+		return light.TrustOptions{}, fmt.Errorf("invalid trust-period %s: %w", period, err)
+	}
+	hash, err := hex.DecodeString(ccc.TrustedHash)
+	if err != nil {
+		return light.TrustOptions{}, fmt.Errorf("invalid trusted-hash %s: %w", ccc.TrustedHash, err)
+	}
+	opts := light.TrustOptions{Period: trustPeriod, Height: ccc.TrustedHeight, Hash: hash}
+	if err := opts.ValidateBasic(); err != nil {
+		return light.TrustOptions{}, fmt.Errorf("verify requires a trusted-height and trusted-hash: %w", err)
+	}
+	return opts, nil
+}
+
+// LightClient returns the light client used to verify queries, creating it from the trust options of the
+// config on first use. The RPC address is the primary and the witness addresses cross-check it. Without
+// witness addresses, the primary is its own witness, which detects no forks.
+func (cc *ChainClient) LightClient(ctx context.Context) (*light.Client, error) {
+	cc.verifier.mu.Lock()
+	defer cc.verifier.mu.Unlock()
+	if cc.verifier.client != nil {
+		return cc.verifier.client, nil
+	}
+
+	trustOptions, err := cc.Config.trustOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	witnessAddrs := cc.Config.WitnessAddrs
+	if len(witnessAddrs) == 0 {
+		cc.log.Warn("No witnesses configured, the light client cannot detect forks", zap.String("chain_id", cc.Config.ChainID))
+		witnessAddrs = []string{cc.Config.RPCAddr}
+	}
+	witnesses := make([]provtypes.Provider, 0, len(witnessAddrs))
+	for _, addr := range witnessAddrs {
+		witness, err := cc.newLightProvider(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid witness %s: %w", addr, err)
+		}
+		witnesses = append(witnesses, witness)
+	}
+
+	lc, err := light.NewClient(
+		ctx,
+		cc.Config.ChainID,
+		trustOptions,
+		cc.LightProvider,
+		witnesses,
+		lightdb.New(dbm.NewMemDB(), cc.Config.ChainID),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create light client: %w", err)
+	}
+	cc.verifier.client = lc
+	return lc, nil
+}
+
+// newLightProvider returns a light client provider for the RPC address, which sends its requests with the
+// transport, rate limit and retry policy of the client.
+func (cc *ChainClient) newLightProvider(addr string) (provtypes.Provider, error) {
+	timeout, _ := time.ParseDuration(cc.Config.Timeout)
+	client, err := rpc.NewRPCClient(addr, timeout, cc.rpcOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return prov.NewWithClient(cc.Config.ChainID, client), nil
+}
+
+// QueryStore queries the value of a key in a module store at the given height, or the latest height if it is 0.
+// Store queries are verified against the light client when verification is enabled.
+func (cc *ChainClient) QueryStore(ctx context.Context, storeName string, key []byte, height int64) (abci.ResponseQuery, error) {
+	return cc.QueryABCI(ctx, abci.RequestQuery{
+		Path:   fmt.Sprintf("/store/%s/key", storeName),
+		Data:   key,
+		Height: height,
+		Prove:  cc.Config.Verify,
+	})
+}
+
+// verifyQuery verifies the ICS23 proof of a store query against the app hash of the light client. The app hash
+// committing to the state at height h is in the header at h+1, which is awaited if it is not produced yet.
+// The response must be for the key of the request, and for its height unless the latest height was requested,
+// as a proof is only valid for the key and height it was made for.
+func (cc *ChainClient) verifyQuery(ctx context.Context, req abci.RequestQuery, res abci.ResponseQuery) error {
+	if !bytes.Equal(res.Key, req.Data) {
+		return fmt.Errorf("%w: response to %s is for key %X instead of %X", ErrVerification, req.Path, res.Key, req.Data)
+	}
+	if req.Height != 0 && res.Height != req.Height {
+		return fmt.Errorf("%w: response to %s is for height %d instead of %d", ErrVerification, req.Path, res.Height, req.Height)
+	}
+	if res.ProofOps == nil || len(res.ProofOps.Ops) == 0 {
+		return fmt.Errorf("%w: no proof returned for %s", ErrVerification, req.Path)
+	}
+
+	lc, err := cc.LightClient(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrVerification, err)
+	}
+	block, err := verifyLightBlockAtHeight(ctx, lc, res.Height+1)
+	if err != nil {
+		return fmt.Errorf("%w: header at height %d: %w", ErrVerification, res.Height+1, err)
+	}
+
+	// isQueryStoreWithProof ensures the path is /store/<storeName>/<subpath>.
+	storeName := strings.SplitN(strings.TrimPrefix(req.Path, "/"), "/", 3)[1]
+	keyPath := merkle.KeyPath{}.
+		AppendKey([]byte(storeName), merkle.KeyEncodingURL).
+		AppendKey(res.Key, merkle.KeyEncodingURL)
+
+	prt := rootmulti.DefaultProofRuntime()
+	if res.Value == nil {
+		err = prt.VerifyAbsence(res.ProofOps, block.AppHash, keyPath.String())
+	} else {
+		err = prt.VerifyValue(res.ProofOps, block.AppHash, keyPath.String(), res.Value)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: proof of %s at height %d does not match the app hash: %w", ErrVerification, req.Path, res.Height, err)
+	}
+	return nil
+}
+
+// verifyLightBlockAtHeight verifies the light block at the height, waiting for it to be produced if needed.
+func verifyLightBlockAtHeight(ctx context.Context, lc *light.Client, height int64) (*types.LightBlock, error) {
+	for {
+		block, err := lc.VerifyLightBlockAtHeight(ctx, height, time.Now())
+		if err == nil || !(errors.Is(err, provtypes.ErrHeightTooHigh) || errors.Is(err, provtypes.ErrLightBlockNotFound)) {
+			return block, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
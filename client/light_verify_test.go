@@ -0,0 +1,131 @@
+package client_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/KyleMoser/cosmos-client/client"
+	"github.com/KyleMoser/cosmos-client/client/transport"
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/libs/bytes"
+	rpcclient "github.com/cometbft/cometbft/rpc/client"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestVerifyConfig(t *testing.T) {
+	trustedHash := strings.Repeat("ab", 32)
+	for name, tc := range map[string]struct {
+		trustPeriod   string
+		trustedHeight int64
+		trustedHash   string
+		err           bool
+	}{
+		"default trust period": {trustedHeight: 100, trustedHash: trustedHash},
+		"trust period":         {trustPeriod: "336h", trustedHeight: 100, trustedHash: trustedHash},
+		"invalid trust period": {trustPeriod: "2 weeks", trustedHeight: 100, trustedHash: trustedHash, err: true},
+		"no trusted height":    {trustedHash: trustedHash, err: true},
+		"no trusted hash":      {trustedHeight: 100, err: true},
+		"short trusted hash":   {trustedHeight: 100, trustedHash: "abcd", err: true},
+		"invalid trusted hash": {trustedHeight: 100, trustedHash: strings.Repeat("zz", 32), err: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			conf := client.GetOsmosisConfig("/keys", false)
+			conf.Verify = true
+			conf.TrustPeriod = tc.trustPeriod
+			conf.TrustedHeight = tc.trustedHeight
+			conf.TrustedHash = tc.trustedHash
+			err := conf.Validate()
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+// unprovenRPC answers every ABCI query without a proof, for the key and height if they are set.
+type unprovenRPC struct {
+	rpcclient.Client
+	prove  bool
+	key    []byte
+	height int64
+}
+
+func (r *unprovenRPC) ABCIQueryWithOptions(_ context.Context, _ string, data bytes.HexBytes, opts rpcclient.ABCIQueryOptions) (*coretypes.ResultABCIQuery, error) {
+	r.prove = opts.Prove
+	res := abci.ResponseQuery{Key: data, Value: []byte("value"), Height: 10}
+	if r.key != nil {
+		res.Key = r.key
+	}
+	if r.height != 0 {
+		res.Height = r.height
+	}
+	return &coretypes.ResultABCIQuery{Response: res}, nil
+}
+
+func TestVerifiedQueryFailsClosed(t *testing.T) {
+	rpc := &unprovenRPC{}
+	conf := client.GetOsmosisConfig("/keys", false)
+	conf.Verify = true
+	cc := &client.ChainClient{Config: conf, RPCClient: rpc}
+
+	_, err := cc.QueryStore(context.Background(), "bank", []byte("key"), 0)
+	require.ErrorIs(t, err, client.ErrVerification)
+	require.True(t, rpc.prove)
+
+	// Queries that are not store queries cannot be proven, so they are not verified.
+	_, err = cc.QueryABCI(context.Background(), abci.RequestQuery{Path: "/cosmos.bank.v1beta1.Query/Balance"})
+	require.NoError(t, err)
+	require.False(t, rpc.prove)
+}
+
+func TestVerifiedQueryMismatch(t *testing.T) {
+	for name, tc := range map[string]struct {
+		rpc    *unprovenRPC
+		height int64
+		err    string
+	}{
+		"key":    {rpc: &unprovenRPC{key: []byte("other")}, err: "is for key"},
+		"height": {rpc: &unprovenRPC{height: 11}, height: 10, err: "is for height 11 instead of 10"},
+		// The latest height is requested, so the proof is checked for the height of the response.
+		"latest height": {rpc: &unprovenRPC{height: 11}, err: "no proof returned"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			conf := client.GetOsmosisConfig("/keys", false)
+			conf.Verify = true
+			cc := &client.ChainClient{Config: conf, RPCClient: tc.rpc}
+
+			_, err := cc.QueryStore(context.Background(), "bank", []byte("key"), tc.height)
+			require.ErrorIs(t, err, client.ErrVerification)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestLightProviderTransport(t *testing.T) {
+	var apiKey atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiKey.Store(r.Header.Get("X-Api-Key"))
+		_, _ = io.WriteString(w, `{"jsonrpc":"2.0","id":-1,"error":{"code":-32603,"message":"Internal error"}}`)
+	}))
+	defer server.Close()
+
+	homepath := t.TempDir()
+	conf := client.GetOsmosisConfig(homepath, false)
+	conf.RPCAddr = server.URL
+	conf.Transport = &transport.Config{Headers: map[string]string{"X-Api-Key": "secret"}}
+	cc, err := client.NewChainClient(zaptest.NewLogger(t), conf, homepath, nil, nil)
+	require.NoError(t, err)
+
+	// The light client provider sends the headers of the transport config like every other request.
+	_, _ = cc.LightProvider.LightBlock(context.Background(), 1)
+	require.Equal(t, "secret", apiKey.Load())
+}
@@ -0,0 +1,167 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tmtypes "github.com/cometbft/cometbft/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+)
+
+// TxSummary is a concise description of a decoded transaction: who signed it,
+// what it does and what it pays.
+type TxSummary struct {
+	Hash      string    `json:"hash"`
+	Signers   []string  `json:"signers"`
+	Sequences []uint64  `json:"sequences"`
+	Messages  []string  `json:"messages"`
+	Fee       sdk.Coins `json:"fee"`
+	Gas       uint64    `json:"gas"`
+	Memo      string    `json:"memo,omitempty"`
+	// Error is set when the transaction could not be decoded with this chain's codec.
+	Error string `json:"error,omitempty"`
+}
+
+// MempoolFilter restricts the transactions returned by QueryUnconfirmedTxs.
+// Empty fields match every transaction.
+type MempoolFilter struct {
+	// Signer is a bech32 account address that must be one of the tx signers.
+	Signer string
+	// MsgType matches a message type URL (e.g. /cosmos.bank.v1beta1.MsgSend) or its suffix (e.g. MsgSend).
+	MsgType string
+}
+
+// Matches reports whether the summarized tx satisfies the filter.
+func (f MempoolFilter) Matches(s TxSummary) bool {
+	if f.Signer != "" && !containsString(s.Signers, f.Signer) {
+		return false
+	}
+	if f.MsgType != "" {
+		for _, m := range s.Messages {
+			if m == f.MsgType || strings.HasSuffix(m, "."+f.MsgType) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// MempoolTxs is the result of an unconfirmed transactions query.
+type MempoolTxs struct {
+	Count      int         `json:"n_txs"`
+	Total      int         `json:"total"`
+	TotalBytes int64       `json:"total_bytes"`
+	Txs        []TxSummary `json:"txs"`
+}
+
+// QueryNumUnconfirmedTxs returns the number and total size of the transactions in the node's mempool.
+func (cc *ChainClient) QueryNumUnconfirmedTxs(ctx context.Context) (*MempoolTxs, error) {
+	res, err := cc.RPCClient.NumUnconfirmedTxs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &MempoolTxs{Count: res.Count, Total: res.Total, TotalBytes: res.TotalBytes}, nil
+}
+
+// maxUnconfirmedTxs is the largest number of transactions a node returns from its mempool.
+const maxUnconfirmedTxs = 100
+
+// QueryUnconfirmedTxs returns summaries of up to limit transactions in the node's mempool
+// that match the given filter. A limit of zero uses the node's default. The node does not
+// page its mempool, so a filter searches the first maxUnconfirmedTxs transactions, and the
+// limit applies to the transactions that match.
+func (cc *ChainClient) QueryUnconfirmedTxs(ctx context.Context, limit int, filter MempoolFilter) (*MempoolTxs, error) {
+	var limitPtr *int
+	if filter != (MempoolFilter{}) {
+		fetch := maxUnconfirmedTxs
+		limitPtr = &fetch
+	} else if limit > 0 {
+		limitPtr = &limit
+	}
+
+	res, err := cc.RPCClient.UnconfirmedTxs(ctx, limitPtr)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &MempoolTxs{Total: res.Total, TotalBytes: res.TotalBytes, Txs: []TxSummary{}}
+	for _, txBz := range res.Txs {
+		if limit > 0 && len(out.Txs) == limit {
+			break
+		}
+		summary := cc.SummarizeTx(txBz)
+		if filter.Matches(summary) {
+			out.Txs = append(out.Txs, summary)
+		}
+	}
+	out.Count = len(out.Txs)
+	return out, nil
+}
+
+// DecodeTx decodes raw transaction bytes using this chain's TxConfig.
+func (cc *ChainClient) DecodeTx(txBz []byte) (authsigning.Tx, error) {
+	decoded, err := cc.Codec.TxConfig.TxDecoder()(txBz)
+	if err != nil {
+		return nil, err
+	}
+	sigTx, ok := decoded.(authsigning.Tx)
+	if !ok {
+		return nil, fmt.Errorf("unexpected transaction type %T", decoded)
+	}
+	return sigTx, nil
+}
+
+// SummarizeTx decodes the raw transaction bytes and summarizes the signers, messages and fees.
+// If the tx cannot be decoded, the returned summary only contains the hash and the error.
+func (cc *ChainClient) SummarizeTx(txBz []byte) TxSummary {
+	summary := TxSummary{Hash: fmt.Sprintf("%X", tmtypes.Tx(txBz).Hash())}
+
+	sigTx, err := cc.DecodeTx(txBz)
+	if err != nil {
+		summary.Error = err.Error()
+		return summary
+	}
+
+	signers, err := sigTx.GetSigners()
+	if err != nil {
+		summary.Error = err.Error()
+		return summary
+	}
+	for _, signer := range signers {
+		addr, err := cc.EncodeBech32AccAddr(signer)
+		if err != nil {
+			summary.Error = err.Error()
+			return summary
+		}
+		summary.Signers = append(summary.Signers, addr)
+	}
+
+	sigs, err := sigTx.GetSignaturesV2()
+	if err != nil {
+		summary.Error = err.Error()
+		return summary
+	}
+	for _, sig := range sigs {
+		summary.Sequences = append(summary.Sequences, sig.Sequence)
+	}
+
+	for _, msg := range sigTx.GetMsgs() {
+		summary.Messages = append(summary.Messages, sdk.MsgTypeURL(msg))
+	}
+	summary.Fee = sigTx.GetFee()
+	summary.Gas = sigTx.GetGas()
+	summary.Memo = sigTx.GetMemo()
+	return summary
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
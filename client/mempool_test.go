@@ -0,0 +1,85 @@
+package client_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/KyleMoser/cosmos-client/client"
+	rpcclient "github.com/cometbft/cometbft/rpc/client"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	tmtypes "github.com/cometbft/cometbft/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
+	"github.com/cosmos/cosmos-sdk/x/bank"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stretchr/testify/require"
+)
+
+// mempoolRPC serves a mempool of txs, returning 30 of them by default and at most 100 like CometBFT.
+type mempoolRPC struct {
+	rpcclient.Client
+	txs []tmtypes.Tx
+}
+
+func (r *mempoolRPC) UnconfirmedTxs(_ context.Context, limit *int) (*coretypes.ResultUnconfirmedTxs, error) {
+	n := 30
+	if limit != nil && *limit > 0 {
+		n = *limit
+	}
+	if n > 100 {
+		n = 100
+	}
+	if n > len(r.txs) {
+		n = len(r.txs)
+	}
+	return &coretypes.ResultUnconfirmedTxs{Count: n, Total: len(r.txs), Txs: r.txs[:n]}, nil
+}
+
+func TestQueryUnconfirmedTxs(t *testing.T) {
+	cc := &client.ChainClient{
+		Config: &client.ChainClientConfig{AccountPrefix: "cosmos"},
+		Codec:  client.MakeCodec([]module.AppModuleBasic{bank.AppModuleBasic{}}, nil, "cosmos", "cosmosvaloper"),
+	}
+	alice := sdk.AccAddress("alice_______________")
+	bob := sdk.AccAddress("bob_________________")
+
+	// Bob's txs sit after the first 20 txs of the mempool.
+	rpc := &mempoolRPC{}
+	for i := 0; i < 25; i++ {
+		from := alice
+		if i >= 20 {
+			from = bob
+		}
+		builder := cc.Codec.TxConfig.NewTxBuilder()
+		require.NoError(t, builder.SetMsgs(banktypes.NewMsgSend(from, alice, sdk.NewCoins(sdk.NewInt64Coin("uatom", int64(i+1))))))
+		bz, err := cc.Codec.TxConfig.TxEncoder()(builder.GetTx())
+		require.NoError(t, err)
+		rpc.txs = append(rpc.txs, bz)
+	}
+	cc.RPCClient = rpc
+
+	tests := map[string]struct {
+		limit    int
+		filter   client.MempoolFilter
+		expected int
+	}{
+		"no filter":             {expected: 25},
+		"limit":                 {limit: 10, expected: 10},
+		"signer":                {filter: client.MempoolFilter{Signer: cc.MustEncodeAccAddr(bob)}, expected: 5},
+		"signer past the limit": {limit: 3, filter: client.MempoolFilter{Signer: cc.MustEncodeAccAddr(bob)}, expected: 3},
+		"msg type":              {limit: 10, filter: client.MempoolFilter{MsgType: "MsgSend"}, expected: 10},
+		"no match":              {limit: 10, filter: client.MempoolFilter{MsgType: "MsgDelegate"}, expected: 0},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			res, err := cc.QueryUnconfirmedTxs(context.Background(), tc.limit, tc.filter)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, res.Count)
+			require.Len(t, res.Txs, tc.expected)
+			for _, tx := range res.Txs {
+				require.Empty(t, tx.Error)
+				require.True(t, tc.filter.Matches(tx))
+			}
+		})
+	}
+}
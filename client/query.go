@@ -5,7 +5,6 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
-	"strings"
 
 	ctypes "github.com/cometbft/cometbft/rpc/core/types"
 
@@ -183,7 +182,8 @@ func (cc *ChainClient) QueryTx(ctx context.Context, hashHex string, prove bool)
 	return cc.RPCClient.Tx(ctx, hash, prove)
 }
 
-// QueryTxs returns an array of transactions related to the specified event search criteria.
+// QueryTxs returns a single page of transactions related to the specified event search criteria.
+// Use SearchTxs or QueryAllTxs to iterate over every page.
 func (cc *ChainClient) QueryTxs(ctx context.Context, page, limit int, events []string) ([]*ctypes.ResultTx, error) {
 	if len(events) == 0 {
 		return nil, errors.New("must declare at least one event to search")
@@ -197,7 +197,12 @@ func (cc *ChainClient) QueryTxs(ctx context.Context, page, limit int, events []s
 		return nil, errors.New("limit must greater than 0")
 	}
 
-	res, err := cc.RPCClient.TxSearch(ctx, strings.Join(events, " AND "), true, &page, &limit, "")
+	query, err := NewTxQuery().Raw(events...).Build()
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := cc.RPCClient.TxSearch(ctx, query, true, &page, &limit, "")
 	if err != nil {
 		return nil, err
 	}
@@ -2,16 +2,20 @@ package query
 
 import (
 	"context"
+	"strconv"
+	"time"
+
+	"github.com/KyleMoser/cosmos-client/client"
 	grpctypes "github.com/cosmos/cosmos-sdk/types/grpc"
 	"github.com/cosmos/cosmos-sdk/types/query"
 	"google.golang.org/grpc/metadata"
-	"strconv"
-	"time"
 )
 
 type QueryOptions struct {
 	Pagination *query.PageRequest
 	Height     int64
+	// Route overrides the query route of the client config if set.
+	Route client.QueryRoute
 }
 
 func DefaultOptions() *QueryOptions {
@@ -32,5 +36,8 @@ func (q *Query) GetQueryContext() (context.Context, context.CancelFunc) {
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	strHeight := strconv.Itoa(int(q.Options.Height))
 	ctx = metadata.AppendToOutgoingContext(ctx, grpctypes.GRPCBlockHeightHeader, strHeight)
+	if q.Options.Route != "" {
+		ctx = client.SetQueryRouteOnContext(ctx, q.Options.Route)
+	}
 	return ctx, cancel
 }
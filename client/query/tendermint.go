@@ -1,11 +1,10 @@
 package query
 
 import (
-	"context"
 	"encoding/hex"
 	"errors"
-	"strings"
 
+	"github.com/KyleMoser/cosmos-client/client"
 	rpcclient "github.com/cometbft/cometbft/rpc/client"
 	coretypes "github.com/cometbft/cometbft/rpc/core/types"
 )
@@ -121,7 +120,19 @@ func TxsRPC(q *Query, events []string) (*coretypes.ResultTxSearch, error) {
 	page := int(q.Options.Pagination.Offset/q.Options.Pagination.Limit) + 1 // page is 1-indexed, not 0-indexed
 	limit := int(q.Options.Pagination.Limit)
 
-	res, err := q.Client.RPCClient.TxSearch(context.Background(), strings.Join(events, " AND "), true, &page, &limit, "")
+	query, err := client.NewTxQuery().Raw(events...).Build()
+	if err != nil {
+		return nil, err
+	}
+
+	orderBy := client.TxOrderAsc
+	if q.Options.Pagination.Reverse {
+		orderBy = client.TxOrderDesc
+	}
+
+	ctx, cancel := q.GetQueryContext()
+	defer cancel()
+	res, err := q.Client.RPCClient.TxSearch(ctx, query, true, &page, &limit, string(orderBy))
 	if err != nil {
 		return nil, err
 	}
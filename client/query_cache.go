@@ -0,0 +1,234 @@
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// DefaultQueryCacheSize is the number of query responses a QueryCache keeps in memory.
+const DefaultQueryCacheSize = 10000
+
+// uncachedLatestQueryPaths are queries of state that changes between blocks without the height changing for
+// the client, such as the account sequences of pending transactions, so their latest responses are never cached.
+var uncachedLatestQueryPaths = map[string]bool{
+	"/cosmos.auth.v1beta1.Query/Account":     true,
+	"/cosmos.auth.v1beta1.Query/AccountInfo": true,
+	"/cosmos.tx.v1beta1.Service/Simulate":    true,
+	"/app/simulate":                          true,
+}
+
+// QueryCacheConfig configures the cache of query responses of a chain client.
+type QueryCacheConfig struct {
+	// Size is the number of responses kept in memory, DefaultQueryCacheSize if unset.
+	Size int `json:"size,omitempty" yaml:"size,omitempty"`
+	// Dir stores the responses of queries at a height on disk instead of in memory, so that they are
+	// kept across runs. Every chain client stores them in a subdirectory named after its chain ID.
+	Dir string `json:"dir,omitempty" yaml:"dir,omitempty"`
+	// LatestTTL caches the responses of queries at the latest height for the duration, e.g. "2s".
+	// They are not cached if it is unset.
+	LatestTTL string `json:"latest-ttl,omitempty" yaml:"latest-ttl,omitempty"`
+}
+
+// Validate checks the size and TTL of the cache.
+func (c *QueryCacheConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	if c.Size < 0 {
+		return fmt.Errorf("invalid query cache size %d", c.Size)
+	}
+	_, err := c.latestTTL()
+	return err
+}
+
+func (c *QueryCacheConfig) latestTTL() (time.Duration, error) {
+	if c.LatestTTL == "" {
+		return 0, nil
+	}
+	ttl, err := time.ParseDuration(c.LatestTTL)
+	if err != nil {
+		return 0, fmt.Errorf("invalid query cache latest-ttl %s: %w", c.LatestTTL, err)
+	}
+	return ttl, nil
+}
+
+// NewQueryCache returns the cache described by the config. A nil config returns a nil cache, which caches nothing.
+func (c *QueryCacheConfig) NewQueryCache() (*QueryCache, error) {
+	if c == nil {
+		return nil, nil
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	latestTTL, _ := c.latestTTL()
+	return NewQueryCache(c.Size, c.Dir, latestTTL)
+}
+
+// QueryCache caches the responses of the queries run by RunGRPCQuery. Responses of queries at a height are
+// immutable, so they are cached until they are evicted. Responses of queries at the latest height are only
+// cached for a short TTL, if at all. Queries sent over the native gRPC connection bypass RunGRPCQuery and
+// are not cached. A nil *QueryCache caches nothing.
+type QueryCache struct {
+	memory    *lru.Cache
+	dir       string
+	latestTTL time.Duration
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+type queryCacheEntry struct {
+	res     abci.ResponseQuery
+	expires time.Time
+}
+
+// NewQueryCache returns a cache keeping up to size responses in memory, DefaultQueryCacheSize if size is zero.
+// If dir is set, responses of queries at a height are stored in it instead. Responses of queries at the latest
+// height are cached for latestTTL.
+func NewQueryCache(size int, dir string, latestTTL time.Duration) (*QueryCache, error) {
+	if size == 0 {
+		size = DefaultQueryCacheSize
+	}
+	memory, err := lru.New(size)
+	if err != nil {
+		return nil, err
+	}
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return nil, fmt.Errorf("failed to create query cache directory: %w", err)
+		}
+	}
+	return &QueryCache{memory: memory, dir: dir, latestTTL: latestTTL}, nil
+}
+
+// Get returns the cached response of the query, if any.
+func (c *QueryCache) Get(req abci.RequestQuery) (abci.ResponseQuery, bool) {
+	if !c.cacheable(req) {
+		return abci.ResponseQuery{}, false
+	}
+	key := queryCacheKey(req)
+
+	if req.Height > 0 && c.dir != "" {
+		res, err := c.readFile(key)
+		if err != nil {
+			c.misses.Add(1)
+			return abci.ResponseQuery{}, false
+		}
+		c.hits.Add(1)
+		return res, true
+	}
+
+	v, ok := c.memory.Get(key)
+	if ok {
+		entry := v.(queryCacheEntry)
+		if entry.expires.IsZero() || time.Now().Before(entry.expires) {
+			c.hits.Add(1)
+			return entry.res, true
+		}
+		c.memory.Remove(key)
+	}
+	c.misses.Add(1)
+	return abci.ResponseQuery{}, false
+}
+
+// Set caches the response of the query, unless responses of the query are not cached.
+func (c *QueryCache) Set(req abci.RequestQuery, res abci.ResponseQuery) error {
+	if !c.cacheable(req) || !res.IsOK() {
+		return nil
+	}
+	key := queryCacheKey(req)
+
+	if req.Height > 0 {
+		if c.dir != "" {
+			return c.writeFile(key, res)
+		}
+		c.memory.Add(key, queryCacheEntry{res: res})
+		return nil
+	}
+	c.memory.Add(key, queryCacheEntry{res: res, expires: time.Now().Add(c.latestTTL)})
+	return nil
+}
+
+// Stats returns the number of cache hits and misses.
+func (c *QueryCache) Stats() (hits, misses uint64) {
+	if c == nil {
+		return 0, 0
+	}
+	return c.hits.Load(), c.misses.Load()
+}
+
+// cacheable reports whether responses of the query are cached.
+func (c *QueryCache) cacheable(req abci.RequestQuery) bool {
+	if c == nil || req.Height < 0 {
+		return false
+	}
+	return req.Height > 0 || (c.latestTTL > 0 && !uncachedLatestQueryPaths[req.Path])
+}
+
+// queryCacheKey identifies the response of a query by its path, data, height and whether it is proven.
+func queryCacheKey(req abci.RequestQuery) string {
+	h := sha256.New()
+	var height [8]byte
+	binary.BigEndian.PutUint64(height[:], uint64(req.Height))
+	h.Write(height[:])
+	if req.Prove {
+		h.Write([]byte{1})
+	} else {
+		h.Write([]byte{0})
+	}
+	h.Write([]byte(req.Path))
+	h.Write([]byte{0})
+	h.Write(req.Data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (c *QueryCache) readFile(key string) (abci.ResponseQuery, error) {
+	bz, err := os.ReadFile(filepath.Join(c.dir, key))
+	if err != nil {
+		return abci.ResponseQuery{}, err
+	}
+	var res abci.ResponseQuery
+	if err := res.Unmarshal(bz); err != nil {
+		return abci.ResponseQuery{}, err
+	}
+	return res, nil
+}
+
+// writeFile writes the response to a temporary file first, so that concurrent readers never see a partial file.
+func (c *QueryCache) writeFile(key string, res abci.ResponseQuery) error {
+	bz, err := res.Marshal()
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(c.dir, key)
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	f, err := os.CreateTemp(c.dir, key+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(bz); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
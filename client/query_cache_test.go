@@ -0,0 +1,95 @@
+package client_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/KyleMoser/cosmos-client/client"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryCacheConfig(t *testing.T) {
+	tests := map[string]struct {
+		config *client.QueryCacheConfig
+		err    string
+	}{
+		"nil config":         {},
+		"defaults":           {config: &client.QueryCacheConfig{}},
+		"latest ttl":         {config: &client.QueryCacheConfig{Size: 100, LatestTTL: "2s"}},
+		"negative size":      {config: &client.QueryCacheConfig{Size: -1}, err: "invalid query cache size -1"},
+		"invalid latest ttl": {config: &client.QueryCacheConfig{LatestTTL: "soon"}, err: "invalid query cache latest-ttl soon"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.config.Validate()
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestQueryCache(t *testing.T) {
+	coins := sdk.NewCoins(sdk.NewInt64Coin("uatom", 1))
+	query := func(t *testing.T, cc *client.ChainClient, height int64) {
+		ctx := context.Background()
+		if height > 0 {
+			ctx = client.SetHeightOnContext(ctx, height)
+		}
+		var res banktypes.QueryAllBalancesResponse
+		err := cc.Invoke(ctx, "/cosmos.bank.v1beta1.Query/AllBalances", &banktypes.QueryAllBalancesRequest{Address: "cosmos1"}, &res)
+		require.NoError(t, err)
+		require.Equal(t, coins, res.Balances)
+	}
+
+	t.Run("memory", func(t *testing.T) {
+		rpc := &balancesRPC{coins: coins}
+		cache, err := client.NewQueryCache(10, "", 0)
+		require.NoError(t, err)
+		cc := &client.ChainClient{Config: &client.ChainClientConfig{}, RPCClient: rpc, QueryCache: cache}
+
+		query(t, cc, 100)
+		query(t, cc, 100)
+		query(t, cc, 101)
+		// Queries at the latest height are not cached without a TTL.
+		query(t, cc, 0)
+		query(t, cc, 0)
+		require.Equal(t, []int64{100, 101, 0, 0}, rpc.heights)
+
+		hits, misses := cache.Stats()
+		require.Equal(t, uint64(1), hits)
+		require.Equal(t, uint64(2), misses)
+	})
+
+	t.Run("latest ttl", func(t *testing.T) {
+		rpc := &balancesRPC{coins: coins}
+		cache, err := client.NewQueryCache(10, "", 50*time.Millisecond)
+		require.NoError(t, err)
+		cc := &client.ChainClient{Config: &client.ChainClientConfig{}, RPCClient: rpc, QueryCache: cache}
+
+		query(t, cc, 0)
+		query(t, cc, 0)
+		time.Sleep(100 * time.Millisecond)
+		query(t, cc, 0)
+		require.Equal(t, []int64{0, 0}, rpc.heights)
+	})
+
+	t.Run("disk", func(t *testing.T) {
+		dir := t.TempDir()
+		rpc := &balancesRPC{coins: coins}
+		cache, err := client.NewQueryCache(10, dir, 0)
+		require.NoError(t, err)
+		query(t, &client.ChainClient{Config: &client.ChainClientConfig{}, RPCClient: rpc, QueryCache: cache}, 100)
+
+		// Responses on disk are used by later clients.
+		cache, err = client.NewQueryCache(10, dir, 0)
+		require.NoError(t, err)
+		query(t, &client.ChainClient{Config: &client.ChainClientConfig{}, RPCClient: rpc, QueryCache: cache}, 100)
+		require.Equal(t, []int64{100}, rpc.heights)
+	})
+}
@@ -0,0 +1,258 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	cmtbytes "github.com/cometbft/cometbft/libs/bytes"
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+	rpcclient "github.com/cometbft/cometbft/rpc/client"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	"github.com/cometbft/cometbft/rpc/jsonrpc/types"
+)
+
+// DefaultBatchSize is the maximum number of calls sent in one JSON-RPC batch request unless another size is given.
+const DefaultBatchSize = 20
+
+// ErrBatchNotSent is the error of the calls of a Batch until it is sent.
+var ErrBatchNotSent = errors.New("batch not sent")
+
+// BatchCaller sends JSON-RPC batch requests. Client and MultiClient implement it.
+type BatchCaller interface {
+	// CallBatch sends the requests in a single batch request and returns the responses in any order.
+	CallBatch(ctx context.Context, requests []types.RPCRequest) ([]types.RPCResponse, error)
+}
+
+var (
+	_ BatchCaller = &Client{}
+	_ BatchCaller = &MultiClient{}
+)
+
+// BatchCall is a call of a Batch. Its Result and Err are set when the batch is sent.
+type BatchCall[T any] struct {
+	Result T
+	Err    error
+}
+
+// Batch groups calls into JSON-RPC batch requests of up to a maximum size. Unlike the batches of CometBFT's
+// HTTP client, a failed call does not fail the other calls of its batch request.
+type Batch struct {
+	caller  BatchCaller
+	maxSize int
+	calls   []batchCall
+}
+
+type batchCall struct {
+	method string
+	params map[string]interface{}
+	// result is the pointer the result of the call is unmarshaled into.
+	result interface{}
+	done   func(err error)
+}
+
+// NewBatch returns a batch sending calls with the caller in batch requests of up to maxSize calls,
+// DefaultBatchSize if maxSize is zero.
+func NewBatch(caller BatchCaller, maxSize int) *Batch {
+	if maxSize <= 0 {
+		maxSize = DefaultBatchSize
+	}
+	return &Batch{caller: caller, maxSize: maxSize}
+}
+
+// addCall adds a call with a result of type T to the batch.
+func addCall[T any](b *Batch, method string, params map[string]interface{}) *BatchCall[*T] {
+	c := &BatchCall[*T]{Err: ErrBatchNotSent}
+	result := new(T)
+	b.calls = append(b.calls, batchCall{
+		method: method,
+		params: params,
+		result: result,
+		done: func(err error) {
+			c.Err = err
+			if err == nil {
+				c.Result = result
+			}
+		},
+	})
+	return c
+}
+
+// Block adds a call of the block at the height, or the latest block if height is nil.
+func (b *Batch) Block(height *int64) *BatchCall[*coretypes.ResultBlock] {
+	params := map[string]interface{}{}
+	if height != nil {
+		params["height"] = height
+	}
+	return addCall[coretypes.ResultBlock](b, "block", params)
+}
+
+// BlockResults adds a call of the results of the block at the height, or the latest block if height is nil.
+func (b *Batch) BlockResults(height *int64) *BatchCall[*coretypes.ResultBlockResults] {
+	params := map[string]interface{}{}
+	if height != nil {
+		params["height"] = height
+	}
+	return addCall[coretypes.ResultBlockResults](b, "block_results", params)
+}
+
+// Validators adds a call of a page of the validator set at the height, or the latest height if height is nil.
+func (b *Batch) Validators(height *int64, page, perPage *int) *BatchCall[*coretypes.ResultValidators] {
+	params := map[string]interface{}{}
+	if page != nil {
+		params["page"] = page
+	}
+	if perPage != nil {
+		params["per_page"] = perPage
+	}
+	if height != nil {
+		params["height"] = height
+	}
+	return addCall[coretypes.ResultValidators](b, "validators", params)
+}
+
+// ABCIQuery adds an ABCI query.
+func (b *Batch) ABCIQuery(path string, data cmtbytes.HexBytes, opts rpcclient.ABCIQueryOptions) *BatchCall[*coretypes.ResultABCIQuery] {
+	params := map[string]interface{}{"path": path, "data": data, "height": opts.Height, "prove": opts.Prove}
+	return addCall[coretypes.ResultABCIQuery](b, "abci_query", params)
+}
+
+// Len returns the number of calls that were added since the batch was last sent.
+func (b *Batch) Len() int {
+	return len(b.calls)
+}
+
+// Send sends the calls in batch requests of up to the maximum size and sets their results and errors.
+// It returns the first error of a batch request that failed as a whole, in which case every call of that
+// request has the error too. The batch is empty afterwards and can be reused.
+func (b *Batch) Send(ctx context.Context) error {
+	calls := b.calls
+	b.calls = nil
+
+	var firstErr error
+	for start := 0; start < len(calls); start += b.maxSize {
+		end := start + b.maxSize
+		if end > len(calls) {
+			end = len(calls)
+		}
+		if err := b.send(ctx, calls[start:end]); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// send sends the calls in a single batch request.
+func (b *Batch) send(ctx context.Context, calls []batchCall) error {
+	requests := make([]types.RPCRequest, len(calls))
+	for i, c := range calls {
+		req, err := types.MapToRequest(types.JSONRPCIntID(i), c.method, c.params)
+		if err != nil {
+			return b.fail(calls, err)
+		}
+		requests[i] = req
+	}
+
+	responses, err := b.caller.CallBatch(ctx, requests)
+	if err != nil {
+		return b.fail(calls, err)
+	}
+
+	byID := make(map[types.JSONRPCIntID]types.RPCResponse, len(responses))
+	for _, res := range responses {
+		if id, ok := res.ID.(types.JSONRPCIntID); ok {
+			byID[id] = res
+		}
+	}
+	for i, c := range calls {
+		res, ok := byID[types.JSONRPCIntID(i)]
+		switch {
+		case !ok:
+			c.done(fmt.Errorf("no response to %s call in batch", c.method))
+		case res.Error != nil:
+			c.done(res.Error)
+		default:
+			if err := cmtjson.Unmarshal(res.Result, c.result); err != nil {
+				c.done(fmt.Errorf("failed to unmarshal %s result: %w", c.method, err))
+			} else {
+				c.done(nil)
+			}
+		}
+	}
+	return nil
+}
+
+// fail sets the error of every call.
+func (b *Batch) fail(calls []batchCall, err error) error {
+	for _, c := range calls {
+		c.done(err)
+	}
+	return err
+}
+
+// CallBatch sends the requests in a single JSON-RPC batch request to the endpoint of the client.
+func (c *Client) CallBatch(ctx context.Context, requests []types.RPCRequest) ([]types.RPCResponse, error) {
+	body, err := json.Marshal(requests)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.batchURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	bz, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("batch request failed with HTTP status %s", res.Status)
+	}
+
+	var responses []types.RPCResponse
+	if err := json.Unmarshal(bz, &responses); err != nil {
+		// Requests that fail as a whole, e.g. batches that are too large, return a single error response.
+		var single types.RPCResponse
+		if json.Unmarshal(bz, &single) == nil && single.Error != nil {
+			return nil, single.Error
+		}
+		return nil, fmt.Errorf("failed to unmarshal batch response: %w", err)
+	}
+	return responses, nil
+}
+
+// CallBatch sends the requests in a single JSON-RPC batch request to the best endpoint, retrying it on the
+// other endpoints if it fails.
+func (m *MultiClient) CallBatch(ctx context.Context, requests []types.RPCRequest) ([]types.RPCResponse, error) {
+	return call(ctx, m, callRead, func(c rpcclient.Client) ([]types.RPCResponse, error) {
+		return c.(BatchCaller).CallBatch(ctx, requests)
+	})
+}
+
+// batchURL returns the URL that batch requests to the address are posted to. Like CometBFT's HTTP client,
+// it posts requests over TCP to HTTP addresses, and requests to unix sockets to a placeholder host, as the
+// transport dials the socket regardless of the host.
+func batchURL(addr string) (string, error) {
+	u, err := url.Parse(addr)
+	if err != nil {
+		return "", err
+	}
+	switch u.Scheme {
+	case "", "tcp":
+		u.Scheme = "http"
+	case "unix":
+		return "http://unix", nil
+	}
+	return u.String(), nil
+}
@@ -0,0 +1,95 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	"github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	"github.com/stretchr/testify/require"
+)
+
+// batchServer returns a JSON-RPC server answering batches of block_results calls, which fails the calls of height 3.
+func batchServer(t *testing.T, batches *atomic.Int64) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		batches.Add(1)
+		var reqs []types.RPCRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&reqs))
+
+		responses := make([]string, 0, len(reqs))
+		// Responses may be in any order.
+		for i := len(reqs) - 1; i >= 0; i-- {
+			var params struct {
+				Height string `json:"height"`
+			}
+			require.NoError(t, json.Unmarshal(reqs[i].Params, &params))
+			id, err := json.Marshal(reqs[i].ID)
+			require.NoError(t, err)
+			if params.Height == "3" {
+				responses = append(responses, fmt.Sprintf(`{"jsonrpc": "2.0", "id": %s, "error": {"code": -32603, "message": "Internal error", "data": "height 3 is not available"}}`, id))
+				continue
+			}
+			responses = append(responses, fmt.Sprintf(`{"jsonrpc": "2.0", "id": %s, "result": {"height": "%s"}}`, id, params.Height))
+		}
+		fmt.Fprintf(w, "[%s]", strings.Join(responses, ","))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestBatch(t *testing.T) {
+	var batches atomic.Int64
+	server := batchServer(t, &batches)
+	client, err := NewRPCClient(server.URL, 5*time.Second)
+	require.NoError(t, err)
+
+	batch := NewBatch(client, 2)
+	calls := make([]*BatchCall[*coretypes.ResultBlockResults], 5)
+	for i := range calls {
+		height := int64(i + 1)
+		calls[i] = batch.BlockResults(&height)
+	}
+	require.Equal(t, 5, batch.Len())
+	require.ErrorIs(t, calls[0].Err, ErrBatchNotSent)
+
+	require.NoError(t, batch.Send(context.Background()))
+	require.Equal(t, int64(3), batches.Load())
+	require.Equal(t, 0, batch.Len())
+	for i, c := range calls {
+		if i == 2 {
+			// A failed call does not fail the other calls of its batch request.
+			require.ErrorContains(t, c.Err, "height 3 is not available")
+			require.Nil(t, c.Result)
+			continue
+		}
+		require.NoError(t, c.Err)
+		require.Equal(t, int64(i+1), c.Result.Height)
+	}
+}
+
+func TestMultiClientBatch(t *testing.T) {
+	var down atomic.Bool
+	var calls, batches atomic.Int64
+	servers := []*httptest.Server{rpcServer(t, &down, &calls), batchServer(t, &batches)}
+	down.Store(true)
+
+	m, err := NewMultiClient([]string{servers[0].URL, servers[1].URL}, 5*time.Second)
+	require.NoError(t, err)
+
+	// Batches fail over to the healthy endpoint.
+	batch := NewBatch(m, 0)
+	height := int64(1)
+	call := batch.BlockResults(&height)
+	require.NoError(t, batch.Send(context.Background()))
+	require.NoError(t, call.Err)
+	require.Equal(t, int64(1), call.Result.Height)
+	require.Equal(t, int64(1), calls.Load())
+	require.Equal(t, int64(1), batches.Load())
+}
@@ -0,0 +1,496 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cometbft/cometbft/libs/bytes"
+	"github.com/cometbft/cometbft/libs/service"
+	rpcclient "github.com/cometbft/cometbft/rpc/client"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	"github.com/cometbft/cometbft/types"
+)
+
+const (
+	// DefaultFailureThreshold is the number of consecutive failures after which an endpoint is unhealthy.
+	DefaultFailureThreshold = 3
+	// DefaultCooldown is how long an unhealthy endpoint is skipped before it is tried again.
+	DefaultCooldown = 30 * time.Second
+
+	// latencyWeight is the weight of the latest call in the moving average of the latency of an endpoint.
+	latencyWeight = 0.3
+)
+
+// pinnedQueryPaths are ABCI queries whose results depend on the mempool or the latest state of a single node,
+// such as account sequences, so they are sent to the same node as broadcasts.
+var pinnedQueryPaths = map[string]bool{
+	"/cosmos.auth.v1beta1.Query/Account":     true,
+	"/cosmos.auth.v1beta1.Query/AccountInfo": true,
+	"/cosmos.tx.v1beta1.Service/Simulate":    true,
+	"/app/simulate":                          true,
+}
+
+// callKind determines which endpoint a call is sent to and whether it is retried.
+type callKind int
+
+const (
+	// callRead is an idempotent read, sent to the best endpoint and retried on the others.
+	callRead callKind = iota
+	// callPinnedRead is a read that must see the state of the pinned node. It is retried on another
+	// endpoint, which then becomes the pinned node.
+	callPinnedRead
+	// callPinned is a broadcast or subscription, sent to the pinned node and never retried.
+	callPinned
+)
+
+// EndpointStats are the statistics of an endpoint of a MultiClient.
+type EndpointStats struct {
+	Address  string `json:"address"`
+	Healthy  bool   `json:"healthy"`
+	Pinned   bool   `json:"pinned"`
+	Requests uint64 `json:"requests"`
+	Failures uint64 `json:"failures"`
+	// ConsecutiveFailures is the number of failures since the last successful call.
+	ConsecutiveFailures int `json:"consecutive_failures"`
+	// Latency is the moving average of the latency of successful calls.
+	Latency     time.Duration `json:"latency"`
+	LastError   string        `json:"last_error,omitempty"`
+	LastFailure time.Time     `json:"last_failure,omitempty"`
+}
+
+type endpoint struct {
+	addr   string
+	client *Client
+
+	// The fields below are guarded by the mutex of the MultiClient.
+	requests            uint64
+	failures            uint64
+	consecutiveFailures int
+	latency             time.Duration
+	inFlight            int
+	lastErr             error
+	lastFailure         time.Time
+}
+
+// MultiClient is an RPC client that sends every call to one of several endpoints of the same chain.
+// Reads go to the healthy endpoint with the lowest latency and fewest calls in flight, and are retried
+// on the other endpoints if they fail. Broadcasts, subscriptions and reads of state that depends on the
+// node, such as account sequences, are pinned to a single node until it becomes unhealthy.
+type MultiClient struct {
+	service.BaseService
+
+	endpoints        []*endpoint
+	failureThreshold int
+	cooldown         time.Duration
+
+	mu     sync.Mutex
+	pinned *endpoint
+}
+
+var _ rpcclient.Client = &MultiClient{}
+
+// NewMultiClient returns a MultiClient for the RPC addresses, which must belong to the same chain.
+// The options apply to the client of every address, except that failed requests are not retried on
+// the same endpoint, as failed calls are retried on the other endpoints.
+func NewMultiClient(addrs []string, timeout time.Duration, opts ...Option) (*MultiClient, error) {
+	if len(addrs) == 0 {
+		return nil, errors.New("at least one RPC address is required")
+	}
+	opts = append(opts[:len(opts):len(opts)], withoutRetries())
+	m := &MultiClient{failureThreshold: DefaultFailureThreshold, cooldown: DefaultCooldown}
+	for _, addr := range addrs {
+		client, err := NewRPCClient(addr, timeout, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RPC address %s: %w", addr, err)
+		}
+		m.endpoints = append(m.endpoints, &endpoint{addr: addr, client: client})
+	}
+	m.BaseService = *service.NewBaseService(nil, "MultiClient", m)
+	return m, nil
+}
+
+// WithFailureThreshold sets the number of consecutive failures after which an endpoint is unhealthy.
+func (m *MultiClient) WithFailureThreshold(threshold int) *MultiClient {
+	m.failureThreshold = threshold
+	return m
+}
+
+// WithCooldown sets how long an unhealthy endpoint is skipped before it is tried again.
+func (m *MultiClient) WithCooldown(cooldown time.Duration) *MultiClient {
+	m.cooldown = cooldown
+	return m
+}
+
+// OnStart starts the client of the pinned node, which subscriptions are made on.
+func (m *MultiClient) OnStart() error {
+	m.mu.Lock()
+	e := m.pin(nil)
+	m.mu.Unlock()
+	return e.client.Start()
+}
+
+// OnStop stops the clients of every node that subscriptions were made on.
+func (m *MultiClient) OnStop() {
+	for _, e := range m.endpoints {
+		if e.client.IsRunning() {
+			_ = e.client.Stop()
+		}
+	}
+}
+
+// Stats returns the statistics of every endpoint, in the order of the addresses of the client.
+func (m *MultiClient) Stats() []EndpointStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	stats := make([]EndpointStats, 0, len(m.endpoints))
+	for _, e := range m.endpoints {
+		s := EndpointStats{
+			Address:             e.addr,
+			Healthy:             m.healthy(e, now),
+			Pinned:              e == m.pinned,
+			Requests:            e.requests,
+			Failures:            e.failures,
+			ConsecutiveFailures: e.consecutiveFailures,
+			Latency:             e.latency,
+			LastFailure:         e.lastFailure,
+		}
+		if e.lastErr != nil {
+			s.LastError = e.lastErr.Error()
+		}
+		stats = append(stats, s)
+	}
+	return stats
+}
+
+// CheckHealth calls the health endpoint of every node, updating their statistics so that unhealthy
+// endpoints are detected before calls are sent to them.
+func (m *MultiClient) CheckHealth(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, e := range m.endpoints {
+		wg.Add(1)
+		go func(e *endpoint) {
+			defer wg.Done()
+			m.begin(e)
+			start := time.Now()
+			_, err := e.client.Health(ctx)
+			m.end(ctx, e, time.Since(start), err)
+		}(e)
+	}
+	wg.Wait()
+}
+
+// call sends the call to an endpoint selected for the kind of call, retrying it on other endpoints if allowed.
+// Errors returned by the node, such as a tx that is not found, are returned as they are: other nodes
+// would return the same error, and the endpoint answered so its health is not affected.
+func call[T any](ctx context.Context, m *MultiClient, kind callKind, fn func(rpcclient.Client) (T, error)) (res T, err error) {
+	tried := map[*endpoint]bool{}
+	for {
+		e := m.next(kind, tried)
+		if e == nil {
+			return res, err
+		}
+		tried[e] = true
+
+		// Subscriptions need the client of the pinned node to be started.
+		if kind != callRead && m.IsRunning() && !e.client.IsRunning() {
+			if startErr := e.client.Start(); startErr != nil && !errors.Is(startErr, service.ErrAlreadyStarted) {
+				return res, startErr
+			}
+		}
+
+		m.begin(e)
+		start := time.Now()
+		res, err = fn(e.client)
+		m.end(ctx, e, time.Since(start), err)
+		if err == nil || ctx.Err() != nil || kind == callPinned || isRPCError(err) {
+			return res, err
+		}
+	}
+}
+
+// next returns the endpoint to send a call to, or nil if every endpoint was tried.
+func (m *MultiClient) next(kind callKind, tried map[*endpoint]bool) *endpoint {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if kind == callRead {
+		return m.best(tried)
+	}
+	if m.pinned != nil && !tried[m.pinned] && m.healthy(m.pinned, time.Now()) {
+		return m.pinned
+	}
+	if len(tried) == len(m.endpoints) {
+		return nil
+	}
+	return m.pin(tried)
+}
+
+// pin pins the best endpoint that was not tried yet and returns it. The mutex must be held.
+func (m *MultiClient) pin(tried map[*endpoint]bool) *endpoint {
+	if m.pinned == nil || tried[m.pinned] || !m.healthy(m.pinned, time.Now()) {
+		if best := m.best(tried); best != nil {
+			m.pinned = best
+		}
+	}
+	return m.pinned
+}
+
+// best returns the endpoint that was not tried yet with the lowest latency weighted by the calls in flight.
+// Unhealthy endpoints are only returned if no healthy endpoint is left, least recently failed first.
+// The mutex must be held.
+func (m *MultiClient) best(tried map[*endpoint]bool) *endpoint {
+	now := time.Now()
+	var healthy, unhealthy []*endpoint
+	for _, e := range m.endpoints {
+		switch {
+		case tried[e]:
+		case m.healthy(e, now):
+			healthy = append(healthy, e)
+		default:
+			unhealthy = append(unhealthy, e)
+		}
+	}
+
+	if len(healthy) > 0 {
+		// Endpoints without a measured latency score zero, so every endpoint is tried.
+		score := func(e *endpoint) time.Duration { return e.latency * time.Duration(e.inFlight+1) }
+		sort.SliceStable(healthy, func(i, j int) bool { return score(healthy[i]) < score(healthy[j]) })
+		return healthy[0]
+	}
+	if len(unhealthy) > 0 {
+		sort.SliceStable(unhealthy, func(i, j int) bool { return unhealthy[i].lastFailure.Before(unhealthy[j].lastFailure) })
+		return unhealthy[0]
+	}
+	return nil
+}
+
+// healthy reports whether the endpoint has failed fewer times in a row than the threshold, or its cooldown has passed.
+// The mutex must be held.
+func (m *MultiClient) healthy(e *endpoint, now time.Time) bool {
+	return e.consecutiveFailures < m.failureThreshold || now.Sub(e.lastFailure) > m.cooldown
+}
+
+func (m *MultiClient) begin(e *endpoint) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e.inFlight++
+	e.requests++
+}
+
+// end records the result of a call. Calls cancelled by the caller and errors returned by the node
+// say nothing about the health of the endpoint and are not recorded.
+func (m *MultiClient) end(ctx context.Context, e *endpoint, latency time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e.inFlight--
+	switch {
+	case isRPCError(err):
+	case err == nil:
+		e.consecutiveFailures = 0
+		if e.latency == 0 {
+			e.latency = latency
+		} else {
+			e.latency = time.Duration(latencyWeight*float64(latency) + (1-latencyWeight)*float64(e.latency))
+		}
+	case ctx.Err() == nil:
+		e.failures++
+		e.consecutiveFailures++
+		e.lastErr = err
+		e.lastFailure = time.Now()
+	}
+}
+
+// isRPCError reports whether the error is a JSON-RPC error response of the node, rather than a transport,
+// HTTP status or timeout error.
+func isRPCError(err error) bool {
+	var rpcErr *rpctypes.RPCError
+	return errors.As(err, &rpcErr)
+}
+
+func queryKind(path string) callKind {
+	if pinnedQueryPaths[path] {
+		return callPinnedRead
+	}
+	return callRead
+}
+
+// ABCIClient
+
+func (m *MultiClient) ABCIInfo(ctx context.Context) (*coretypes.ResultABCIInfo, error) {
+	return call(ctx, m, callRead, func(c rpcclient.Client) (*coretypes.ResultABCIInfo, error) { return c.ABCIInfo(ctx) })
+}
+
+func (m *MultiClient) ABCIQuery(ctx context.Context, path string, data bytes.HexBytes) (*coretypes.ResultABCIQuery, error) {
+	return call(ctx, m, queryKind(path), func(c rpcclient.Client) (*coretypes.ResultABCIQuery, error) {
+		return c.ABCIQuery(ctx, path, data)
+	})
+}
+
+func (m *MultiClient) ABCIQueryWithOptions(ctx context.Context, path string, data bytes.HexBytes, opts rpcclient.ABCIQueryOptions) (*coretypes.ResultABCIQuery, error) {
+	return call(ctx, m, queryKind(path), func(c rpcclient.Client) (*coretypes.ResultABCIQuery, error) {
+		return c.ABCIQueryWithOptions(ctx, path, data, opts)
+	})
+}
+
+func (m *MultiClient) BroadcastTxCommit(ctx context.Context, tx types.Tx) (*coretypes.ResultBroadcastTxCommit, error) {
+	return call(ctx, m, callPinned, func(c rpcclient.Client) (*coretypes.ResultBroadcastTxCommit, error) {
+		return c.BroadcastTxCommit(ctx, tx)
+	})
+}
+
+func (m *MultiClient) BroadcastTxAsync(ctx context.Context, tx types.Tx) (*coretypes.ResultBroadcastTx, error) {
+	return call(ctx, m, callPinned, func(c rpcclient.Client) (*coretypes.ResultBroadcastTx, error) { return c.BroadcastTxAsync(ctx, tx) })
+}
+
+func (m *MultiClient) BroadcastTxSync(ctx context.Context, tx types.Tx) (*coretypes.ResultBroadcastTx, error) {
+	return call(ctx, m, callPinned, func(c rpcclient.Client) (*coretypes.ResultBroadcastTx, error) { return c.BroadcastTxSync(ctx, tx) })
+}
+
+// SignClient
+
+func (m *MultiClient) Block(ctx context.Context, height *int64) (*coretypes.ResultBlock, error) {
+	return call(ctx, m, callRead, func(c rpcclient.Client) (*coretypes.ResultBlock, error) { return c.Block(ctx, height) })
+}
+
+func (m *MultiClient) BlockByHash(ctx context.Context, hash []byte) (*coretypes.ResultBlock, error) {
+	return call(ctx, m, callRead, func(c rpcclient.Client) (*coretypes.ResultBlock, error) { return c.BlockByHash(ctx, hash) })
+}
+
+func (m *MultiClient) BlockResults(ctx context.Context, height *int64) (*coretypes.ResultBlockResults, error) {
+	return call(ctx, m, callRead, func(c rpcclient.Client) (*coretypes.ResultBlockResults, error) { return c.BlockResults(ctx, height) })
+}
+
+func (m *MultiClient) Header(ctx context.Context, height *int64) (*coretypes.ResultHeader, error) {
+	return call(ctx, m, callRead, func(c rpcclient.Client) (*coretypes.ResultHeader, error) { return c.Header(ctx, height) })
+}
+
+func (m *MultiClient) HeaderByHash(ctx context.Context, hash bytes.HexBytes) (*coretypes.ResultHeader, error) {
+	return call(ctx, m, callRead, func(c rpcclient.Client) (*coretypes.ResultHeader, error) { return c.HeaderByHash(ctx, hash) })
+}
+
+func (m *MultiClient) Commit(ctx context.Context, height *int64) (*coretypes.ResultCommit, error) {
+	return call(ctx, m, callRead, func(c rpcclient.Client) (*coretypes.ResultCommit, error) { return c.Commit(ctx, height) })
+}
+
+func (m *MultiClient) Validators(ctx context.Context, height *int64, page, perPage *int) (*coretypes.ResultValidators, error) {
+	return call(ctx, m, callRead, func(c rpcclient.Client) (*coretypes.ResultValidators, error) {
+		return c.Validators(ctx, height, page, perPage)
+	})
+}
+
+func (m *MultiClient) Tx(ctx context.Context, hash []byte, prove bool) (*coretypes.ResultTx, error) {
+	return call(ctx, m, callRead, func(c rpcclient.Client) (*coretypes.ResultTx, error) { return c.Tx(ctx, hash, prove) })
+}
+
+func (m *MultiClient) TxSearch(ctx context.Context, query string, prove bool, page, perPage *int, orderBy string) (*coretypes.ResultTxSearch, error) {
+	return call(ctx, m, callRead, func(c rpcclient.Client) (*coretypes.ResultTxSearch, error) {
+		return c.TxSearch(ctx, query, prove, page, perPage, orderBy)
+	})
+}
+
+func (m *MultiClient) BlockSearch(ctx context.Context, query string, page, perPage *int, orderBy string) (*coretypes.ResultBlockSearch, error) {
+	return call(ctx, m, callRead, func(c rpcclient.Client) (*coretypes.ResultBlockSearch, error) {
+		return c.BlockSearch(ctx, query, page, perPage, orderBy)
+	})
+}
+
+// HistoryClient
+
+func (m *MultiClient) Genesis(ctx context.Context) (*coretypes.ResultGenesis, error) {
+	return call(ctx, m, callRead, func(c rpcclient.Client) (*coretypes.ResultGenesis, error) { return c.Genesis(ctx) })
+}
+
+func (m *MultiClient) GenesisChunked(ctx context.Context, id uint) (*coretypes.ResultGenesisChunk, error) {
+	return call(ctx, m, callRead, func(c rpcclient.Client) (*coretypes.ResultGenesisChunk, error) { return c.GenesisChunked(ctx, id) })
+}
+
+func (m *MultiClient) BlockchainInfo(ctx context.Context, minHeight, maxHeight int64) (*coretypes.ResultBlockchainInfo, error) {
+	return call(ctx, m, callRead, func(c rpcclient.Client) (*coretypes.ResultBlockchainInfo, error) {
+		return c.BlockchainInfo(ctx, minHeight, maxHeight)
+	})
+}
+
+// StatusClient
+
+func (m *MultiClient) Status(ctx context.Context) (*coretypes.ResultStatus, error) {
+	return call(ctx, m, callRead, func(c rpcclient.Client) (*coretypes.ResultStatus, error) { return c.Status(ctx) })
+}
+
+// NetworkClient
+
+func (m *MultiClient) NetInfo(ctx context.Context) (*coretypes.ResultNetInfo, error) {
+	return call(ctx, m, callRead, func(c rpcclient.Client) (*coretypes.ResultNetInfo, error) { return c.NetInfo(ctx) })
+}
+
+func (m *MultiClient) DumpConsensusState(ctx context.Context) (*coretypes.ResultDumpConsensusState, error) {
+	return call(ctx, m, callRead, func(c rpcclient.Client) (*coretypes.ResultDumpConsensusState, error) {
+		return c.DumpConsensusState(ctx)
+	})
+}
+
+func (m *MultiClient) ConsensusState(ctx context.Context) (*coretypes.ResultConsensusState, error) {
+	return call(ctx, m, callRead, func(c rpcclient.Client) (*coretypes.ResultConsensusState, error) { return c.ConsensusState(ctx) })
+}
+
+func (m *MultiClient) ConsensusParams(ctx context.Context, height *int64) (*coretypes.ResultConsensusParams, error) {
+	return call(ctx, m, callRead, func(c rpcclient.Client) (*coretypes.ResultConsensusParams, error) {
+		return c.ConsensusParams(ctx, height)
+	})
+}
+
+func (m *MultiClient) Health(ctx context.Context) (*coretypes.ResultHealth, error) {
+	return call(ctx, m, callRead, func(c rpcclient.Client) (*coretypes.ResultHealth, error) { return c.Health(ctx) })
+}
+
+// EventsClient
+
+func (m *MultiClient) Subscribe(ctx context.Context, subscriber, query string, outCapacity ...int) (<-chan coretypes.ResultEvent, error) {
+	return call(ctx, m, callPinned, func(c rpcclient.Client) (<-chan coretypes.ResultEvent, error) {
+		return c.Subscribe(ctx, subscriber, query, outCapacity...)
+	})
+}
+
+func (m *MultiClient) Unsubscribe(ctx context.Context, subscriber, query string) error {
+	_, err := call(ctx, m, callPinned, func(c rpcclient.Client) (struct{}, error) {
+		return struct{}{}, c.Unsubscribe(ctx, subscriber, query)
+	})
+	return err
+}
+
+func (m *MultiClient) UnsubscribeAll(ctx context.Context, subscriber string) error {
+	_, err := call(ctx, m, callPinned, func(c rpcclient.Client) (struct{}, error) {
+		return struct{}{}, c.UnsubscribeAll(ctx, subscriber)
+	})
+	return err
+}
+
+// MempoolClient
+
+func (m *MultiClient) UnconfirmedTxs(ctx context.Context, limit *int) (*coretypes.ResultUnconfirmedTxs, error) {
+	return call(ctx, m, callPinnedRead, func(c rpcclient.Client) (*coretypes.ResultUnconfirmedTxs, error) {
+		return c.UnconfirmedTxs(ctx, limit)
+	})
+}
+
+func (m *MultiClient) NumUnconfirmedTxs(ctx context.Context) (*coretypes.ResultUnconfirmedTxs, error) {
+	return call(ctx, m, callPinnedRead, func(c rpcclient.Client) (*coretypes.ResultUnconfirmedTxs, error) {
+		return c.NumUnconfirmedTxs(ctx)
+	})
+}
+
+func (m *MultiClient) CheckTx(ctx context.Context, tx types.Tx) (*coretypes.ResultCheckTx, error) {
+	return call(ctx, m, callPinnedRead, func(c rpcclient.Client) (*coretypes.ResultCheckTx, error) { return c.CheckTx(ctx, tx) })
+}
+
+// EvidenceClient
+
+func (m *MultiClient) BroadcastEvidence(ctx context.Context, ev types.Evidence) (*coretypes.ResultBroadcastEvidence, error) {
+	return call(ctx, m, callPinned, func(c rpcclient.Client) (*coretypes.ResultBroadcastEvidence, error) {
+		return c.BroadcastEvidence(ctx, ev)
+	})
+}
@@ -0,0 +1,129 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/KyleMoser/cosmos-client/client/throttle"
+	"github.com/stretchr/testify/require"
+)
+
+// rpcServer returns a JSON-RPC server answering health and broadcast calls, which fails every call while down is set.
+// Tx calls are answered with a JSON-RPC error, as the tx is never found.
+func rpcServer(t *testing.T, down *atomic.Bool, calls *atomic.Int64) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if down.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		if req.Method == "tx" {
+			fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": %s, "error": {"code": -32603, "message": "Internal error", "data": "tx (AB) not found"}}`, req.ID)
+			return
+		}
+		result := `{}`
+		if req.Method == "broadcast_tx_sync" {
+			result = `{"code": 0, "data": "", "log": "", "codespace": "", "hash": "AB"}`
+		}
+		fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": %s, "result": %s}`, req.ID, result)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestMultiClient(t *testing.T) {
+	var down [2]atomic.Bool
+	var calls [2]atomic.Int64
+	servers := []*httptest.Server{rpcServer(t, &down[0], &calls[0]), rpcServer(t, &down[1], &calls[1])}
+
+	m, err := NewMultiClient([]string{servers[0].URL, servers[1].URL}, 5*time.Second)
+	require.NoError(t, err)
+	m.WithFailureThreshold(1).WithCooldown(time.Minute)
+	ctx := context.Background()
+
+	// Reads fail over to the healthy endpoint.
+	down[0].Store(true)
+	for i := 0; i < 3; i++ {
+		_, err = m.Health(ctx)
+		require.NoError(t, err)
+	}
+	stats := m.Stats()
+	require.False(t, stats[0].Healthy)
+	require.True(t, stats[1].Healthy)
+	require.Equal(t, uint64(1), stats[0].Failures)
+	require.Equal(t, uint64(3), stats[1].Requests)
+
+	// Broadcasts are pinned to a healthy node and are not retried when it fails.
+	_, err = m.BroadcastTxSync(ctx, []byte("tx"))
+	require.NoError(t, err)
+	require.True(t, m.Stats()[1].Pinned)
+
+	down[0].Store(false)
+	down[1].Store(true)
+	_, err = m.BroadcastTxSync(ctx, []byte("tx"))
+	require.Error(t, err)
+	require.Equal(t, int64(1), calls[0].Load())
+
+	// The pin moves once the pinned node is unhealthy.
+	_, err = m.BroadcastTxSync(ctx, []byte("tx"))
+	require.NoError(t, err)
+	require.True(t, m.Stats()[0].Pinned)
+}
+
+func TestMultiClientRPCError(t *testing.T) {
+	var down [2]atomic.Bool
+	var calls [2]atomic.Int64
+	servers := []*httptest.Server{rpcServer(t, &down[0], &calls[0]), rpcServer(t, &down[1], &calls[1])}
+
+	m, err := NewMultiClient([]string{servers[0].URL, servers[1].URL}, 5*time.Second)
+	require.NoError(t, err)
+	m.WithFailureThreshold(1).WithCooldown(time.Minute)
+
+	// Errors of the node are returned without retrying the other node or affecting the health of the endpoint.
+	_, err = m.Tx(context.Background(), []byte{0xab}, false)
+	require.ErrorContains(t, err, "not found")
+	require.Equal(t, int64(1), calls[0].Load()+calls[1].Load())
+	for _, stats := range m.Stats() {
+		require.True(t, stats.Healthy)
+		require.Zero(t, stats.Failures)
+	}
+}
+
+func TestMultiClientRetryPolicy(t *testing.T) {
+	var down [2]atomic.Bool
+	var calls [2]atomic.Int64
+	servers := []*httptest.Server{rpcServer(t, &down[0], &calls[0]), rpcServer(t, &down[1], &calls[1])}
+
+	policy := throttle.RetryPolicy{Attempts: 3, Delay: time.Millisecond, RetryOn: []throttle.ErrorClass{throttle.ClassServer}}
+	m, err := NewMultiClient([]string{servers[0].URL, servers[1].URL}, 5*time.Second, WithRetryPolicy(policy))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// Failed calls are retried on the other endpoint rather than on the same endpoint.
+	down[0].Store(true)
+	_, err = m.Health(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), calls[0].Load())
+
+	// Without a MultiClient, reads are retried on the same endpoint, but broadcasts are never retried.
+	client, err := NewRPCClient(servers[0].URL, 5*time.Second, WithRetryPolicy(policy))
+	require.NoError(t, err)
+	_, err = client.Health(ctx)
+	require.Error(t, err)
+	require.Equal(t, int64(4), calls[0].Load())
+	_, err = client.BroadcastTxSync(ctx, []byte("tx"))
+	require.Error(t, err)
+	require.Equal(t, int64(5), calls[0].Load())
+}
@@ -0,0 +1,164 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/KyleMoser/cosmos-client/client/telemetry"
+	"github.com/KyleMoser/cosmos-client/client/throttle"
+	"github.com/KyleMoser/cosmos-client/client/transport"
+)
+
+// Option configures the RPC clients created by NewRPCClient and NewMultiClient.
+type Option func(*options)
+
+type options struct {
+	telemetry bool
+	chainID   string
+	metrics   *telemetry.Metrics
+
+	throttle  bool
+	policy    throttle.RetryPolicy
+	rateLimit *throttle.RateLimitConfig
+
+	transportConfig *transport.Config
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithTelemetry records a span and the metrics of every HTTP request to the RPC endpoint, labeled with the
+// chain ID, endpoint and JSON-RPC method. Spans are only recorded if an OpenTelemetry tracer provider is
+// registered globally, and metrics only if they are not nil.
+func WithTelemetry(chainID string, metrics *telemetry.Metrics) Option {
+	return func(o *options) {
+		o.telemetry = true
+		o.chainID = chainID
+		o.metrics = metrics
+	}
+}
+
+// WithRetryPolicy retries failed HTTP requests to the RPC endpoint according to the policy and pauses
+// requests while the endpoint asks for them to be retried later. Broadcasts are never retried, and a
+// MultiClient retries failed calls on its other endpoints instead.
+func WithRetryPolicy(policy throttle.RetryPolicy) Option {
+	return func(o *options) {
+		o.throttle = true
+		o.policy = policy
+	}
+}
+
+// withoutRetries sends every HTTP request to the RPC endpoint once, keeping the rate limit of the options.
+func withoutRetries() Option {
+	return func(o *options) {
+		o.policy.Attempts = 1
+	}
+}
+
+// WithRateLimit limits the rate of HTTP requests to the RPC endpoint. Every endpoint of a MultiClient
+// has its own limiter. Without a retry policy, rate limited requests are not retried.
+func WithRateLimit(rateLimit *throttle.RateLimitConfig) Option {
+	return func(o *options) {
+		o.throttle = true
+		o.rateLimit = rateLimit
+	}
+}
+
+// WithTransport sends the headers of the config with every request to the RPC endpoint, including websocket
+// connections, and connects through its proxy with its TLS config.
+func WithTransport(cfg *transport.Config) Option {
+	return func(o *options) {
+		o.transportConfig = cfg
+	}
+}
+
+// transport returns the transport of the HTTP client of the RPC endpoint. Telemetry records every attempt
+// of a retried request.
+func (o options) transport(addr string, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if o.telemetry {
+		base = &instrumentedTransport{
+			base:    base,
+			labels:  telemetry.Labels{ChainID: o.chainID, Operation: telemetry.OperationRPC, Endpoint: addr},
+			metrics: o.metrics,
+		}
+	}
+	if o.throttle {
+		base = &throttle.Transport{Base: base, Policy: o.policy, Limiter: o.rateLimit.NewLimiter(), Idempotent: idempotent}
+	}
+	return base
+}
+
+// instrumentedTransport records the requests to an RPC endpoint. Only transport failures and HTTP errors
+// count as failures, as JSON-RPC errors are part of the response body.
+type instrumentedTransport struct {
+	base    http.RoundTripper
+	labels  telemetry.Labels
+	metrics *telemetry.Metrics
+}
+
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	labels := t.labels
+	labels.Method = jsonRPCMethod(req)
+	ctx, done := telemetry.Start(req.Context(), t.metrics, labels)
+
+	res, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err == nil && res.StatusCode >= http.StatusBadRequest {
+		done(fmt.Errorf("HTTP status %s", res.Status))
+	} else {
+		done(err)
+	}
+	return res, err
+}
+
+// nonIdempotentMethods are the JSON-RPC methods that must not be sent twice, as the node may have processed
+// a request that failed, for example because the response timed out.
+var nonIdempotentMethods = map[string]bool{
+	"broadcast_tx_async":  true,
+	"broadcast_tx_sync":   true,
+	"broadcast_tx_commit": true,
+	"broadcast_evidence":  true,
+}
+
+// idempotent reports whether the JSON-RPC request may be retried. Batches only contain reads.
+func idempotent(req *http.Request) bool {
+	return !nonIdempotentMethods[jsonRPCMethod(req)]
+}
+
+// jsonRPCMethod returns the method of a JSON-RPC request, or "batch" for batch requests.
+func jsonRPCMethod(req *http.Request) string {
+	if req.GetBody == nil {
+		return "unknown"
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return "unknown"
+	}
+	defer body.Close()
+	bz, err := io.ReadAll(body)
+	if err != nil {
+		return "unknown"
+	}
+
+	bz = bytes.TrimSpace(bz)
+	if len(bz) > 0 && bz[0] == '[' {
+		return "batch"
+	}
+	var rpcReq struct {
+		Method string `json:"method"`
+	}
+	if err := json.Unmarshal(bz, &rpcReq); err != nil || rpcReq.Method == "" {
+		return "unknown"
+	}
+	return rpcReq.Method
+}
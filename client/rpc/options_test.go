@@ -0,0 +1,164 @@
+package rpc
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/KyleMoser/cosmos-client/client/telemetry"
+	"github.com/KyleMoser/cosmos-client/client/transport"
+	"github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	"github.com/gorilla/websocket"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithTelemetry(t *testing.T) {
+	var down atomic.Bool
+	var calls atomic.Int64
+	server := rpcServer(t, &down, &calls)
+
+	reg := prometheus.NewRegistry()
+	metrics, err := telemetry.NewMetrics(reg)
+	require.NoError(t, err)
+	client, err := NewRPCClient(server.URL, 5*time.Second, WithTelemetry("osmosis-1", metrics))
+	require.NoError(t, err)
+
+	_, err = client.Health(context.Background())
+	require.NoError(t, err)
+	down.Store(true)
+	_, err = client.Health(context.Background())
+	require.Error(t, err)
+
+	expected := `
+# HELP lens_request_failures_total Number of failed requests of chain clients.
+# TYPE lens_request_failures_total counter
+lens_request_failures_total{chain_id="osmosis-1",endpoint="` + server.URL + `",method="health",operation="rpc"} 1
+# HELP lens_requests_total Number of requests of chain clients.
+# TYPE lens_requests_total counter
+lens_requests_total{chain_id="osmosis-1",endpoint="` + server.URL + `",method="health",operation="rpc"} 2
+`
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected), "lens_requests_total", "lens_request_failures_total"))
+}
+
+func TestWithTransport(t *testing.T) {
+	const query = "tm.event='NewBlock'"
+	var apiKey atomic.Value
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiKey.Store(r.Header.Get("X-Api-Key"))
+		if r.URL.Path != "/websocket" {
+			_, _ = io.WriteString(w, `{"jsonrpc":"2.0","id":0,"result":{}}`)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var req types.RPCRequest
+		if err := conn.ReadJSON(&req); err != nil || req.Method != "subscribe" {
+			return
+		}
+		_ = conn.WriteJSON(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "result": map[string]interface{}{}})
+		_ = conn.WriteJSON(map[string]interface{}{"jsonrpc": "2.0", "id": "1#event", "result": map[string]interface{}{
+			"query":  query,
+			"data":   nil,
+			"events": map[string][]string{"tm.event": {"NewBlock"}},
+		}})
+		_, _, _ = conn.ReadMessage()
+	}))
+	defer server.Close()
+
+	client, err := NewRPCClient(server.URL, 5*time.Second, WithTransport(&transport.Config{
+		Headers: map[string]string{"X-Api-Key": "secret"},
+	}))
+	require.NoError(t, err)
+
+	_, err = client.Health(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "secret", apiKey.Load())
+
+	// Subscriptions are made over a websocket that sends the headers too.
+	apiKey.Store("")
+	require.NoError(t, client.Start())
+	defer client.Stop() //nolint:errcheck
+	require.Equal(t, "secret", apiKey.Load())
+
+	events, err := client.Subscribe(context.Background(), "test", query)
+	require.NoError(t, err)
+	select {
+	case event := <-events:
+		require.Equal(t, query, event.Query)
+		require.Equal(t, []string{"NewBlock"}, event.Events["tm.event"])
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event received")
+	}
+}
+
+func TestWSEventsBlockedSubscriber(t *testing.T) {
+	const query = "tm.event='NewBlock'"
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/websocket" {
+			_, _ = io.WriteString(w, `{"jsonrpc":"2.0","id":0,"result":{}}`)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			var req types.RPCRequest
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+			_ = conn.WriteJSON(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": map[string]interface{}{}})
+			if req.Method == "subscribe" {
+				// The event is sent once the subscription is registered by the client.
+				time.Sleep(50 * time.Millisecond)
+				_ = conn.WriteJSON(map[string]interface{}{"jsonrpc": "2.0", "id": "1#event", "result": map[string]interface{}{
+					"query":  query,
+					"data":   nil,
+					"events": map[string][]string{"tm.event": {"NewBlock"}},
+				}})
+			}
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewRPCClient(server.URL, 5*time.Second, WithTransport(&transport.Config{
+		Headers: map[string]string{"X-Api-Key": "secret"},
+	}))
+	require.NoError(t, err)
+	require.NoError(t, client.Start())
+
+	// The event is never received from the unbuffered channel, which must not block unsubscribing or stopping.
+	_, err = client.Subscribe(context.Background(), "test", query, 0)
+	require.NoError(t, err)
+	time.Sleep(200 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		if err := client.Unsubscribe(ctx, "test", query); err != nil {
+			done <- err
+			return
+		}
+		done <- client.Stop()
+	}()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-ctx.Done():
+		t.Fatal("unsubscribing blocked on the subscriber")
+	}
+}
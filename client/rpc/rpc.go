@@ -1,22 +1,115 @@
 package rpc
 
 import (
-	//rpcclient "github.com/cometbft/cometbft/rpc/client"
+	"context"
+	"net/http"
 	"time"
 
+	"github.com/cometbft/cometbft/libs/log"
+	"github.com/cometbft/cometbft/libs/service"
+	rpcclient "github.com/cometbft/cometbft/rpc/client"
 	rpchttp "github.com/cometbft/cometbft/rpc/client/http"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
 	libclient "github.com/cometbft/cometbft/rpc/jsonrpc/client"
 )
 
-func NewRPCClient(addr string, timeout time.Duration) (*rpchttp.HTTP, error) {
+// Client is the RPC client of a single endpoint. It is CometBFT's HTTP client, except that subscriptions are
+// made over a websocket that uses the transport options, if any are set.
+type Client struct {
+	*rpchttp.HTTP
+	events eventsService
+
+	// httpClient and batchURL send batch requests, see CallBatch.
+	httpClient *http.Client
+	batchURL   string
+}
+
+// eventsService is the client that subscriptions are made on, which is started and stopped with the Client.
+type eventsService interface {
+	service.Service
+	rpcclient.EventsClient
+}
+
+var _ rpcclient.Client = &Client{}
+
+func NewRPCClient(addr string, timeout time.Duration, opts ...Option) (*Client, error) {
+	o := newOptions(opts)
 	httpClient, err := libclient.DefaultHTTPClient(addr)
 	if err != nil {
 		return nil, err
 	}
 	httpClient.Timeout = timeout
+	base, err := o.transportConfig.HTTPTransport(httpClient.Transport.(*http.Transport))
+	if err != nil {
+		return nil, err
+	}
+	httpClient.Transport = o.transport(addr, base)
 	rpcClient, err := rpchttp.NewWithClient(addr, "/websocket", httpClient)
 	if err != nil {
 		return nil, err
 	}
-	return rpcClient, nil
+
+	batchURL, err := batchURL(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Client{HTTP: rpcClient, events: rpcClient.WSEvents, httpClient: httpClient, batchURL: batchURL}
+	if !o.transportConfig.IsZero() {
+		if c.events, err = newWSEvents(addr, "/websocket", o.transportConfig); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// NewHTTPClient returns CometBFT's HTTP client for the endpoint, which is what NewRPCClient returned
+// before it supported options. Use NewRPCClient for transport options and batch requests.
+func NewHTTPClient(addr string, timeout time.Duration) (*rpchttp.HTTP, error) {
+	httpClient, err := libclient.DefaultHTTPClient(addr)
+	if err != nil {
+		return nil, err
+	}
+	httpClient.Timeout = timeout
+	return rpchttp.NewWithClient(addr, "/websocket", httpClient)
+}
+
+func (c *Client) Start() error {
+	return c.events.Start()
+}
+
+func (c *Client) Stop() error {
+	return c.events.Stop()
+}
+
+func (c *Client) Reset() error {
+	return c.events.Reset()
+}
+
+func (c *Client) IsRunning() bool {
+	return c.events.IsRunning()
+}
+
+func (c *Client) Quit() <-chan struct{} {
+	return c.events.Quit()
+}
+
+func (c *Client) String() string {
+	return c.events.String()
+}
+
+func (c *Client) SetLogger(l log.Logger) {
+	c.events.SetLogger(l)
+}
+
+func (c *Client) Subscribe(ctx context.Context, subscriber, query string, outCapacity ...int) (<-chan coretypes.ResultEvent, error) {
+	return c.events.Subscribe(ctx, subscriber, query, outCapacity...)
+}
+
+func (c *Client) Unsubscribe(ctx context.Context, subscriber, query string) error {
+	return c.events.Unsubscribe(ctx, subscriber, query)
+}
+
+func (c *Client) UnsubscribeAll(ctx context.Context, subscriber string) error {
+	return c.events.UnsubscribeAll(ctx, subscriber)
 }
@@ -0,0 +1,285 @@
+package rpc
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/KyleMoser/cosmos-client/client/transport"
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+	"github.com/cometbft/cometbft/libs/service"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	"github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// wsWriteWait is the time allowed to write a request to the websocket.
+	wsWriteWait = 10 * time.Second
+	// wsMaxReconnectDelay caps the delay between attempts to reconnect the websocket.
+	wsMaxReconnectDelay = 30 * time.Second
+)
+
+var errNotRunning = errors.New("client is not running, use the Start method to start")
+
+// wsEvents subscribes to events over a websocket like the events client of CometBFT's HTTP client, which
+// cannot send headers or use a proxy or TLS config. Subscriptions are renewed after reconnecting.
+type wsEvents struct {
+	service.BaseService
+	url    string
+	dialer *websocket.Dialer
+	header http.Header
+
+	// writeMu serializes writes to the connection, which gorilla/websocket does not support concurrently.
+	writeMu sync.Mutex
+
+	mu            sync.RWMutex
+	conn          *websocket.Conn
+	nextID        int
+	subscriptions map[string]chan coretypes.ResultEvent // query -> channel
+}
+
+func newWSEvents(remote, endpoint string, cfg *transport.Config) (*wsEvents, error) {
+	u, err := url.Parse(remote)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "", "tcp", "http", "ws":
+		u.Scheme = "ws"
+	case "https", "wss":
+		u.Scheme = "wss"
+	default:
+		return nil, fmt.Errorf("websocket of %s: transport options are not supported for scheme %s", remote, u.Scheme)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + endpoint
+
+	dialer, err := cfg.WebsocketDialer()
+	if err != nil {
+		return nil, err
+	}
+	header := cfg.Header()
+	if u.User != nil && header.Get("Authorization") == "" {
+		// Like the HTTP client, authenticate with the credentials of the remote address.
+		password, _ := u.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(u.User.Username() + ":" + password))
+		header.Set("Authorization", "Basic "+credentials)
+	}
+	u.User = nil
+
+	w := &wsEvents{
+		url:           u.String(),
+		dialer:        dialer,
+		header:        header,
+		subscriptions: map[string]chan coretypes.ResultEvent{},
+	}
+	w.BaseService = *service.NewBaseService(nil, "wsEvents", w)
+	return w, nil
+}
+
+// OnStart connects the websocket and starts receiving events.
+func (w *wsEvents) OnStart() error {
+	conn, err := w.dial(context.Background())
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	w.conn = conn
+	w.mu.Unlock()
+
+	go w.readLoop(conn)
+	return nil
+}
+
+// OnStop closes the websocket, which stops receiving events.
+func (w *wsEvents) OnStop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn != nil {
+		_ = w.conn.Close()
+	}
+}
+
+func (w *wsEvents) dial(ctx context.Context) (*websocket.Conn, error) {
+	conn, res, err := w.dialer.DialContext(ctx, w.url, w.header)
+	if res != nil {
+		res.Body.Close()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to websocket %s: %w", w.url, err)
+	}
+	return conn, nil
+}
+
+// Subscribe subscribes to the events of the query. The subscriber is ignored, as CometBFT uses the remote
+// address of the connection instead. The channel has a capacity of one unless outCapacity is given, and is
+// never closed.
+func (w *wsEvents) Subscribe(ctx context.Context, _, query string, outCapacity ...int) (<-chan coretypes.ResultEvent, error) {
+	if !w.IsRunning() {
+		return nil, errNotRunning
+	}
+	if err := w.send(ctx, "subscribe", map[string]interface{}{"query": query}); err != nil {
+		return nil, err
+	}
+
+	outCap := 1
+	if len(outCapacity) > 0 {
+		outCap = outCapacity[0]
+	}
+	out := make(chan coretypes.ResultEvent, outCap)
+	w.mu.Lock()
+	w.subscriptions[query] = out
+	w.mu.Unlock()
+	return out, nil
+}
+
+// Unsubscribe unsubscribes from the events of the query.
+func (w *wsEvents) Unsubscribe(ctx context.Context, _, query string) error {
+	if !w.IsRunning() {
+		return errNotRunning
+	}
+	if err := w.send(ctx, "unsubscribe", map[string]interface{}{"query": query}); err != nil {
+		return err
+	}
+	w.mu.Lock()
+	delete(w.subscriptions, query)
+	w.mu.Unlock()
+	return nil
+}
+
+// UnsubscribeAll unsubscribes from the events of every query.
+func (w *wsEvents) UnsubscribeAll(ctx context.Context, _ string) error {
+	if !w.IsRunning() {
+		return errNotRunning
+	}
+	if err := w.send(ctx, "unsubscribe_all", map[string]interface{}{}); err != nil {
+		return err
+	}
+	w.mu.Lock()
+	w.subscriptions = map[string]chan coretypes.ResultEvent{}
+	w.mu.Unlock()
+	return nil
+}
+
+// send writes a request to the websocket. Responses are received by the read loop, which ignores them
+// unless they are events.
+func (w *wsEvents) send(ctx context.Context, method string, params map[string]interface{}) error {
+	w.mu.Lock()
+	conn := w.conn
+	w.nextID++
+	id := w.nextID
+	w.mu.Unlock()
+
+	req, err := types.MapToRequest(types.JSONRPCIntID(id), method, params)
+	if err != nil {
+		return err
+	}
+
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+	deadline := time.Now().Add(wsWriteWait)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := conn.SetWriteDeadline(deadline); err != nil {
+		return err
+	}
+	return conn.WriteJSON(req)
+}
+
+// readLoop receives events from the connection, reconnecting until the client is stopped.
+func (w *wsEvents) readLoop(conn *websocket.Conn) {
+	for {
+		var res types.RPCResponse
+		if err := conn.ReadJSON(&res); err != nil {
+			if !w.IsRunning() {
+				return
+			}
+			w.Logger.Error("Websocket connection failed, reconnecting", "url", w.url, "err", err)
+			if conn = w.reconnect(); conn == nil {
+				return
+			}
+			continue
+		}
+		if res.Error != nil {
+			w.Logger.Error("Websocket error", "url", w.url, "err", res.Error.Error())
+			continue
+		}
+
+		result := new(coretypes.ResultEvent)
+		if err := cmtjson.Unmarshal(res.Result, result); err != nil {
+			w.Logger.Error("Failed to unmarshal event", "err", err)
+			continue
+		}
+		// Responses to requests have no query, so they match no subscription. The event is sent after
+		// unlocking, as a blocked subscriber would otherwise block subscribing and unsubscribing.
+		w.mu.RLock()
+		out, ok := w.subscriptions[result.Query]
+		w.mu.RUnlock()
+		if !ok {
+			continue
+		}
+		if cap(out) == 0 {
+			select {
+			case out <- *result:
+			case <-w.Quit():
+				return
+			}
+		} else {
+			select {
+			case out <- *result:
+			default:
+				w.Logger.Error("Dropped event because the channel is full", "query", result.Query)
+			}
+		}
+	}
+}
+
+// reconnect connects the websocket again with exponential backoff and renews the subscriptions. It returns
+// nil if the client was stopped.
+func (w *wsEvents) reconnect() *websocket.Conn {
+	delay := time.Second
+	for {
+		select {
+		case <-w.Quit():
+			return nil
+		case <-time.After(delay):
+		}
+
+		conn, err := w.dial(context.Background())
+		if err != nil {
+			w.Logger.Error("Failed to reconnect websocket", "url", w.url, "err", err)
+			if delay *= 2; delay > wsMaxReconnectDelay {
+				delay = wsMaxReconnectDelay
+			}
+			continue
+		}
+
+		w.mu.Lock()
+		_ = w.conn.Close()
+		w.conn = conn
+		queries := make([]string, 0, len(w.subscriptions))
+		for query := range w.subscriptions {
+			queries = append(queries, query)
+		}
+		w.mu.Unlock()
+		if !w.IsRunning() {
+			// The client was stopped while reconnecting and did not close the new connection.
+			_ = conn.Close()
+			return nil
+		}
+
+		for _, query := range queries {
+			if err := w.send(context.Background(), "subscribe", map[string]interface{}{"query": query}); err != nil {
+				w.Logger.Error("Failed to resubscribe", "query", query, "err", err)
+			}
+		}
+		return conn
+	}
+}
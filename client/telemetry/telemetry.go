@@ -0,0 +1,181 @@
+// Package telemetry instruments chain clients with Prometheus metrics and OpenTelemetry traces.
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	namespace = "lens"
+
+	// Operations of the chain client which are instrumented.
+	OperationInvoke       = "invoke"
+	OperationQueryABCI    = "query_abci"
+	OperationSendMsgs     = "send_msgs"
+	OperationCalculateGas = "calculate_gas"
+	OperationRPC          = "rpc"
+
+	// instrumentationName is the name of the tracer of the chain client.
+	instrumentationName = "github.com/KyleMoser/cosmos-client"
+)
+
+// labelNames are the labels of every metric.
+var labelNames = []string{"chain_id", "operation", "method", "endpoint"}
+
+// Labels identify a request of a chain client.
+type Labels struct {
+	ChainID   string
+	Operation string
+	// Method is the gRPC method, ABCI query path or JSON-RPC method of the request.
+	Method   string
+	Endpoint string
+}
+
+func (l Labels) values() []string {
+	return []string{l.ChainID, l.Operation, l.Method, l.Endpoint}
+}
+
+// Metrics are the Prometheus metrics of chain client requests. A nil *Metrics records nothing.
+type Metrics struct {
+	requests     *prometheus.CounterVec
+	failures     *prometheus.CounterVec
+	duration     *prometheus.HistogramVec
+	cacheLookups *prometheus.CounterVec
+}
+
+// NewMetrics returns metrics registered with the registerer.
+func NewMetrics(reg prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "requests_total",
+			Help:      "Number of requests of chain clients.",
+		}, labelNames),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "request_failures_total",
+			Help:      "Number of failed requests of chain clients.",
+		}, labelNames),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "request_duration_seconds",
+			Help:      "Duration of requests of chain clients.",
+			Buckets:   []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+		}, labelNames),
+		cacheLookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "query_cache_lookups_total",
+			Help:      "Number of lookups of cached query responses, by result (hit or miss).",
+		}, []string{"chain_id", "method", "result"}),
+	}
+	for _, c := range []prometheus.Collector{m.requests, m.failures, m.duration, m.cacheLookups} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+var (
+	defaultMetrics     *Metrics
+	defaultMetricsOnce sync.Once
+)
+
+// DefaultMetrics returns the metrics registered with the default Prometheus registerer, which are shared
+// by every chain client with metrics enabled in its config.
+func DefaultMetrics() *Metrics {
+	defaultMetricsOnce.Do(func() {
+		m, err := NewMetrics(prometheus.DefaultRegisterer)
+		if err != nil {
+			// The metrics are only registered once, so registration cannot conflict.
+			panic(err)
+		}
+		defaultMetrics = m
+	})
+	return defaultMetrics
+}
+
+// Observe records a request that took the duration and failed if err is set.
+func (m *Metrics) Observe(labels Labels, duration time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	values := labels.values()
+	m.requests.WithLabelValues(values...).Inc()
+	if err != nil {
+		m.failures.WithLabelValues(values...).Inc()
+	}
+	m.duration.WithLabelValues(values...).Observe(duration.Seconds())
+}
+
+// ObserveCacheLookup records a lookup of a cached query response of the method.
+func (m *Metrics) ObserveCacheLookup(chainID, method string, hit bool) {
+	if m == nil {
+		return
+	}
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	m.cacheLookups.WithLabelValues(chainID, method, result).Inc()
+}
+
+// Start starts a span for the request as a child of the span of the context, if any, and returns a function
+// that ends the span and records the request in the metrics. Spans are only recorded if an OpenTelemetry
+// tracer provider is registered globally.
+func Start(ctx context.Context, m *Metrics, labels Labels) (context.Context, func(err error)) {
+	start := time.Now()
+	ctx, span := otel.Tracer(instrumentationName).Start(ctx, labels.Operation+" "+labels.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("chain_id", labels.ChainID),
+			attribute.String("method", labels.Method),
+			attribute.String("endpoint", labels.Endpoint),
+		),
+	)
+	return ctx, func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+		m.Observe(labels, time.Since(start), err)
+	}
+}
+
+// ServeMetrics serves the metrics of the gatherer at /metrics on the address until the context is done.
+func ServeMetrics(ctx context.Context, addr string, gatherer prometheus.Gatherer) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}))
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.ListenAndServe()
+	}()
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			return err
+		}
+		if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	}
+}
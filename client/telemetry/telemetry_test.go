@@ -0,0 +1,43 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m, err := NewMetrics(reg)
+	require.NoError(t, err)
+
+	labels := Labels{ChainID: "osmosis-1", Operation: OperationInvoke, Method: "/cosmos.bank.v1beta1.Query/Balance", Endpoint: "https://rpc.osmosis.zone"}
+	_, done := Start(context.Background(), m, labels)
+	done(nil)
+	_, done = Start(context.Background(), m, labels)
+	done(errors.New("unavailable"))
+
+	require.Equal(t, 2.0, testutil.ToFloat64(m.requests.WithLabelValues(labels.values()...)))
+	require.Equal(t, 1.0, testutil.ToFloat64(m.failures.WithLabelValues(labels.values()...)))
+	require.Equal(t, 1, testutil.CollectAndCount(m.duration))
+
+	m.ObserveCacheLookup("osmosis-1", labels.Method, true)
+	m.ObserveCacheLookup("osmosis-1", labels.Method, true)
+	m.ObserveCacheLookup("osmosis-1", labels.Method, false)
+	require.Equal(t, 2.0, testutil.ToFloat64(m.cacheLookups.WithLabelValues("osmosis-1", labels.Method, "hit")))
+	require.Equal(t, 1.0, testutil.ToFloat64(m.cacheLookups.WithLabelValues("osmosis-1", labels.Method, "miss")))
+
+	// Metrics can only be registered once per registry.
+	_, err = NewMetrics(reg)
+	require.Error(t, err)
+
+	// Nil metrics record nothing.
+	var nilMetrics *Metrics
+	_, done = Start(context.Background(), nilMetrics, labels)
+	done(nil)
+	nilMetrics.ObserveCacheLookup("osmosis-1", labels.Method, true)
+}
@@ -0,0 +1,85 @@
+package throttle
+
+import (
+	"fmt"
+	"time"
+)
+
+// RetryConfig is a retry policy in a config file, with durations such as "400ms".
+// Unset fields take the values of DefaultRetryPolicy.
+type RetryConfig struct {
+	Attempts uint     `json:"attempts,omitempty" yaml:"attempts,omitempty"`
+	Delay    string   `json:"delay,omitempty" yaml:"delay,omitempty"`
+	MaxDelay string   `json:"max-delay,omitempty" yaml:"max-delay,omitempty"`
+	Jitter   string   `json:"jitter,omitempty" yaml:"jitter,omitempty"`
+	RetryOn  []string `json:"retry-on,omitempty" yaml:"retry-on,omitempty"`
+}
+
+// Policy returns the retry policy of the config. A nil config is DefaultRetryPolicy.
+func (c *RetryConfig) Policy() (RetryPolicy, error) {
+	p := DefaultRetryPolicy()
+	if c == nil {
+		return p, nil
+	}
+	if c.Attempts > 0 {
+		p.Attempts = c.Attempts
+	}
+	for _, d := range []struct {
+		name  string
+		value string
+		dst   *time.Duration
+	}{
+		{"delay", c.Delay, &p.Delay},
+		{"max-delay", c.MaxDelay, &p.MaxDelay},
+		{"jitter", c.Jitter, &p.Jitter},
+	} {
+		if d.value == "" {
+			continue
+		}
+		duration, err := time.ParseDuration(d.value)
+		if err != nil {
+			return RetryPolicy{}, fmt.Errorf("invalid retry %s %s: %w", d.name, d.value, err)
+		}
+		*d.dst = duration
+	}
+	if len(c.RetryOn) > 0 {
+		p.RetryOn = nil
+		for _, class := range c.RetryOn {
+			switch ErrorClass(class) {
+			case ClassNetwork, ClassTimeout, ClassRateLimit, ClassServer, ClassAny:
+				p.RetryOn = append(p.RetryOn, ErrorClass(class))
+			default:
+				return RetryPolicy{}, fmt.Errorf("unknown retry error class %q, expected %q, %q, %q, %q or %q",
+					class, ClassNetwork, ClassTimeout, ClassRateLimit, ClassServer, ClassAny)
+			}
+		}
+	}
+	return p, nil
+}
+
+// RateLimitConfig is a client-side rate limit in a config file.
+type RateLimitConfig struct {
+	// RequestsPerSecond is the average number of requests per second. Zero only honours Retry-After headers.
+	RequestsPerSecond float64 `json:"requests-per-second,omitempty" yaml:"requests-per-second,omitempty"`
+	// Burst is the maximum number of requests sent at once. It defaults to one.
+	Burst int `json:"burst,omitempty" yaml:"burst,omitempty"`
+}
+
+// Validate checks that the rate limit is not negative.
+func (c *RateLimitConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	if c.RequestsPerSecond < 0 || c.Burst < 0 {
+		return fmt.Errorf("invalid rate limit of %v requests per second with bursts of %d", c.RequestsPerSecond, c.Burst)
+	}
+	return nil
+}
+
+// NewLimiter returns a limiter of the rate limit. A nil config only honours Retry-After headers.
+func (c *RateLimitConfig) NewLimiter() *Limiter {
+	if c == nil {
+		return NewLimiter(0, 1)
+	}
+	return NewLimiter(c.RequestsPerSecond, c.Burst)
+}
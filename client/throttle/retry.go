@@ -0,0 +1,190 @@
+// Package throttle retries failed requests and limits the rate of requests to remote endpoints.
+package throttle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/avast/retry-go/v4"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorClass is a class of errors that a RetryPolicy retries.
+type ErrorClass string
+
+const (
+	// ClassNetwork are connection failures, such as refused or reset connections.
+	ClassNetwork ErrorClass = "network"
+	// ClassTimeout are requests that timed out.
+	ClassTimeout ErrorClass = "timeout"
+	// ClassRateLimit are requests rejected by rate limiting, such as HTTP 429 responses.
+	ClassRateLimit ErrorClass = "rate-limit"
+	// ClassServer are server errors, such as HTTP 5xx responses.
+	ClassServer ErrorClass = "server"
+	// ClassAny is every error.
+	ClassAny ErrorClass = "any"
+)
+
+// ErrRetriesExhausted is returned when a request still fails after every attempt of a policy.
+// Errors wrapping it are never retried again, so that nested retries do not multiply the attempts.
+var ErrRetriesExhausted = errors.New("retries exhausted")
+
+// StatusError is an HTTP response with an error status.
+type StatusError struct {
+	StatusCode int
+	// RetryAfter is the delay requested by the Retry-After header of the response, if any.
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("HTTP status %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// RetryPolicy determines which failed requests are retried, how often and how long to wait in between.
+type RetryPolicy struct {
+	// Attempts is the maximum number of attempts, including the first one.
+	Attempts uint
+	// Delay is the delay after the first failed attempt, which doubles after every further attempt.
+	Delay time.Duration
+	// MaxDelay caps the delay between attempts, except for delays requested by the server.
+	MaxDelay time.Duration
+	// Jitter is the maximum random delay added to every delay, spreading out the retries of concurrent requests.
+	Jitter time.Duration
+	// RetryOn are the classes of errors that are retried.
+	RetryOn []ErrorClass
+}
+
+// DefaultRetryPolicy returns the policy used unless one is configured: 5 attempts with exponential backoff
+// from 400ms up to 10s and 100ms of jitter, retrying network, timeout, rate limit and server errors.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		Attempts: 5,
+		Delay:    400 * time.Millisecond,
+		MaxDelay: 10 * time.Second,
+		Jitter:   100 * time.Millisecond,
+		RetryOn:  []ErrorClass{ClassNetwork, ClassTimeout, ClassRateLimit, ClassServer},
+	}
+}
+
+// Retryable reports whether the error is in one of the classes retried by the policy.
+func (p RetryPolicy) Retryable(err error) bool {
+	if err == nil || errors.Is(err, ErrRetriesExhausted) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	for _, class := range p.RetryOn {
+		if class == ClassAny || class == Classify(err) {
+			return true
+		}
+	}
+	return false
+}
+
+// Backoff returns the delay after the given failed attempt, counting from 1. The delay is at least
+// the delay requested by the server.
+func (p RetryPolicy) Backoff(attempt uint, retryAfter time.Duration) time.Duration {
+	delay := p.Delay
+	for i := uint(1); i < attempt && (p.MaxDelay <= 0 || delay < p.MaxDelay); i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		delay += time.Duration(rand.Int63n(int64(p.Jitter)))
+	}
+	if delay < retryAfter {
+		delay = retryAfter
+	}
+	return delay
+}
+
+// Do calls fn until it succeeds, it fails with an error that is not retryable, or the attempts of the policy
+// are used up, in which case the last error is returned wrapped in ErrRetriesExhausted.
+func (p RetryPolicy) Do(ctx context.Context, fn func() error) error {
+	attempts := p.attempts()
+	var lastErr error
+	err := retry.Do(
+		func() error {
+			lastErr = fn()
+			return lastErr
+		},
+		retry.Context(ctx),
+		retry.Attempts(attempts),
+		retry.RetryIf(p.Retryable),
+		retry.DelayType(func(n uint, err error, _ *retry.Config) time.Duration {
+			return p.Backoff(n+1, retryAfter(err))
+		}),
+		retry.LastErrorOnly(true),
+	)
+	if err != nil && attempts > 1 && p.Retryable(lastErr) && ctx.Err() == nil {
+		return fmt.Errorf("%w after %d attempts: %w", ErrRetriesExhausted, attempts, err)
+	}
+	return err
+}
+
+// attempts returns the number of attempts of the policy, which is at least one.
+func (p RetryPolicy) attempts() uint {
+	if p.Attempts == 0 {
+		return 1
+	}
+	return p.Attempts
+}
+
+// Classify returns the class of the error.
+func Classify(err error) ErrorClass {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		switch {
+		case statusErr.StatusCode == http.StatusTooManyRequests:
+			return ClassRateLimit
+		case statusErr.StatusCode >= http.StatusInternalServerError:
+			return ClassServer
+		}
+		return ClassAny
+	}
+
+	if s, ok := status.FromError(err); ok && s.Code() != codes.Unknown {
+		switch s.Code() {
+		case codes.ResourceExhausted:
+			return ClassRateLimit
+		case codes.Unavailable:
+			return ClassServer
+		case codes.DeadlineExceeded:
+			return ClassTimeout
+		}
+		return ClassAny
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ClassTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return ClassTimeout
+		}
+		return ClassNetwork
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return ClassNetwork
+	}
+	return ClassAny
+}
+
+// retryAfter returns the delay requested by the server that rejected the request, if any.
+func retryAfter(err error) time.Duration {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.RetryAfter
+	}
+	return 0
+}
@@ -0,0 +1,214 @@
+package throttle_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/KyleMoser/cosmos-client/client/throttle"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestClassify(t *testing.T) {
+	tests := map[string]struct {
+		err      error
+		expected throttle.ErrorClass
+	}{
+		"rate limited":       {&throttle.StatusError{StatusCode: http.StatusTooManyRequests}, throttle.ClassRateLimit},
+		"server error":       {fmt.Errorf("query: %w", &throttle.StatusError{StatusCode: http.StatusBadGateway}), throttle.ClassServer},
+		"client error":       {&throttle.StatusError{StatusCode: http.StatusNotFound}, throttle.ClassAny},
+		"grpc unavailable":   {status.Error(codes.Unavailable, "connection refused"), throttle.ClassServer},
+		"grpc exhausted":     {status.Error(codes.ResourceExhausted, "slow down"), throttle.ClassRateLimit},
+		"grpc invalid":       {status.Error(codes.InvalidArgument, "bad request"), throttle.ClassAny},
+		"deadline exceeded":  {context.DeadlineExceeded, throttle.ClassTimeout},
+		"unexpected EOF":     {fmt.Errorf("read: %w", io.ErrUnexpectedEOF), throttle.ClassNetwork},
+		"other error":        {errors.New("invalid request"), throttle.ClassAny},
+		"connection refused": {&netError{}, throttle.ClassNetwork},
+		"net timeout":        {&netError{timeout: true}, throttle.ClassTimeout},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.expected, throttle.Classify(tc.err))
+		})
+	}
+}
+
+type netError struct{ timeout bool }
+
+func (e *netError) Error() string   { return "dial tcp: connection refused" }
+func (e *netError) Timeout() bool   { return e.timeout }
+func (e *netError) Temporary() bool { return false }
+
+func TestRetryConfigPolicy(t *testing.T) {
+	tests := map[string]struct {
+		config   *throttle.RetryConfig
+		expected throttle.RetryPolicy
+		err      string
+	}{
+		"nil config": {
+			expected: throttle.DefaultRetryPolicy(),
+		},
+		"overrides": {
+			config: &throttle.RetryConfig{Attempts: 3, Delay: "1s", Jitter: "0s", RetryOn: []string{"rate-limit"}},
+			expected: throttle.RetryPolicy{
+				Attempts: 3,
+				Delay:    time.Second,
+				MaxDelay: 10 * time.Second,
+				RetryOn:  []throttle.ErrorClass{throttle.ClassRateLimit},
+			},
+		},
+		"invalid duration": {
+			config: &throttle.RetryConfig{MaxDelay: "soon"},
+			err:    "invalid retry max-delay soon",
+		},
+		"unknown class": {
+			config: &throttle.RetryConfig{RetryOn: []string{"teapot"}},
+			err:    `unknown retry error class "teapot"`,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			policy, err := tc.config.Policy()
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, policy)
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	policy := throttle.RetryPolicy{Delay: 100 * time.Millisecond, MaxDelay: time.Second}
+	require.Equal(t, 100*time.Millisecond, policy.Backoff(1, 0))
+	require.Equal(t, 400*time.Millisecond, policy.Backoff(3, 0))
+	require.Equal(t, time.Second, policy.Backoff(10, 0))
+	// Delays requested by the server exceed the maximum delay.
+	require.Equal(t, 5*time.Second, policy.Backoff(1, 5*time.Second))
+}
+
+func TestRetryPolicyDo(t *testing.T) {
+	policy := throttle.RetryPolicy{Attempts: 3, Delay: time.Millisecond, RetryOn: []throttle.ErrorClass{throttle.ClassServer}}
+
+	calls := 0
+	err := policy.Do(context.Background(), func() error {
+		calls++
+		if calls < 3 {
+			return &throttle.StatusError{StatusCode: http.StatusServiceUnavailable}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, calls)
+
+	calls = 0
+	err = policy.Do(context.Background(), func() error {
+		calls++
+		return &throttle.StatusError{StatusCode: http.StatusInternalServerError}
+	})
+	require.ErrorIs(t, err, throttle.ErrRetriesExhausted)
+	require.Equal(t, 3, calls)
+
+	// Errors that are not retryable are returned immediately.
+	calls = 0
+	err = policy.Do(context.Background(), func() error {
+		calls++
+		return &throttle.StatusError{StatusCode: http.StatusTooManyRequests}
+	})
+	require.Error(t, err)
+	require.NotErrorIs(t, err, throttle.ErrRetriesExhausted)
+	require.Equal(t, 1, calls)
+}
+
+func TestTransport(t *testing.T) {
+	var calls atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Retries must replay the request body.
+		if body, _ := io.ReadAll(r.Body); string(body) != `{"method":"status"}` {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch calls.Add(1) {
+		case 1:
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			_, _ = w.Write([]byte("ok"))
+		}
+	}))
+	defer server.Close()
+
+	policy := throttle.RetryPolicy{
+		Attempts: 3,
+		Delay:    time.Millisecond,
+		RetryOn:  []throttle.ErrorClass{throttle.ClassRateLimit, throttle.ClassServer},
+	}
+	client := &http.Client{Transport: &throttle.Transport{Policy: policy, Limiter: throttle.NewLimiter(0, 1)}}
+
+	start := time.Now()
+	res, err := client.Post(server.URL, "application/json", strings.NewReader(`{"method":"status"}`))
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.EqualValues(t, 3, calls.Load())
+	// The Retry-After header delays the retry beyond the backoff of the policy.
+	require.GreaterOrEqual(t, time.Since(start), time.Second)
+}
+
+func TestTransportExhausted(t *testing.T) {
+	var calls atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	policy := throttle.RetryPolicy{Attempts: 2, Delay: time.Millisecond, RetryOn: []throttle.ErrorClass{throttle.ClassServer}}
+	client := &http.Client{Transport: &throttle.Transport{Policy: policy}}
+
+	// The last error response is returned to the caller as if it was not retried.
+	res, err := client.Get(server.URL)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+	require.EqualValues(t, 2, calls.Load())
+
+	// Requests that are not idempotent are sent once.
+	calls.Store(0)
+	client.Transport.(*throttle.Transport).Idempotent = func(*http.Request) bool { return false }
+	res, err = client.Get(server.URL)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+	require.EqualValues(t, 1, calls.Load())
+}
+
+func TestLimiter(t *testing.T) {
+	limiter := throttle.NewLimiter(0, 1)
+	limiter.Pause(200 * time.Millisecond)
+
+	start := time.Now()
+	require.NoError(t, limiter.Wait(context.Background()))
+	require.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	limiter.Pause(time.Minute)
+	require.ErrorIs(t, limiter.Wait(ctx), context.Canceled)
+
+	// A nil limiter does not limit requests.
+	var none *throttle.Limiter
+	require.NoError(t, none.Wait(context.Background()))
+}
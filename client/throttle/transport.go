@@ -0,0 +1,169 @@
+package throttle
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Limiter is a client-side token bucket rate limiter, which also pauses every request while a server
+// asks for requests to be retried later. A nil *Limiter does not limit requests.
+type Limiter struct {
+	limiter *rate.Limiter
+
+	mu    sync.Mutex
+	until time.Time
+}
+
+// NewLimiter returns a limiter allowing the number of requests per second on average, with bursts of
+// up to burst requests. Requests are only paused on request of the server if requestsPerSecond is zero.
+func NewLimiter(requestsPerSecond float64, burst int) *Limiter {
+	limit := rate.Inf
+	if requestsPerSecond > 0 {
+		limit = rate.Limit(requestsPerSecond)
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &Limiter{limiter: rate.NewLimiter(limit, burst)}
+}
+
+// Wait blocks until a request may be sent or the context is done.
+func (l *Limiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	pause := time.Until(l.until)
+	l.mu.Unlock()
+	if pause > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(pause):
+		}
+	}
+	return l.limiter.Wait(ctx)
+}
+
+// Pause pauses every request for the duration, such as the delay requested by a Retry-After header.
+func (l *Limiter) Pause(d time.Duration) {
+	if l == nil || d <= 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until := time.Now().Add(d); until.After(l.until) {
+		l.until = until
+	}
+}
+
+// Transport is an http.RoundTripper that waits for the limiter before every request and retries failed
+// requests according to the policy. Responses with a Retry-After header pause the limiter for the requested
+// delay. Requests with a body are only retried if the body can be replayed, which is the case for requests
+// created by http.NewRequest with an in-memory body.
+type Transport struct {
+	// Base is the transport sending the requests. It defaults to http.DefaultTransport.
+	Base    http.RoundTripper
+	Policy  RetryPolicy
+	Limiter *Limiter
+	// Idempotent reports whether a request may be sent again if it failed. Requests such as broadcasts,
+	// which the server may have processed even though they failed, must never be retried.
+	// Every request is considered idempotent if it is nil.
+	Idempotent func(*http.Request) bool
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	ctx := req.Context()
+	attempts := t.Policy.attempts()
+	idempotent := t.Idempotent == nil || t.Idempotent(req)
+
+	for attempt := uint(1); ; attempt++ {
+		if err := t.Limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+
+		r := req
+		if attempt > 1 && req.Body != nil && req.Body != http.NoBody {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r = req.Clone(ctx)
+			r.Body = body
+		}
+
+		res, err := base.RoundTrip(r)
+		if err == nil {
+			if !retryableStatus(res.StatusCode) {
+				return res, nil
+			}
+			delay := parseRetryAfter(res.Header.Get("Retry-After"))
+			t.Limiter.Pause(delay)
+			err = &StatusError{StatusCode: res.StatusCode, RetryAfter: delay}
+		}
+
+		canReplay := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+		if !idempotent || !t.Policy.Retryable(err) || !canReplay || ctx.Err() != nil {
+			return res, resultErr(res, err)
+		}
+		if attempt >= attempts {
+			if res != nil {
+				// The caller handles the error response as if it was not retried.
+				return res, nil
+			}
+			if attempts == 1 {
+				return nil, err
+			}
+			return nil, fmt.Errorf("%w after %d attempts: %w", ErrRetriesExhausted, attempts, err)
+		}
+
+		if res != nil {
+			// Drain the body so that the connection can be reused.
+			_, _ = io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(t.Policy.Backoff(attempt, retryAfter(err))):
+		}
+	}
+}
+
+// resultErr returns the error of a request that is not retried. Error responses are returned as they are.
+func resultErr(res *http.Response, err error) error {
+	if res != nil {
+		return nil
+	}
+	return err
+}
+
+// retryableStatus reports whether a response with the status may succeed if the request is retried.
+func retryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// parseRetryAfter returns the delay of a Retry-After header, which is either a number of seconds or an HTTP date.
+func parseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		return time.Until(date)
+	}
+	return 0
+}
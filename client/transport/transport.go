@@ -0,0 +1,317 @@
+// Package transport configures how chain clients connect to remote endpoints: extra headers, authentication,
+// proxies and TLS, applied to RPC, websocket, gRPC and registry clients alike.
+package transport
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"golang.org/x/net/proxy"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// Config is the transport of a chain client or registry in a config file. A nil *Config connects directly,
+// without extra headers and with the system's root CAs.
+type Config struct {
+	// Headers are sent with every request, e.g. the API key of an RPC provider.
+	Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	// BasicAuth authenticates every request with a username and password.
+	BasicAuth *BasicAuth `json:"basic-auth,omitempty" yaml:"basic-auth,omitempty"`
+	// BearerToken authenticates every request with a bearer token.
+	BearerToken string `json:"bearer-token,omitempty" yaml:"bearer-token,omitempty"`
+	// Proxy is the URL of an HTTP, HTTPS or SOCKS5 proxy, e.g. socks5://localhost:1080.
+	Proxy string `json:"proxy,omitempty" yaml:"proxy,omitempty"`
+	// CAFile is a PEM file of the CAs that server certificates are verified with, instead of the system's root CAs.
+	CAFile string `json:"ca-file,omitempty" yaml:"ca-file,omitempty"`
+	// CertFile and KeyFile are the PEM files of a client certificate for mutual TLS.
+	CertFile string `json:"cert-file,omitempty" yaml:"cert-file,omitempty"`
+	KeyFile  string `json:"key-file,omitempty" yaml:"key-file,omitempty"`
+}
+
+// BasicAuth is the username and password of HTTP basic authentication.
+type BasicAuth struct {
+	Username string `json:"username" yaml:"username"`
+	Password string `json:"password" yaml:"password"`
+}
+
+// IsZero reports whether the config connects like a nil config.
+func (c *Config) IsZero() bool {
+	return c == nil || (len(c.Headers) == 0 && c.BasicAuth == nil && c.BearerToken == "" &&
+		c.Proxy == "" && c.CAFile == "" && c.CertFile == "" && c.KeyFile == "")
+}
+
+// Validate checks the proxy URL and that the TLS files can be loaded.
+func (c *Config) Validate() error {
+	if c == nil {
+		return nil
+	}
+	if c.BasicAuth != nil && c.BearerToken != "" {
+		return errors.New("basic-auth and bearer-token cannot both be set")
+	}
+	if _, err := c.ProxyURL(); err != nil {
+		return err
+	}
+	_, err := c.TLSConfig()
+	return err
+}
+
+// Header returns the headers sent with every request, including the Authorization header.
+func (c *Config) Header() http.Header {
+	header := http.Header{}
+	if c == nil {
+		return header
+	}
+	for k, v := range c.Headers {
+		header.Set(k, v)
+	}
+	switch {
+	case c.BasicAuth != nil:
+		credentials := base64.StdEncoding.EncodeToString([]byte(c.BasicAuth.Username + ":" + c.BasicAuth.Password))
+		header.Set("Authorization", "Basic "+credentials)
+	case c.BearerToken != "":
+		header.Set("Authorization", "Bearer "+c.BearerToken)
+	}
+	return header
+}
+
+// ProxyURL returns the URL of the proxy, or nil if no proxy is configured.
+func (c *Config) ProxyURL() (*url.URL, error) {
+	if c == nil || c.Proxy == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(c.Proxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy %s: %w", c.Proxy, err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("invalid proxy %s: unsupported scheme %q, expected http, https or socks5", c.Proxy, u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy %s: missing host", c.Proxy)
+	}
+	return u, nil
+}
+
+// TLSConfig returns the TLS config of connections to servers, or nil if neither a CA nor a client certificate
+// is configured, in which case the defaults apply.
+func (c *Config) TLSConfig() (*tls.Config, error) {
+	if c == nil || (c.CAFile == "" && c.CertFile == "" && c.KeyFile == "") {
+		return nil, nil
+	}
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ca-file: %w", err)
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in ca-file %s", c.CAFile)
+		}
+	}
+	if c.CertFile != "" || c.KeyFile != "" {
+		if c.CertFile == "" || c.KeyFile == "" {
+			return nil, errors.New("cert-file and key-file must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// HTTPTransport returns a clone of the base transport that uses the proxy and TLS config and sends the headers
+// with every request. Base defaults to http.DefaultTransport.
+func (c *Config) HTTPTransport(base *http.Transport) (http.RoundTripper, error) {
+	if base == nil {
+		base = http.DefaultTransport.(*http.Transport)
+	}
+	if c.IsZero() {
+		return base, nil
+	}
+	t := base.Clone()
+
+	proxyURL, err := c.ProxyURL()
+	if err != nil {
+		return nil, err
+	}
+	if proxyURL != nil {
+		t.Proxy = http.ProxyURL(proxyURL)
+		// Custom dialers, such as the one of CometBFT's HTTP client, may dial the server no matter which
+		// address they are asked to dial, which would bypass the proxy.
+		t.Dial = nil //nolint:staticcheck
+		t.DialContext = nil
+	}
+	tlsConfig, err := c.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		t.TLSClientConfig = tlsConfig
+	}
+
+	if header := c.Header(); len(header) > 0 {
+		return &headerTransport{base: t, header: header}, nil
+	}
+	return t, nil
+}
+
+// WebsocketDialer returns a websocket dialer that uses the proxy and TLS config. The headers must be passed
+// to its Dial method.
+func (c *Config) WebsocketDialer() (*websocket.Dialer, error) {
+	dialer := *websocket.DefaultDialer
+	proxyURL, err := c.ProxyURL()
+	if err != nil {
+		return nil, err
+	}
+	if proxyURL != nil {
+		dialer.Proxy = http.ProxyURL(proxyURL)
+	}
+	if dialer.TLSClientConfig, err = c.TLSConfig(); err != nil {
+		return nil, err
+	}
+	return &dialer, nil
+}
+
+// GRPCDialOptions returns the dial options that send the headers as metadata with every call and connect
+// through the proxy. The TLS config must be set in the transport credentials of the connection.
+func (c *Config) GRPCDialOptions() ([]grpc.DialOption, error) {
+	var opts []grpc.DialOption
+	if header := c.Header(); len(header) > 0 {
+		metadata := map[string]string{}
+		for k := range header {
+			// gRPC metadata keys are lowercase.
+			metadata[strings.ToLower(k)] = header.Get(k)
+		}
+		opts = append(opts, grpc.WithPerRPCCredentials(headerCredentials(metadata)))
+	}
+
+	proxyURL, err := c.ProxyURL()
+	if err != nil {
+		return nil, err
+	}
+	if proxyURL != nil {
+		opts = append(opts, grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			return dialProxy(ctx, proxyURL, addr)
+		}))
+	}
+	return opts, nil
+}
+
+// headerTransport sends headers with every request, unless the request already sets them.
+type headerTransport struct {
+	base   http.RoundTripper
+	header http.Header
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for k, v := range t.header {
+		if req.Header.Get(k) == "" {
+			req.Header[k] = v
+		}
+	}
+	return t.base.RoundTrip(req)
+}
+
+// headerCredentials sends metadata with every gRPC call. Like the headers of HTTP requests, they are also
+// sent over connections without TLS, as configured.
+type headerCredentials map[string]string
+
+var _ credentials.PerRPCCredentials = headerCredentials{}
+
+func (h headerCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return h, nil
+}
+
+func (h headerCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+// dialProxy connects to the address through the proxy.
+func dialProxy(ctx context.Context, proxyURL *url.URL, addr string) (net.Conn, error) {
+	if proxyURL.Scheme == "socks5" {
+		dialer, err := proxy.FromURL(proxyURL, proxy.Direct)
+		if err != nil {
+			return nil, err
+		}
+		return dialer.(proxy.ContextDialer).DialContext(ctx, "tcp", addr)
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", proxyURL.Host)
+	if err != nil {
+		return nil, err
+	}
+	if proxyURL.Scheme == "https" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: proxyURL.Hostname(), MinVersion: tls.VersionTLS12})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{}) //nolint:errcheck
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: http.Header{},
+	}
+	if user := proxyURL.User; user != nil {
+		password, _ := user.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(user.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	br := bufio.NewReader(conn)
+	res, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy %s refused to connect to %s: %s", proxyURL.Host, addr, res.Status)
+	}
+	if br.Buffered() > 0 {
+		// The server already sent data, which was read along with the response of the proxy.
+		return &bufferedConn{Conn: conn, r: br}, nil
+	}
+	return conn, nil
+}
+
+// bufferedConn is a connection whose first bytes were read into a buffer.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
@@ -6,7 +6,7 @@ import (
 	"strings"
 
 	"cosmossdk.io/store/rootmulti"
-	"github.com/avast/retry-go/v4"
+	"github.com/KyleMoser/cosmos-client/client/telemetry"
 	abci "github.com/cometbft/cometbft/abci/types"
 	rpcclient "github.com/cometbft/cometbft/rpc/client"
 	"github.com/cosmos/cosmos-sdk/client"
@@ -54,6 +54,13 @@ func (cc *ChainClient) SendMsg(ctx context.Context, msg sdk.Msg, memo string) (*
 // of that transaction will be logged. A boolean indicating if a transaction was successfully
 // sent and executed successfully is returned.
 func (cc *ChainClient) SendMsgs(ctx context.Context, msgs []sdk.Msg, memo string) (*sdk.TxResponse, error) {
+	ctx, done := cc.instrument(ctx, telemetry.OperationSendMsgs, msgsMethod(msgs), cc.Config.RPCAddr)
+	res, err := cc.sendMsgs(ctx, msgs, memo)
+	done(err)
+	return res, err
+}
+
+func (cc *ChainClient) sendMsgs(ctx context.Context, msgs []sdk.Msg, memo string) (*sdk.TxResponse, error) {
 	txf, err := cc.PrepareFactory(cc.TxFactory())
 	if err != nil {
 		return nil, err
@@ -131,13 +138,13 @@ func (cc *ChainClient) PrepareFactory(txf tx.Factory) (tx.Factory, error) {
 	)
 
 	// Get key address and retry if fail
-	if err = retry.Do(func() error {
+	if err = cc.RetryPolicy().Do(context.Background(), func() error {
 		from, err = cc.GetKeyAddress()
 		if err != nil {
 			return err
 		}
 		return err
-	}, RtyAtt, RtyDel, RtyErr); err != nil {
+	}); err != nil {
 		return tx.Factory{}, err
 	}
 
@@ -147,25 +154,25 @@ func (cc *ChainClient) PrepareFactory(txf tx.Factory) (tx.Factory, error) {
 		WithCodec(cc.Codec.Marshaler)
 
 	// Set the account number and sequence on the transaction factory and retry if fail
-	if err = retry.Do(func() error {
+	if err = cc.RetryPolicy().Do(context.Background(), func() error {
 		if err = txf.AccountRetriever().EnsureExists(cliCtx, from); err != nil {
 			return err
 		}
 		return err
-	}, RtyAtt, RtyDel, RtyErr); err != nil {
+	}); err != nil {
 		return txf, err
 	}
 
 	// TODO: why this code? this may potentially require another query when we don't want one
 	initNum, initSeq := txf.AccountNumber(), txf.Sequence()
 	if initNum == 0 || initSeq == 0 {
-		if err = retry.Do(func() error {
+		if err = cc.RetryPolicy().Do(context.Background(), func() error {
 			num, seq, err = txf.AccountRetriever().GetAccountNumberSequence(cliCtx, from)
 			if err != nil {
 				return err
 			}
 			return err
-		}, RtyAtt, RtyDel, RtyErr); err != nil {
+		}); err != nil {
 			return txf, err
 		}
 
@@ -185,21 +192,29 @@ func (cc *ChainClient) PrepareFactory(txf tx.Factory) (tx.Factory, error) {
 	return txf, nil
 }
 
+// CalculateGas simulates the msgs and returns the simulation result and the gas used, multiplied by the gas adjustment.
 func (cc *ChainClient) CalculateGas(ctx context.Context, txf tx.Factory, msgs ...sdk.Msg) (txtypes.SimulateResponse, uint64, error) {
+	ctx, done := cc.instrument(ctx, telemetry.OperationCalculateGas, msgsMethod(msgs), cc.Config.RPCAddr)
+	simRes, gas, err := cc.calculateGas(ctx, txf, msgs...)
+	done(err)
+	return simRes, gas, err
+}
+
+func (cc *ChainClient) calculateGas(ctx context.Context, txf tx.Factory, msgs ...sdk.Msg) (txtypes.SimulateResponse, uint64, error) {
 	keyInfo, err := cc.Keybase.Key(cc.Config.Key)
 	if err != nil {
 		return txtypes.SimulateResponse{}, 0, err
 	}
 
 	var txBytes []byte
-	if err := retry.Do(func() error {
+	if err := cc.RetryPolicy().Do(ctx, func() error {
 		var err error
 		txBytes, err = BuildSimTx(keyInfo, txf, msgs...)
 		if err != nil {
 			return err
 		}
 		return nil
-	}, retry.Context(ctx), RtyAtt, RtyDel, RtyErr); err != nil {
+	}); err != nil {
 		return txtypes.SimulateResponse{}, 0, err
 	}
 
@@ -209,14 +224,14 @@ func (cc *ChainClient) CalculateGas(ctx context.Context, txf tx.Factory, msgs ..
 	}
 
 	var res abci.ResponseQuery
-	if err := retry.Do(func() error {
+	if err := cc.RetryPolicy().Do(ctx, func() error {
 		var err error
 		res, err = cc.QueryABCI(ctx, simQuery)
 		if err != nil {
 			return err
 		}
 		return nil
-	}, retry.Context(ctx), RtyAtt, RtyDel, RtyErr); err != nil {
+	}); err != nil {
 		return txtypes.SimulateResponse{}, 0, err
 	}
 
@@ -228,10 +243,19 @@ func (cc *ChainClient) CalculateGas(ctx context.Context, txf tx.Factory, msgs ..
 	return simRes, uint64(txf.GasAdjustment() * float64(simRes.GasInfo.GasUsed)), nil
 }
 
+// QueryABCI runs the ABCI query, verifying store queries against the light client if verification is enabled.
 func (cc *ChainClient) QueryABCI(ctx context.Context, req abci.RequestQuery) (abci.ResponseQuery, error) {
+	ctx, done := cc.instrument(ctx, telemetry.OperationQueryABCI, req.Path, cc.Config.RPCAddr)
+	res, err := cc.queryABCI(ctx, req)
+	done(err)
+	return res, err
+}
+
+func (cc *ChainClient) queryABCI(ctx context.Context, req abci.RequestQuery) (abci.ResponseQuery, error) {
+	verify := cc.Config.Verify && isQueryStoreWithProof(req.Path)
 	opts := rpcclient.ABCIQueryOptions{
 		Height: req.Height,
-		Prove:  req.Prove,
+		Prove:  req.Prove || verify,
 	}
 	result, err := cc.RPCClient.ABCIQueryWithOptions(ctx, req.Path, req.Data, opts)
 	if err != nil {
@@ -243,10 +267,13 @@ func (cc *ChainClient) QueryABCI(ctx context.Context, req abci.RequestQuery) (ab
 	}
 
 	// data from trusted node or subspace query doesn't need verification
-	if !opts.Prove || !isQueryStoreWithProof(req.Path) {
+	if !verify {
 		return result.Response, nil
 	}
 
+	if err := cc.verifyQuery(ctx, req, result.Response); err != nil {
+		return abci.ResponseQuery{}, err
+	}
 	return result.Response, nil
 }
 
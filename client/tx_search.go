@@ -0,0 +1,360 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cometbft/cometbft/libs/pubsub/query"
+	ctypes "github.com/cometbft/cometbft/rpc/core/types"
+)
+
+// TxQueryOperator is a comparison operator supported by the CometBFT event query language.
+type TxQueryOperator string
+
+const (
+	OpEqual        TxQueryOperator = "="
+	OpLess         TxQueryOperator = "<"
+	OpLessEqual    TxQueryOperator = "<="
+	OpGreater      TxQueryOperator = ">"
+	OpGreaterEqual TxQueryOperator = ">="
+	OpContains     TxQueryOperator = "CONTAINS"
+)
+
+// heightKey is the reserved event key holding the height of a transaction.
+const heightKey = "tx.height"
+
+// TxQuery builds a CometBFT tx_search query out of typed conditions which are joined with AND.
+// The zero value is an empty query; use String to render it.
+type TxQuery struct {
+	conditions []string
+	err        error
+}
+
+// NewTxQuery returns an empty TxQuery.
+func NewTxQuery() *TxQuery {
+	return &TxQuery{}
+}
+
+// Equals adds the condition key='value'.
+func (q *TxQuery) Equals(key, value string) *TxQuery {
+	return q.Compare(key, OpEqual, value)
+}
+
+// Contains adds the condition key CONTAINS 'value'.
+func (q *TxQuery) Contains(key, value string) *TxQuery {
+	return q.Compare(key, OpContains, value)
+}
+
+// Exists adds the condition key EXISTS.
+func (q *TxQuery) Exists(key string) *TxQuery {
+	if err := validateQueryKey(key); err != nil {
+		q.setErr(err)
+		return q
+	}
+	q.conditions = append(q.conditions, key+" EXISTS")
+	return q
+}
+
+// Compare adds the condition key <op> value. Strings are quoted; integers and floats are
+// compared numerically; time.Time values are compared as TIME.
+func (q *TxQuery) Compare(key string, op TxQueryOperator, value interface{}) *TxQuery {
+	if err := validateQueryKey(key); err != nil {
+		q.setErr(err)
+		return q
+	}
+
+	var operand string
+	switch v := value.(type) {
+	case string:
+		if strings.Contains(v, "'") {
+			q.setErr(fmt.Errorf("query value for %s must not contain a single quote: %q", key, v))
+			return q
+		}
+		operand = "'" + v + "'"
+	case int:
+		operand = strconv.Itoa(v)
+	case int64:
+		operand = strconv.FormatInt(v, 10)
+	case uint64:
+		operand = strconv.FormatUint(v, 10)
+	case float64:
+		operand = strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		operand = "TIME " + v.UTC().Format(time.RFC3339)
+	default:
+		q.setErr(fmt.Errorf("unsupported query value type %T for %s", value, key))
+		return q
+	}
+
+	if op == OpContains {
+		if _, ok := value.(string); !ok {
+			q.setErr(fmt.Errorf("CONTAINS requires a string value for %s", key))
+			return q
+		}
+	}
+
+	q.conditions = append(q.conditions, fmt.Sprintf("%s %s %s", key, op, operand))
+	return q
+}
+
+// Height restricts the query to transactions included at the given height.
+func (q *TxQuery) Height(height int64) *TxQuery {
+	return q.Compare(heightKey, OpEqual, height)
+}
+
+// MinHeight restricts the query to transactions included at or after the given height.
+func (q *TxQuery) MinHeight(height int64) *TxQuery {
+	return q.Compare(heightKey, OpGreaterEqual, height)
+}
+
+// MaxHeight restricts the query to transactions included at or before the given height.
+func (q *TxQuery) MaxHeight(height int64) *TxQuery {
+	return q.Compare(heightKey, OpLessEqual, height)
+}
+
+// HeightRange restricts the query to transactions included between min and max, inclusive.
+func (q *TxQuery) HeightRange(min, max int64) *TxQuery {
+	if min > max {
+		q.setErr(fmt.Errorf("invalid height range: %d > %d", min, max))
+		return q
+	}
+	return q.MinHeight(min).MaxHeight(max)
+}
+
+// Raw adds pre-built conditions, e.g. "message.action='/cosmos.bank.v1beta1.MsgSend'".
+func (q *TxQuery) Raw(conditions ...string) *TxQuery {
+	q.conditions = append(q.conditions, conditions...)
+	return q
+}
+
+// Parse adds a condition written as key<op>value, e.g. "message.sender=cosmos1..." or
+// "tx.height>=100". Numeric values are compared numerically, anything else as a string.
+// Conditions that already contain quotes are added verbatim.
+func (q *TxQuery) Parse(expr string) *TxQuery {
+	if strings.Contains(expr, "'") || strings.Contains(expr, " EXISTS") || strings.Contains(expr, " CONTAINS ") {
+		return q.Raw(expr)
+	}
+
+	// Two character operators must be checked before their one character prefixes.
+	for _, op := range []TxQueryOperator{OpLessEqual, OpGreaterEqual, OpEqual, OpLess, OpGreater} {
+		idx := strings.Index(expr, string(op))
+		if idx <= 0 {
+			continue
+		}
+		key := strings.TrimSpace(expr[:idx])
+		value := strings.TrimSpace(expr[idx+len(op):])
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return q.Compare(key, op, n)
+		}
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return q.Compare(key, op, f)
+		}
+		return q.Compare(key, op, value)
+	}
+
+	q.setErr(fmt.Errorf("invalid query condition %q: expected key<op>value", expr))
+	return q
+}
+
+// Build renders the query and validates it against the CometBFT query grammar.
+func (q *TxQuery) Build() (string, error) {
+	if q.err != nil {
+		return "", q.err
+	}
+	if len(q.conditions) == 0 {
+		return "", errors.New("must declare at least one condition to search")
+	}
+
+	s := q.String()
+	if _, err := query.New(s); err != nil {
+		return "", fmt.Errorf("invalid tx query %q: %w", s, err)
+	}
+	return s, nil
+}
+
+// String renders the query without validation.
+func (q *TxQuery) String() string {
+	return strings.Join(q.conditions, " AND ")
+}
+
+func (q *TxQuery) setErr(err error) {
+	if q.err == nil {
+		q.err = err
+	}
+}
+
+func validateQueryKey(key string) error {
+	if key == "" || strings.ContainsAny(key, " '=<>") {
+		return fmt.Errorf("invalid query key %q", key)
+	}
+	return nil
+}
+
+// TxOrder is the order in which tx search results are returned.
+type TxOrder string
+
+const (
+	TxOrderAsc  TxOrder = "asc"
+	TxOrderDesc TxOrder = "desc"
+)
+
+// maxTxSearchPerPage is the largest page size accepted by CometBFT's tx_search.
+const maxTxSearchPerPage = 100
+
+// TxSearchOptions configures a paginated tx search.
+type TxSearchOptions struct {
+	// PerPage is the number of results requested per page. Defaults to (and is capped at) 100.
+	PerPage int
+	// OrderBy sorts results by height. Defaults to ascending, which is also the only order
+	// that is stable while new blocks are being committed.
+	OrderBy TxOrder
+	// Prove requests merkle proofs of inclusion for each transaction.
+	Prove bool
+	// MaxResults stops the iteration after this many results. Zero means no limit.
+	MaxResults int
+}
+
+// TxSearchIterator iterates over every page of a tx search.
+//
+//	it := cc.SearchTxs(txQuery, client.TxSearchOptions{})
+//	for it.Next(ctx) {
+//		tx := it.Tx()
+//	}
+//	if err := it.Err(); err != nil { ... }
+type TxSearchIterator struct {
+	cc    *ChainClient
+	query string
+	opts  TxSearchOptions
+
+	page    int
+	buf     []*ctypes.ResultTx
+	cur     *ctypes.ResultTx
+	total   int
+	fetched int
+	emitted int
+	done    bool
+	err     error
+}
+
+// SearchTxs returns an iterator over all transactions matching the query.
+// The query is typically built with TxQuery.Build.
+func (cc *ChainClient) SearchTxs(txQuery string, opts TxSearchOptions) *TxSearchIterator {
+	if opts.PerPage <= 0 || opts.PerPage > maxTxSearchPerPage {
+		opts.PerPage = maxTxSearchPerPage
+	}
+	if opts.OrderBy == "" {
+		opts.OrderBy = TxOrderAsc
+	}
+	return &TxSearchIterator{cc: cc, query: txQuery, opts: opts, total: -1}
+}
+
+// Next advances to the next transaction, fetching the next page when needed.
+// It returns false when all results have been consumed or an error occurred.
+func (it *TxSearchIterator) Next(ctx context.Context) bool {
+	if it.err != nil || (it.opts.MaxResults > 0 && it.emitted >= it.opts.MaxResults) {
+		return false
+	}
+
+	if len(it.buf) == 0 {
+		if it.done {
+			return false
+		}
+		if err := it.fetch(ctx); err != nil {
+			it.err = err
+			return false
+		}
+		if len(it.buf) == 0 {
+			return false
+		}
+	}
+
+	it.cur, it.buf = it.buf[0], it.buf[1:]
+	it.emitted++
+	return true
+}
+
+func (it *TxSearchIterator) fetch(ctx context.Context) error {
+	it.page++
+	page, perPage := it.page, it.opts.PerPage
+	res, err := it.cc.RPCClient.TxSearch(ctx, it.query, it.opts.Prove, &page, &perPage, string(it.opts.OrderBy))
+	if err != nil {
+		return err
+	}
+
+	it.total = res.TotalCount
+	it.fetched += len(res.Txs)
+	it.buf = res.Txs
+	if len(res.Txs) < perPage || it.fetched >= res.TotalCount {
+		it.done = true
+	}
+	return nil
+}
+
+// Tx returns the current transaction. It is only valid after Next returned true.
+func (it *TxSearchIterator) Tx() *ctypes.ResultTx {
+	return it.cur
+}
+
+// Total returns the total number of matching transactions reported by the node,
+// or -1 if no page has been fetched yet.
+func (it *TxSearchIterator) Total() int {
+	return it.total
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *TxSearchIterator) Err() error {
+	return it.err
+}
+
+// StreamTxs runs a paginated tx search in a goroutine and sends every result on the returned channel.
+// The result channel is closed when the search completes; any error is sent on the error channel,
+// which is closed afterwards.
+func (cc *ChainClient) StreamTxs(ctx context.Context, txQuery string, opts TxSearchOptions) (<-chan *ctypes.ResultTx, <-chan error) {
+	out := make(chan *ctypes.ResultTx)
+	errCh := make(chan error, 1)
+	go func() {
+		defer close(errCh)
+		defer close(out)
+
+		it := cc.SearchTxs(txQuery, opts)
+		for it.Next(ctx) {
+			select {
+			case out <- it.Tx():
+			case <-ctx.Done():
+				errCh <- ctx.Err()
+				return
+			}
+		}
+		if err := it.Err(); err != nil {
+			errCh <- err
+		}
+	}()
+	return out, errCh
+}
+
+// QueryTxPage returns a single page (1-indexed) of transactions matching the query.
+func (cc *ChainClient) QueryTxPage(ctx context.Context, txQuery string, page int, opts TxSearchOptions) ([]*ctypes.ResultTx, error) {
+	if page <= 0 {
+		return nil, errors.New("page must greater than 0")
+	}
+	it := cc.SearchTxs(txQuery, opts)
+	it.page = page - 1
+	if err := it.fetch(ctx); err != nil {
+		return nil, err
+	}
+	return it.buf, nil
+}
+
+// QueryAllTxs collects every transaction matching the query across all pages.
+func (cc *ChainClient) QueryAllTxs(ctx context.Context, txQuery string, opts TxSearchOptions) ([]*ctypes.ResultTx, error) {
+	var txs []*ctypes.ResultTx
+	it := cc.SearchTxs(txQuery, opts)
+	for it.Next(ctx) {
+		txs = append(txs, it.Tx())
+	}
+	return txs, it.Err()
+}
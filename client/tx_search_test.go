@@ -0,0 +1,257 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/KyleMoser/cosmos-client/client"
+	rpcclient "github.com/cometbft/cometbft/rpc/client"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxQueryBuild(t *testing.T) {
+	testCases := map[string]struct {
+		query       *client.TxQuery
+		expected    string
+		expectedErr bool
+	}{
+		"equality": {
+			query:    client.NewTxQuery().Equals("message.sender", "cosmos1abc"),
+			expected: "message.sender = 'cosmos1abc'",
+		},
+		"height range": {
+			query:    client.NewTxQuery().Equals("message.action", "/cosmos.bank.v1beta1.MsgSend").HeightRange(10, 20),
+			expected: "message.action = '/cosmos.bank.v1beta1.MsgSend' AND tx.height >= 10 AND tx.height <= 20",
+		},
+		"parsed conditions": {
+			query:    client.NewTxQuery().Parse("transfer.recipient=cosmos1abc").Parse("tx.height>5"),
+			expected: "transfer.recipient = 'cosmos1abc' AND tx.height > 5",
+		},
+		"raw conditions": {
+			query:    client.NewTxQuery().Raw("message.module='bank'").Parse("tx.height<=100"),
+			expected: "message.module='bank' AND tx.height <= 100",
+		},
+		"time comparison": {
+			query:    client.NewTxQuery().Compare("tx.time", client.OpGreaterEqual, time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)),
+			expected: "tx.time >= TIME 2023-01-02T03:04:05Z",
+		},
+		"exists and contains": {
+			query:    client.NewTxQuery().Exists("ibc_transfer.sender").Contains("message.action", "Transfer"),
+			expected: "ibc_transfer.sender EXISTS AND message.action CONTAINS 'Transfer'",
+		},
+		"empty query": {
+			query:       client.NewTxQuery(),
+			expectedErr: true,
+		},
+		"invalid height range": {
+			query:       client.NewTxQuery().HeightRange(20, 10),
+			expectedErr: true,
+		},
+		"quote in value": {
+			query:       client.NewTxQuery().Equals("message.sender", "it's"),
+			expectedErr: true,
+		},
+		"unparseable condition": {
+			query:       client.NewTxQuery().Parse("message.sender"),
+			expectedErr: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			q, err := tc.query.Build()
+			if tc.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, q)
+		})
+	}
+}
+
+// txSearchRPC serves a tx search of n txs, at heights 1 to n, and rejects pages out of range like CometBFT.
+type txSearchRPC struct {
+	rpcclient.Client
+	n     int
+	pages []int
+	err   error
+}
+
+func (r *txSearchRPC) TxSearch(_ context.Context, _ string, _ bool, page, perPage *int, _ string) (*coretypes.ResultTxSearch, error) {
+	r.pages = append(r.pages, *page)
+	if r.err != nil {
+		return nil, r.err
+	}
+	pages := (r.n + *perPage - 1) / *perPage
+	if pages == 0 {
+		pages = 1
+	}
+	if *page < 1 || *page > pages {
+		return nil, fmt.Errorf("page should be within [1, %d] range, given %d", pages, *page)
+	}
+
+	res := &coretypes.ResultTxSearch{TotalCount: r.n}
+	for i := (*page - 1) * *perPage; i < r.n && i < *page**perPage; i++ {
+		res.Txs = append(res.Txs, &coretypes.ResultTx{Height: int64(i + 1)})
+	}
+	return res, nil
+}
+
+func heights(txs []*coretypes.ResultTx) []int64 {
+	out := make([]int64, len(txs))
+	for i, tx := range txs {
+		out[i] = tx.Height
+	}
+	return out
+}
+
+func heightRange(n int) []int64 {
+	out := make([]int64, n)
+	for i := range out {
+		out[i] = int64(i + 1)
+	}
+	return out
+}
+
+func TestTxSearchIterator(t *testing.T) {
+	testCases := map[string]struct {
+		n             int
+		opts          client.TxSearchOptions
+		expected      []int64
+		expectedPages []int
+	}{
+		"empty result": {
+			n:             0,
+			expected:      []int64{},
+			expectedPages: []int{1},
+		},
+		"single page": {
+			n:             5,
+			expected:      heightRange(5),
+			expectedPages: []int{1},
+		},
+		"partial last page": {
+			n:             25,
+			opts:          client.TxSearchOptions{PerPage: 10},
+			expected:      heightRange(25),
+			expectedPages: []int{1, 2, 3},
+		},
+		"full last page": {
+			n:             20,
+			opts:          client.TxSearchOptions{PerPage: 10},
+			expected:      heightRange(20),
+			expectedPages: []int{1, 2},
+		},
+		"max results": {
+			n:             25,
+			opts:          client.TxSearchOptions{PerPage: 10, MaxResults: 12},
+			expected:      heightRange(12),
+			expectedPages: []int{1, 2},
+		},
+		"per page capped": {
+			n:             150,
+			opts:          client.TxSearchOptions{PerPage: 1000},
+			expected:      heightRange(150),
+			expectedPages: []int{1, 2},
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			rpc := &txSearchRPC{n: tc.n}
+			cc := &client.ChainClient{RPCClient: rpc}
+
+			it := cc.SearchTxs("tx.height > 0", tc.opts)
+			require.Equal(t, -1, it.Total())
+			txs := []*coretypes.ResultTx{}
+			for it.Next(context.Background()) {
+				txs = append(txs, it.Tx())
+			}
+			require.NoError(t, it.Err())
+			require.Equal(t, tc.expected, heights(txs))
+			require.Equal(t, tc.n, it.Total())
+			require.Equal(t, tc.expectedPages, rpc.pages)
+
+			// Iteration is over once Next returned false.
+			require.False(t, it.Next(context.Background()))
+			require.Equal(t, tc.expectedPages, rpc.pages)
+		})
+	}
+}
+
+func TestTxSearchIteratorError(t *testing.T) {
+	cc := &client.ChainClient{RPCClient: &txSearchRPC{err: errors.New("node unavailable")}}
+	it := cc.SearchTxs("tx.height > 0", client.TxSearchOptions{})
+	require.False(t, it.Next(context.Background()))
+	require.ErrorContains(t, it.Err(), "node unavailable")
+}
+
+func TestQueryAllTxs(t *testing.T) {
+	rpc := &txSearchRPC{n: 25}
+	cc := &client.ChainClient{RPCClient: rpc}
+	txs, err := cc.QueryAllTxs(context.Background(), "tx.height > 0", client.TxSearchOptions{PerPage: 10})
+	require.NoError(t, err)
+	require.Equal(t, heightRange(25), heights(txs))
+	require.Equal(t, []int{1, 2, 3}, rpc.pages)
+
+	cc.RPCClient = &txSearchRPC{n: 0}
+	txs, err = cc.QueryAllTxs(context.Background(), "tx.height > 0", client.TxSearchOptions{})
+	require.NoError(t, err)
+	require.Empty(t, txs)
+}
+
+func TestQueryTxPage(t *testing.T) {
+	cc := &client.ChainClient{RPCClient: &txSearchRPC{n: 25}}
+	txs, err := cc.QueryTxPage(context.Background(), "tx.height > 0", 3, client.TxSearchOptions{PerPage: 10})
+	require.NoError(t, err)
+	require.Equal(t, []int64{21, 22, 23, 24, 25}, heights(txs))
+
+	_, err = cc.QueryTxPage(context.Background(), "tx.height > 0", 4, client.TxSearchOptions{PerPage: 10})
+	require.Error(t, err)
+	_, err = cc.QueryTxPage(context.Background(), "tx.height > 0", 0, client.TxSearchOptions{})
+	require.Error(t, err)
+}
+
+func TestStreamTxs(t *testing.T) {
+	cc := &client.ChainClient{RPCClient: &txSearchRPC{n: 25}}
+	out, errCh := cc.StreamTxs(context.Background(), "tx.height > 0", client.TxSearchOptions{PerPage: 10})
+	var txs []*coretypes.ResultTx
+	for tx := range out {
+		txs = append(txs, tx)
+	}
+	require.NoError(t, <-errCh)
+	require.Equal(t, heightRange(25), heights(txs))
+
+	t.Run("empty result", func(t *testing.T) {
+		cc := &client.ChainClient{RPCClient: &txSearchRPC{n: 0}}
+		out, errCh := cc.StreamTxs(context.Background(), "tx.height > 0", client.TxSearchOptions{})
+		_, ok := <-out
+		require.False(t, ok)
+		require.NoError(t, <-errCh)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		cc := &client.ChainClient{RPCClient: &txSearchRPC{err: errors.New("node unavailable")}}
+		out, errCh := cc.StreamTxs(context.Background(), "tx.height > 0", client.TxSearchOptions{})
+		_, ok := <-out
+		require.False(t, ok)
+		require.ErrorContains(t, <-errCh, "node unavailable")
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		out, errCh := cc.StreamTxs(ctx, "tx.height > 0", client.TxSearchOptions{PerPage: 10})
+		<-out
+		cancel()
+		require.ErrorIs(t, <-errCh, context.Canceled)
+		_, ok := <-out
+		require.False(t, ok)
+	})
+}
@@ -1,11 +1,15 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path"
+	"strings"
+	"sync"
 
 	"github.com/KyleMoser/cosmos-client/client"
+	"github.com/KyleMoser/cosmos-client/client/chain_registry"
 	"github.com/cosmos/cosmos-sdk/types/module"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -45,9 +49,27 @@ func (a *appState) OverwriteConfig(cfg *configService) error {
 }
 
 func (a *appState) Initialize(home string, logger *zap.Logger, cmd *cobra.Command, o map[string]ClientOverrides) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	// Every client looks up IBC paths and asset lists in the selected registry rather than GitHub.
+	// The registry is only built when a client first uses it, so commands that never touch the
+	// registry do not clone it and are not broken by a misconfigured registry.
+	registry := &lazyRegistry{build: func() (chain_registry.ChainRegistry, error) {
+		return a.ChainRegistry(ctx)
+	}}
+
 	a.cl = make(map[string]*client.ChainClient)
 	for name, chain := range a.GetChainConfigs() {
 		chain.Modules = append([]module.AppModuleBasic{}, ModuleBasics...)
+		// The chain name is not stored in the config file, where chains are keyed by their registry name.
+		if chain.ChainName == "" {
+			chain.ChainName = name
+		}
+		if a.Viper.GetString(flagMetricsAddr) != "" {
+			chain.Metrics = true
+		}
 		cl, err := client.NewChainClient(
 			logger.With(zap.String("chain", name)),
 			chain,
@@ -58,6 +80,7 @@ func (a *appState) Initialize(home string, logger *zap.Logger, cmd *cobra.Comman
 		if err != nil {
 			return fmt.Errorf("error creating chain client: %w", err)
 		}
+		cl.Registry = registry
 		// If overrides are present (should only happen in test), modify the client to use those overrides.
 		if o != nil {
 			if rc := o[name].RPCClient; rc != nil {
@@ -114,3 +137,180 @@ func (a *appState) GetClient(chainID string) *client.ChainClient {
 	}
 	return nil
 }
+
+// ChainRegistry returns the chain registry selected by the --registry-path or --registry flags.
+// Without either flag, every registry in the config file is layered in order of precedence.
+// Remote registries are cached on disk unless caching is disabled in the config.
+func (a *appState) ChainRegistry(ctx context.Context) (chain_registry.ChainRegistry, error) {
+	cfgs, err := a.registryConfigs()
+	if err != nil {
+		return nil, err
+	}
+
+	registries := make([]chain_registry.ChainRegistry, 0, len(cfgs))
+	for _, cfg := range cfgs {
+		registry, err := chain_registry.NewChainRegistry(ctx, a.Log, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("registry %s: %w", cfg.GetName(), err)
+		}
+		if cfg.IsRemote() && !cfg.DisableCache {
+			if registry, err = a.cachedRegistry(registry, cfg); err != nil {
+				return nil, err
+			}
+		}
+		registries = append(registries, registry)
+	}
+
+	if len(registries) == 1 {
+		return registries[0], nil
+	}
+	return chain_registry.NewLayeredRegistry(registries...), nil
+}
+
+// RegistryCaches returns the on-disk caches of the selected remote chain registries.
+func (a *appState) RegistryCaches(ctx context.Context) ([]*chain_registry.CachedRegistry, error) {
+	cfgs, err := a.registryConfigs()
+	if err != nil {
+		return nil, err
+	}
+
+	var caches []*chain_registry.CachedRegistry
+	for _, cfg := range cfgs {
+		if !cfg.IsRemote() {
+			continue
+		}
+		registry, err := chain_registry.NewChainRegistry(ctx, a.Log, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("registry %s: %w", cfg.GetName(), err)
+		}
+		cache, err := a.cachedRegistry(registry, cfg)
+		if err != nil {
+			return nil, err
+		}
+		caches = append(caches, cache)
+	}
+	return caches, nil
+}
+
+// registryConfigs returns the configs of the selected chain registries, in order of precedence.
+func (a *appState) registryConfigs() ([]chain_registry.RegistryConfig, error) {
+	if path := a.Viper.GetString(flagRegistryPath); path != "" {
+		return []chain_registry.RegistryConfig{{Type: chain_registry.RegistryTypeLocal, Path: path}}, nil
+	}
+
+	var cfgs []chain_registry.RegistryConfig
+	if a.Config.clientConfig != nil {
+		cfgs = a.Config.clientConfig.Registries
+	}
+	if len(cfgs) == 0 {
+		cfgs = []chain_registry.RegistryConfig{{Type: chain_registry.RegistryTypeGithub}}
+	}
+
+	name := a.Viper.GetString(flagRegistry)
+	if name == "" {
+		return cfgs, nil
+	}
+	names := make([]string, 0, len(cfgs))
+	for _, cfg := range cfgs {
+		if cfg.GetName() == name {
+			return []chain_registry.RegistryConfig{cfg}, nil
+		}
+		names = append(names, cfg.GetName())
+	}
+	return nil, fmt.Errorf("registry %s not found in configuration; available registries are: %s", name, strings.Join(names, ", "))
+}
+
+// lazyRegistry is a chain registry built on first use.
+type lazyRegistry struct {
+	build    func() (chain_registry.ChainRegistry, error)
+	once     sync.Once
+	registry chain_registry.ChainRegistry
+	err      error
+}
+
+func (l *lazyRegistry) get() (chain_registry.ChainRegistry, error) {
+	l.once.Do(func() {
+		l.registry, l.err = l.build()
+		if l.err != nil {
+			l.err = fmt.Errorf("error creating chain registry: %w", l.err)
+		}
+	})
+	return l.registry, l.err
+}
+
+func (l *lazyRegistry) GetChain(name string) (chain_registry.ChainInfo, error) {
+	registry, err := l.get()
+	if err != nil {
+		return chain_registry.ChainInfo{}, err
+	}
+	return registry.GetChain(name)
+}
+
+func (l *lazyRegistry) ListChains(ctx context.Context) ([]string, error) {
+	registry, err := l.get()
+	if err != nil {
+		return nil, err
+	}
+	return registry.ListChains(ctx)
+}
+
+func (l *lazyRegistry) GetAssetList(name string) (chain_registry.AssetList, error) {
+	registry, err := l.get()
+	if err != nil {
+		return chain_registry.AssetList{}, err
+	}
+	return registry.GetAssetList(name)
+}
+
+func (l *lazyRegistry) GetIbcConfig(chainA, chainB string) (chain_registry.IbcConfig, error) {
+	registry, err := l.get()
+	if err != nil {
+		return chain_registry.IbcConfig{}, err
+	}
+	return registry.GetIbcConfig(chainA, chainB)
+}
+
+func (l *lazyRegistry) ListIbcPaths(ctx context.Context) ([]chain_registry.IbcPath, error) {
+	registry, err := l.get()
+	if err != nil {
+		return nil, err
+	}
+	return registry.ListIbcPaths(ctx)
+}
+
+// SourceLink returns an empty link if the registry cannot be built.
+func (l *lazyRegistry) SourceLink() string {
+	registry, err := l.get()
+	if err != nil {
+		return ""
+	}
+	return registry.SourceLink()
+}
+
+func (a *appState) cachedRegistry(registry chain_registry.ChainRegistry, cfg chain_registry.RegistryConfig) (*chain_registry.CachedRegistry, error) {
+	ttl, err := cfg.GetCacheTTL()
+	if err != nil {
+		return nil, err
+	}
+	dir := path.Join(a.Viper.GetString("home"), "cache", "registry", cfg.GetName())
+	return chain_registry.NewCachedRegistry(a.Log.With(zap.String("registry", cfg.GetName())), registry, dir, ttl), nil
+}
+
+// DenomResolver returns a resolver for the denoms of the given client's chain, built from the chain's
+// asset list in the selected chain registry. If the asset list is unavailable, only IBC denom traces
+// queried from the chain are resolved.
+func (a *appState) DenomResolver(ctx context.Context, cl *client.ChainClient) *chain_registry.DenomResolver {
+	var assetList chain_registry.AssetList
+	registry, err := a.ChainRegistry(ctx)
+	if err == nil {
+		assetList, err = registry.GetAssetList(cl.Config.ChainName)
+	}
+	if err != nil {
+		a.Log.Warn(
+			"Failed to get asset list, display units are unavailable",
+			zap.String("chain_name", cl.Config.ChainName),
+			zap.Error(err),
+		)
+	}
+	return chain_registry.NewDenomResolver(assetList)
+}
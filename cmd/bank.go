@@ -93,12 +93,30 @@ func bankBalanceCmd(a *appState) *cobra.Command {
 			if err != nil {
 				return err
 			}
-			return cl.PrintObject(balance)
+
+			human, err := cmd.Flags().GetBool(flagHuman)
+			if err != nil {
+				return err
+			}
+			if !human {
+				return cl.PrintObject(balance)
+			}
+
+			resolver := a.DenomResolver(cmd.Context(), cl)
+			coins := cl.HumanizeCoins(cmd.Context(), resolver, balance.Balances)
+			if cl.Config.OutputFormat != outputTable {
+				return cl.PrintObject(coins)
+			}
+			rows := make([][]string, 0, len(coins))
+			for _, c := range coins {
+				rows = append(rows, []string{c.DisplayAmount, c.Symbol, c.Denom, c.BaseDenom, c.OriginChain})
+			}
+			return writeTable(cmd.OutOrStdout(), []string{"AMOUNT", "SYMBOL", "DENOM", "BASE DENOM", "ORIGIN"}, rows)
 		},
 	}
 	flags.AddQueryFlagsToCmd(cmd)
 	flags.AddPaginationFlagsToCmd(cmd, "balance")
-	return cmd
+	return humanFlag(cmd, a.Viper)
 }
 
 func bankTotalSupplyCmd(a *appState) *cobra.Command {
@@ -5,9 +5,11 @@ import (
 	"os"
 	"os/exec"
 	"path"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/KyleMoser/cosmos-client/client"
 	"github.com/KyleMoser/cosmos-client/client/chain_registry"
@@ -30,6 +32,8 @@ func chainsCmd(a *appState) *cobra.Command {
 		cmdChainsShow(a),
 		cmdChainsSetDefault(a),
 		cmdChainsRegistryList(a),
+		cmdChainsEndpoints(a),
+		cmdChainsSync(a),
 		cmdChainsShowDefault(a),
 		cmdChainsEditorDefault(),
 	)
@@ -44,7 +48,11 @@ func cmdChainsRegistryList(a *appState) *cobra.Command {
 		Aliases: []string{"rl"},
 		Short:   "list chains available for configuration from the registry",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			chains, err := chain_registry.DefaultChainRegistry(a.Log).ListChains(cmd.Context())
+			registry, err := a.ChainRegistry(cmd.Context())
+			if err != nil {
+				return err
+			}
+			chains, err := registry.ListChains(cmd.Context())
 			if err != nil {
 				return err
 			}
@@ -54,14 +62,109 @@ func cmdChainsRegistryList(a *appState) *cobra.Command {
 	return cmd
 }
 
+func cmdChainsEndpoints(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "endpoints [chain-name]",
+		Args:    cobra.RangeArgs(0, 1),
+		Aliases: []string{"ep"},
+		Short:   "probe and rank the RPC endpoints of a chain from the registry",
+		Long: `Probe every RPC endpoint the chain registry lists for a chain, measuring latency, the earliest
+available block (to tell archive nodes from pruned nodes), tx indexing and version. Healthy endpoints
+are ranked by --policy; unhealthy endpoints are listed last. Defaults to the chain of the default client.`,
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s chains endpoints osmosis
+$ %s chains endpoints cosmoshub --policy archive -o table`, appName, appName)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			policy, err := chain_registry.ParseSelectionPolicy(a.Viper.GetString(flagPolicy))
+			if err != nil {
+				return err
+			}
+
+			cl := a.GetDefaultClient()
+			var chainName string
+			if len(args) == 1 {
+				chainName = args[0]
+			} else if cl != nil {
+				chainName = cl.Config.ChainName
+			}
+			if chainName == "" {
+				return fmt.Errorf("no chain-name provided and there is no default chain with a registry name")
+			}
+
+			registry, err := a.ChainRegistry(cmd.Context())
+			if err != nil {
+				return err
+			}
+			chainInfo, err := registry.GetChain(chainName)
+			if err != nil {
+				return err
+			}
+			probes, err := chainInfo.ProbeRPCEndpoints(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			ranked := chain_registry.RankEndpoints(probes, policy)
+			for _, probe := range probes {
+				if !probe.Healthy || (policy == chain_registry.PolicyTxIndex && !probe.TxIndex) {
+					ranked = append(ranked, probe)
+				}
+			}
+
+			if cl != nil && cl.Config.OutputFormat != outputTable {
+				return cl.PrintObject(ranked)
+			}
+			rows := make([][]string, 0, len(ranked))
+			for _, p := range ranked {
+				rows = append(rows, []string{
+					p.Endpoint,
+					p.Provider,
+					fmt.Sprint(p.Healthy),
+					p.Latency.Round(time.Millisecond).String(),
+					strconv.FormatInt(p.LatestHeight, 10),
+					strconv.FormatInt(p.EarliestHeight, 10),
+					fmt.Sprint(p.Archive),
+					fmt.Sprint(p.TxIndex),
+					p.Version,
+					p.Error,
+				})
+			}
+			return writeTable(cmd.OutOrStdout(), []string{"ENDPOINT", "PROVIDER", "HEALTHY", "LATENCY", "LATEST", "EARLIEST", "ARCHIVE", "TX INDEX", "VERSION", "ERROR"}, rows)
+		},
+	}
+	return policyFlag(cmd, a.Viper)
+}
+
 func cmdChainsAdd(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "add [[chain-name]]",
 		Args:    cobra.MinimumNArgs(1),
 		Aliases: []string{"a"},
 		Short:   "add configuration for a chain or a number of chains from the chain registry",
+		Long: `Add configuration for chains from the chain registries in the config file. When several
+registries are configured, each chain is read from the first registry that has it, unless a registry
+is selected with --registry. With --endpoint-policy, the RPC endpoints are probed and the best one
+according to the policy is saved. With --fallback-endpoints, other healthy RPC endpoints are saved
+as rpc-addrs, which the client fails over to and balances load across.`,
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s chains add osmosis juno
+$ %s chains add --registry internal mychain
+$ %s chains add --registry-path ~/chain-registry osmosis
+$ %s chains add --endpoint-policy archive osmosis
+$ %s chains add --fallback-endpoints 2 osmosis`, appName, appName, appName, appName, appName)),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			registry := chain_registry.DefaultChainRegistry(a.Log)
+			var policy chain_registry.SelectionPolicy
+			if name := a.Viper.GetString(flagEndpointPolicy); name != "" {
+				var err error
+				if policy, err = chain_registry.ParseSelectionPolicy(name); err != nil {
+					return err
+				}
+			}
+
+			registry, err := a.ChainRegistry(cmd.Context())
+			if err != nil {
+				return err
+			}
 			overwriteConfig := false
 
 			for _, chain := range args {
@@ -75,7 +178,10 @@ func cmdChainsAdd(a *appState) *cobra.Command {
 					continue
 				}
 
-				chainConfig, err := client.GetChainConfigWithOpts(cmd.Context(), chainInfo, nil)
+				chainConfig, err := client.GetChainConfigWithOpts(cmd.Context(), chainInfo, &client.ChainConfigOptions{
+					EndpointPolicy:       policy,
+					FallbackRPCEndpoints: a.Viper.GetInt(flagFallback),
+				})
 				if err != nil {
 					a.Log.Info(
 						"Failed to generate chain config",
@@ -94,7 +200,103 @@ func cmdChainsAdd(a *appState) *cobra.Command {
 			}
 		},
 	}
-	return cmd
+	return endpointPolicyFlag(fallbackEndpointsFlag(cmd, a.Viper), a.Viper)
+}
+
+func cmdChainsSync(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "sync [[chain-name]]",
+		Aliases: []string{"sy"},
+		Short:   "update chain configurations from the chain registry",
+		Long: `Compare the configuration of chains with the chain registry and apply the differences. Without
+arguments, every configured chain is synced. Only fields that come from the registry are synced: the chain ID,
+RPC, fallback RPC and gRPC addresses, account prefix, slip44, gas prices, extra codecs and key algorithms. The RPC
+and gRPC addresses are only replaced when they are no longer listed in the registry, and fallback RPC addresses that
+are no longer listed are removed. Extra codecs and key algorithms are kept if the registry lists none. Fields such
+as the key and keyring backend are never changed. Use --fields to apply only some of the changes.`,
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s chains sync --dry-run
+$ %s chains sync osmosis --fields rpc-addr,gas-prices -y`, appName, appName)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fields := a.Viper.GetStringSlice(flagFields)
+			for _, f := range fields {
+				if !slices.Contains(client.SyncFields, f) {
+					return fmt.Errorf("unknown field %q, expected one of %s", f, strings.Join(client.SyncFields, ", "))
+				}
+			}
+
+			configs := a.Config.clientConfig.GetChainConfigs()
+			names := args
+			if len(names) == 0 {
+				for name := range configs {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+			}
+
+			registry, err := a.ChainRegistry(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			changes := map[string][]client.ConfigChange{}
+			var rows [][]string
+			for _, name := range names {
+				saved, ok := configs[name]
+				if !ok {
+					return fmt.Errorf("chain %s not found", name)
+				}
+				chainName := saved.ChainName
+				if chainName == "" {
+					chainName = name
+				}
+				chainInfo, err := registry.GetChain(chainName)
+				if err != nil {
+					return fmt.Errorf("failed to get chain %s from the registry: %w", chainName, err)
+				}
+				diff, err := client.DiffChainConfig(cmd.Context(), saved, chainInfo, &client.ChainConfigOptions{Registry: registry})
+				if err != nil {
+					return fmt.Errorf("failed to diff chain %s: %w", name, err)
+				}
+				for _, change := range diff {
+					if len(fields) > 0 && !slices.Contains(fields, change.Field) {
+						continue
+					}
+					changes[name] = append(changes[name], change)
+					rows = append(rows, []string{name, change.Field, change.Old, change.New})
+				}
+			}
+
+			if len(rows) == 0 {
+				fmt.Fprintln(cmd.ErrOrStderr(), "chain configurations are up to date")
+				return nil
+			}
+			if cl := a.GetDefaultClient(); cl != nil && cl.Config.OutputFormat != outputTable {
+				if err := cl.PrintObject(changes); err != nil {
+					return err
+				}
+			} else if err := writeTable(cmd.OutOrStdout(), []string{"CHAIN", "FIELD", "OLD", "NEW"}, rows); err != nil {
+				return err
+			}
+			if a.Viper.GetBool(flagDryRun) {
+				return nil
+			}
+
+			if skip, _ := cmd.Flags().GetBool("skip"); !skip {
+				fmt.Fprintf(cmd.OutOrStdout(), "Apply %d changes? (Y/n)\n", len(rows))
+				if !askForConfirmation(a.Log, cmd) {
+					return nil
+				}
+			}
+
+			for name, chainChanges := range changes {
+				client.ApplyConfigChanges(configs[name], chainChanges)
+				a.Config.clientConfig.SetChainConfig(name, configs[name])
+			}
+			return a.OverwriteConfig(a.Config)
+		},
+	}
+	return chainsSyncFlags(cmd, a.Viper)
 }
 
 func cmdChainsDelete(a *appState) *cobra.Command {
@@ -146,6 +348,11 @@ func cmdChainsEdit(a *appState) *cobra.Command {
 				conf.RPCAddr = args[2]
 			case "grpc-addr":
 				conf.GRPCAddr = args[2]
+			case "query-route":
+				if _, err := client.ParseQueryRoute(args[2]); err != nil {
+					return err
+				}
+				conf.QueryRoute = args[2]
 			case "account-prefix":
 				conf.AccountPrefix = args[2]
 			case "gas-adjustment":
@@ -170,8 +377,33 @@ func cmdChainsEdit(a *appState) *cobra.Command {
 				conf.Debug = b
 			case "timeout":
 				conf.Timeout = args[2]
+			case "verify":
+				b, err := strconv.ParseBool(args[2])
+				if err != nil {
+					return err
+				}
+				conf.Verify = b
+			case "trust-period":
+				conf.TrustPeriod = args[2]
+			case "trusted-height":
+				h, err := strconv.ParseInt(args[2], 10, 64)
+				if err != nil {
+					return err
+				}
+				conf.TrustedHeight = h
+			case "trusted-hash":
+				conf.TrustedHash = args[2]
+			case "batch-size":
+				n, err := strconv.Atoi(args[2])
+				if err != nil {
+					return err
+				}
+				if n < 0 {
+					return fmt.Errorf("invalid batch-size %d", n)
+				}
+				conf.BatchSize = n
 			default:
-				return fmt.Errorf("unknown key %s, try 'key', 'chain-id', 'rpc-addr', 'grpc-addr', 'account-prefix', 'gas-adjustment', 'gas-prices', 'min-gas-amount', 'debug', or 'timeout'", args[1])
+				return fmt.Errorf("unknown key %s, try 'key', 'chain-id', 'rpc-addr', 'grpc-addr', 'query-route', 'account-prefix', 'gas-adjustment', 'gas-prices', 'min-gas-amount', 'debug', 'timeout', 'verify', 'trust-period', 'trusted-height', 'trusted-hash', or 'batch-size'", args[1])
 			}
 			return a.OverwriteConfig(a.Config)
 		},
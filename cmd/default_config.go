@@ -5,6 +5,7 @@ import (
 	"path"
 
 	"github.com/KyleMoser/cosmos-client/client"
+	"github.com/KyleMoser/cosmos-client/client/chain_registry"
 )
 
 var _ configPart = (*CosmosClientConfig)(nil)
@@ -13,7 +14,10 @@ var _ configPart = (*CosmosClientConfig)(nil)
 type CosmosClientConfig struct {
 	DefaultChain string                               `yaml:"default_chain" json:"default_chain"`
 	Chains       map[string]*client.ChainClientConfig `yaml:"chains" json:"chains"`
-	Debug        bool
+	// Registries are the chain registries used by the chains commands, in order of precedence.
+	// Defaults to github.com/cosmos/chain-registry.
+	Registries []chain_registry.RegistryConfig `yaml:"registries,omitempty" json:"registries,omitempty"`
+	Debug      bool
 }
 
 func (c *CosmosClientConfig) SetChainConfig(name string, config *client.ChainClientConfig) {
@@ -40,6 +44,15 @@ func (c *CosmosClientConfig) ValidateConfig() error {
 	if c.GetDefaultChain() == "" {
 		return fmt.Errorf("default chain (%s) configuration not found", c.DefaultChain)
 	}
+	// Registries are selected and cached by name, which defaults to their type.
+	names := map[string]bool{}
+	for _, registry := range c.Registries {
+		name := registry.GetName()
+		if names[name] {
+			return fmt.Errorf("registry name %s is used by several registries, set a unique name for each registry", name)
+		}
+		names[name] = true
+	}
 	return nil
 }
 
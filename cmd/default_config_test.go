@@ -0,0 +1,22 @@
+package cmd_test
+
+import (
+	"testing"
+
+	"github.com/KyleMoser/cosmos-client/client/chain_registry"
+	"github.com/KyleMoser/cosmos-client/cmd"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateConfigRegistryNames(t *testing.T) {
+	conf := &cmd.CosmosClientConfig{DefaultChain: "cosmoshub"}
+	conf.Registries = []chain_registry.RegistryConfig{
+		{Type: chain_registry.RegistryTypeGithub},
+		{Type: chain_registry.RegistryTypeGithub, NetworkType: "testnet"},
+	}
+	// Both registries are named after their type, so they cannot be told apart.
+	require.ErrorContains(t, conf.ValidateConfig(), "registry name github")
+
+	conf.Registries[1].Name = "testnet"
+	require.NoError(t, conf.ValidateConfig())
+}
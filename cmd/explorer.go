@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KyleMoser/cosmos-client/client"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	"github.com/spf13/cobra"
+)
+
+const outputTable = "table"
+
+func blockQueryCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "block [height]",
+		Aliases: []string{"blk"},
+		Short:   "query a block (the latest block if no height is given) with every tx decoded",
+		Args:    withUsage(cobra.RangeArgs(0, 1)),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s query block
+$ %s query block 1234567 -o table
+$ %s q blk 1234567 -o yaml`, appName, appName, appName)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cl := a.GetDefaultClient()
+			var height int64
+			if len(args) == 1 {
+				var err error
+				height, err = strconv.ParseInt(args[0], 10, 64)
+				if err != nil {
+					return fmt.Errorf("invalid height %q: %w", args[0], err)
+				}
+			}
+
+			block, err := cl.QueryDecodedBlock(cmd.Context(), height)
+			if err != nil {
+				return err
+			}
+			if cl.Config.OutputFormat != outputTable {
+				return cl.PrintObject(block)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Chain:    %s\nHeight:   %d\nHash:     %s\nTime:     %s\nProposer: %s\nTxs:      %d\n\n",
+				block.ChainID, block.Height, block.Hash, block.Time.Format(time.RFC3339), block.Proposer, block.NumTxs)
+			rows := make([][]string, 0, len(block.Txs))
+			for _, tx := range block.Txs {
+				rows = append(rows, txTableRow(tx))
+			}
+			return writeTable(cmd.OutOrStdout(), txTableHeaders, rows)
+		},
+	}
+	return cmd
+}
+
+// maxBlocksQueryRange is the maximum number of blocks queried at once by the blocks command.
+const maxBlocksQueryRange = 1000
+
+func blocksQueryCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "blocks [from-height] [to-height]",
+		Short: "query a range of blocks with every tx decoded, fetched in batch requests",
+		Args:  withUsage(cobra.ExactArgs(2)),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s query blocks 1234500 1234567
+$ %s q blocks 1234500 1234567 -o table`, appName, appName)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cl := a.GetDefaultClient()
+			from, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid height %q: %w", args[0], err)
+			}
+			to, err := strconv.ParseInt(args[1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid height %q: %w", args[1], err)
+			}
+			if from < 1 || to < from {
+				return fmt.Errorf("invalid height range %d to %d", from, to)
+			}
+			if to-from+1 > maxBlocksQueryRange {
+				return fmt.Errorf("height range %d to %d exceeds the maximum of %d blocks", from, to, maxBlocksQueryRange)
+			}
+
+			heights := make([]int64, 0, to-from+1)
+			for h := from; h <= to; h++ {
+				heights = append(heights, h)
+			}
+			blocks, err := cl.QueryDecodedBlocks(cmd.Context(), heights)
+			if err != nil {
+				return err
+			}
+			if cl.Config.OutputFormat != outputTable {
+				return cl.PrintObject(blocks)
+			}
+
+			rows := make([][]string, 0, len(blocks))
+			for _, block := range blocks {
+				rows = append(rows, []string{
+					strconv.FormatInt(block.Height, 10),
+					block.Time.Format(time.RFC3339),
+					strconv.Itoa(block.NumTxs),
+					block.Proposer,
+					block.Hash,
+				})
+			}
+			return writeTable(cmd.OutOrStdout(), []string{"HEIGHT", "TIME", "TXS", "PROPOSER", "HASH"}, rows)
+		},
+	}
+	return cmd
+}
+
+func txQueryCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tx [hash]",
+		Short: "query a transaction by its hex encoded hash and decode it",
+		Args:  withUsage(cobra.ExactArgs(1)),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s query tx 3F2A...
+$ %s q tx 3F2A... -o table`, appName, appName)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cl := a.GetDefaultClient()
+			tx, err := cl.QueryDecodedTx(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			if cl.Config.OutputFormat != outputTable {
+				return cl.PrintObject(tx)
+			}
+			return writeTable(cmd.OutOrStdout(), txTableHeaders, [][]string{txTableRow(*tx)})
+		},
+	}
+	return cmd
+}
+
+func txsQueryCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "txs",
+		Short: "search for transactions matching event conditions",
+		Long: `Search for transactions matching event conditions. Conditions are given as key<op>value
+where op is one of =, <, <=, > or >=, and are joined with AND. Numeric values are compared
+numerically. Use --all to iterate over every page of results.`,
+		Args: withUsage(cobra.NoArgs),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s query txs --events message.sender=cosmos1... --all
+$ %s query txs --events message.action=/cosmos.bank.v1beta1.MsgSend --min-height 100 --max-height 200
+$ %s q txs --events transfer.recipient=cosmos1... --order-by desc --limit 10 -o table`, appName, appName, appName)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cl := a.GetDefaultClient()
+			flags := cmd.Flags()
+
+			events, err := flags.GetStringArray(flagEvents)
+			if err != nil {
+				return err
+			}
+			minHeight, err := flags.GetInt64(flagMinHeight)
+			if err != nil {
+				return err
+			}
+			maxHeight, err := flags.GetInt64(flagMaxHeight)
+			if err != nil {
+				return err
+			}
+
+			q := client.NewTxQuery()
+			for _, e := range events {
+				q.Parse(e)
+			}
+			if minHeight > 0 {
+				q.MinHeight(minHeight)
+			}
+			if maxHeight > 0 {
+				q.MaxHeight(maxHeight)
+			}
+			query, err := q.Build()
+			if err != nil {
+				return err
+			}
+
+			orderBy, err := flags.GetString(flagOrderBy)
+			if err != nil {
+				return err
+			}
+			if orderBy != string(client.TxOrderAsc) && orderBy != string(client.TxOrderDesc) {
+				return fmt.Errorf("invalid --%s %q: must be asc or desc", flagOrderBy, orderBy)
+			}
+			all, err := flags.GetBool(flagAll)
+			if err != nil {
+				return err
+			}
+			page, err := flags.GetInt("page")
+			if err != nil {
+				return err
+			}
+			limit, err := flags.GetInt("limit")
+			if err != nil {
+				return err
+			}
+
+			opts := client.TxSearchOptions{PerPage: limit, OrderBy: client.TxOrder(orderBy)}
+			var results []*coretypes.ResultTx
+			if all {
+				results, err = cl.QueryAllTxs(cmd.Context(), query, opts)
+			} else {
+				results, err = cl.QueryTxPage(cmd.Context(), query, page, opts)
+			}
+			if err != nil {
+				return err
+			}
+
+			txs := make([]client.DecodedTx, len(results))
+			for i, res := range results {
+				txs[i] = cl.DecodeResultTx(res)
+			}
+			if cl.Config.OutputFormat != outputTable {
+				return cl.PrintObject(txs)
+			}
+
+			rows := make([][]string, len(txs))
+			for i, tx := range txs {
+				rows[i] = txTableRow(tx)
+			}
+			return writeTable(cmd.OutOrStdout(), txTableHeaders, rows)
+		},
+	}
+	return txSearchFlags(cmd, a.Viper)
+}
+
+var txTableHeaders = []string{"HEIGHT", "INDEX", "HASH", "CODE", "GAS", "FEE", "SIGNERS", "MESSAGES"}
+
+func txTableRow(tx client.DecodedTx) []string {
+	messages := make([]string, len(tx.Messages))
+	for i, m := range tx.Messages {
+		// Only the message name is needed in a table, e.g. MsgSend instead of /cosmos.bank.v1beta1.MsgSend.
+		messages[i] = m[strings.LastIndex(m, ".")+1:]
+	}
+	if tx.DecodeError != "" {
+		messages = []string{"<undecodable: " + tx.DecodeError + ">"}
+	}
+	return []string{
+		strconv.FormatInt(tx.Height, 10),
+		strconv.FormatUint(uint64(tx.Index), 10),
+		tx.Hash,
+		strconv.FormatUint(uint64(tx.Code), 10),
+		fmt.Sprintf("%d/%d", tx.GasUsed, tx.GasWanted),
+		tx.Fee.String(),
+		strings.Join(tx.Signers, ","),
+		strings.Join(messages, ","),
+	}
+}
@@ -1,6 +1,10 @@
 package cmd
 
 import (
+	"strings"
+
+	"github.com/KyleMoser/cosmos-client/client"
+	"github.com/KyleMoser/cosmos-client/client/chain_registry"
 	"github.com/KyleMoser/cosmos-client/client/query"
 	"github.com/cosmos/cosmos-sdk/client/flags"
 	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
@@ -13,6 +17,28 @@ import (
 const (
 	gRPCSecureOnlyFlag = "secure-only"
 	flagMemo           = "memo"
+	flagSigner         = "signer"
+	flagMsgType        = "msg-type"
+	flagCount          = "count"
+	flagEvents         = "events"
+	flagAll            = "all"
+	flagOrderBy        = "order-by"
+	flagMinHeight      = "min-height"
+	flagMaxHeight      = "max-height"
+	flagRegistryPath   = "registry-path"
+	flagRegistry       = "registry"
+	flagHuman          = "human"
+	flagPolicy         = "policy"
+	flagReceiver       = "receiver"
+	flagMaxHops        = "max-hops"
+	flagTimeout        = "timeout"
+	flagFields         = "fields"
+	flagDryRun         = "dry-run"
+	flagOnline         = "online"
+	flagAddress        = "address"
+	flagFallback       = "fallback-endpoints"
+	flagEndpointPolicy = "endpoint-policy"
+	flagMetricsAddr    = "metrics-addr"
 )
 
 func peersFlag(cmd *cobra.Command, v *viper.Viper) *cobra.Command {
@@ -33,6 +59,100 @@ func limitFlag(cmd *cobra.Command, v *viper.Viper) *cobra.Command {
 	return cmd
 }
 
+func mempoolFlags(cmd *cobra.Command, v *viper.Viper) *cobra.Command {
+	cmd.Flags().String(flagSigner, "", "only show transactions signed by this key or address")
+	cmd.Flags().String(flagMsgType, "", "only show transactions containing this message type (e.g. MsgSend or /cosmos.bank.v1beta1.MsgSend)")
+	cmd.Flags().Bool(flagCount, false, "only return the number of pending transactions")
+	for _, f := range []string{flagSigner, flagMsgType, flagCount} {
+		if err := v.BindPFlag(f, cmd.Flags().Lookup(f)); err != nil {
+			panic(err)
+		}
+	}
+	return cmd
+}
+
+func txSearchFlags(cmd *cobra.Command, v *viper.Viper) *cobra.Command {
+	cmd.Flags().StringArray(flagEvents, nil, "event condition as key<op>value, e.g. message.sender=cosmos1... (repeatable, joined with AND)")
+	cmd.Flags().Bool(flagAll, false, "fetch every page of results instead of a single page")
+	cmd.Flags().String(flagOrderBy, string(client.TxOrderAsc), "order results by height (asc or desc)")
+	cmd.Flags().Int64(flagMinHeight, 0, "only return transactions at or above this height")
+	cmd.Flags().Int64(flagMaxHeight, 0, "only return transactions at or below this height")
+	cmd.Flags().Int("page", 1, "page of results to fetch when --all is not set")
+	cmd.Flags().Int("limit", 100, "results per page (at most 100)")
+	for _, f := range []string{flagEvents, flagAll, flagOrderBy, flagMinHeight, flagMaxHeight, "page", "limit"} {
+		if err := v.BindPFlag(f, cmd.Flags().Lookup(f)); err != nil {
+			panic(err)
+		}
+	}
+	return cmd
+}
+
+func humanFlag(cmd *cobra.Command, v *viper.Viper) *cobra.Command {
+	cmd.Flags().Bool(flagHuman, false, "show amounts in display units (e.g. ATOM instead of uatom) using the chain registry asset list")
+	if err := v.BindPFlag(flagHuman, cmd.Flags().Lookup(flagHuman)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func policyFlag(cmd *cobra.Command, v *viper.Viper) *cobra.Command {
+	cmd.Flags().String(flagPolicy, "latency", "endpoint ranking policy: latency, archive, tx-index or random")
+	if err := v.BindPFlag(flagPolicy, cmd.Flags().Lookup(flagPolicy)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func fallbackEndpointsFlag(cmd *cobra.Command, v *viper.Viper) *cobra.Command {
+	cmd.Flags().Int(flagFallback, 0, "number of other healthy RPC endpoints to fail over to and balance load across")
+	if err := v.BindPFlag(flagFallback, cmd.Flags().Lookup(flagFallback)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func endpointPolicyFlag(cmd *cobra.Command, v *viper.Viper) *cobra.Command {
+	cmd.Flags().String(flagEndpointPolicy, "", "probe the RPC endpoints and select one by policy: latency, archive, tx-index or random (defaults to a random healthy endpoint without probing)")
+	if err := v.BindPFlag(flagEndpointPolicy, cmd.Flags().Lookup(flagEndpointPolicy)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func ibcRouteFlags(cmd *cobra.Command, v *viper.Viper) *cobra.Command {
+	cmd.Flags().String(flagReceiver, "", "final receiver of the transfer, used to build the packet forward memo")
+	cmd.Flags().Int(flagMaxHops, chain_registry.DefaultMaxRouteHops, "maximum number of hops, not counting hops that unwind the denom to its origin")
+	cmd.Flags().Duration(flagTimeout, 0, "timeout of every forwarded transfer (defaults to the packet forward middleware's default)")
+	for _, f := range []string{flagReceiver, flagMaxHops, flagTimeout} {
+		if err := v.BindPFlag(f, cmd.Flags().Lookup(f)); err != nil {
+			panic(err)
+		}
+	}
+	return cmd
+}
+
+func chainsSyncFlags(cmd *cobra.Command, v *viper.Viper) *cobra.Command {
+	cmd.Flags().StringSlice(flagFields, nil, "only apply changes to these fields: "+strings.Join(client.SyncFields, ", "))
+	cmd.Flags().Bool(flagDryRun, false, "only show the changes, without applying them")
+	for _, f := range []string{flagFields, flagDryRun} {
+		if err := v.BindPFlag(f, cmd.Flags().Lookup(f)); err != nil {
+			panic(err)
+		}
+	}
+	return skipConfirm(cmd, v)
+}
+
+func registryValidateFlags(cmd *cobra.Command, v *viper.Viper) *cobra.Command {
+	cmd.Flags().Bool(flagOnline, false, "also query the chains: RPC endpoints must be reachable and IBC clients, connections and channels must exist")
+	cmd.Flags().StringSlice(flagAddress, nil, "sample addresses that must match the bech32 prefix of the validated chain")
+	for _, f := range []string{flagOnline, flagAddress} {
+		if err := v.BindPFlag(f, cmd.Flags().Lookup(f)); err != nil {
+			panic(err)
+		}
+	}
+	return cmd
+}
+
 func skipConfirm(cmd *cobra.Command, v *viper.Viper) *cobra.Command {
 	cmd.Flags().BoolP("skip", "y", false, "output using yaml")
 	v.BindPFlag("skip", cmd.Flags().Lookup("skip"))
@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/KyleMoser/cosmos-client/client/chain_registry"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+func ibcCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ibc",
+		Short: "inspect IBC paths between chains",
+	}
+
+	cmd.AddCommand(
+		ibcPathsCmd(a),
+		ibcValidateCmd(a),
+		ibcRouteCmd(a),
+	)
+
+	return cmd
+}
+
+func ibcPathsCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "paths [chain-name]",
+		Aliases: []string{"p"},
+		Short:   "list the IBC paths of a chain in the chain registry",
+		Long: `List the IBC paths between a chain and every chain it is connected to according to the
+chain registry, with the channel that would be used for transfers. Defaults to the chain of the default client.`,
+		Args: cobra.RangeArgs(0, 1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cl := a.GetDefaultClient()
+			var (
+				configs []chain_registry.IbcConfig
+				err     error
+			)
+			switch {
+			case len(args) == 1:
+				var registry chain_registry.ChainRegistry
+				if registry, err = a.ChainRegistry(cmd.Context()); err != nil {
+					return err
+				}
+				configs, err = chain_registry.GetIbcConfigs(cmd.Context(), registry, args[0])
+			case cl != nil:
+				configs, err = cl.GetIbcConfigs(cmd.Context())
+			default:
+				err = fmt.Errorf("no chain-name provided and there is no default chain")
+			}
+			if err != nil {
+				return err
+			}
+
+			if cl != nil && cl.Config.OutputFormat != outputTable {
+				return cl.PrintObject(configs)
+			}
+			rows := make([][]string, 0, len(configs))
+			for _, conf := range configs {
+				row := []string{conf.Chain2.ChainName, conf.Chain1.ClientId, conf.Chain1.ConnectionId, "", "", ""}
+				if ch, err := conf.PreferredChannel("transfer"); err == nil {
+					row[3], row[4], row[5] = ch.Chain1.ChannelId, ch.Chain2.ChannelId, ch.Tags.Status
+				}
+				rows = append(rows, row)
+			}
+			return writeTable(cmd.OutOrStdout(), []string{"COUNTERPARTY", "CLIENT", "CONNECTION", "CHANNEL", "COUNTERPARTY CHANNEL", "STATUS"}, rows)
+		},
+	}
+	return cmd
+}
+
+func ibcValidateCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "validate [chain-name] [counterparty-chain-name]",
+		Aliases: []string{"v"},
+		Short:   "validate the IBC transfer path between two configured chains against live state",
+		Long: `Validate the preferred transfer channel between two chains in the chain registry by querying
+both chains: the channels and connections must be open and refer to each other, and the light clients
+must be active and track the counterparty chain. Both chains must be configured.`,
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s ibc validate cosmoshub osmosis`, appName)),
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			src, dst := a.GetClient(args[0]), a.GetClient(args[1])
+			if src == nil {
+				return fmt.Errorf("chain %s not found", args[0])
+			}
+			if dst == nil {
+				return fmt.Errorf("chain %s not found", args[1])
+			}
+
+			v, err := src.ValidateIbcPath(cmd.Context(), dst)
+			if err != nil {
+				return err
+			}
+
+			if cl := a.GetDefaultClient(); cl != nil && cl.Config.OutputFormat != outputTable {
+				if err := cl.PrintObject(v); err != nil {
+					return err
+				}
+			} else {
+				rows := make([][]string, 0, len(v.Checks))
+				for _, c := range v.Checks {
+					rows = append(rows, []string{c.Chain, c.Check, fmt.Sprint(c.OK), c.Detail})
+				}
+				if err := writeTable(cmd.OutOrStdout(), []string{"CHAIN", "CHECK", "OK", "DETAIL"}, rows); err != nil {
+					return err
+				}
+			}
+			return v.Err()
+		},
+	}
+	return cmd
+}
+
+// ibcRouteOutput is a planned route together with what is needed to send the first transfer.
+type ibcRouteOutput struct {
+	chain_registry.IbcRoute
+	Receiver string `json:"receiver,omitempty"`
+	Memo     string `json:"memo,omitempty"`
+}
+
+func ibcRouteCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "route [denom] [from-chain-name] [to-chain-name]",
+		Aliases: []string{"r"},
+		Short:   "plan the IBC transfers that move a denom from one chain to another",
+		Long: `Plan the route of a denom between two chains of the chain registry. IBC denoms are first sent back
+along the path they arrived by to their origin chain, then over the fewest hops to the destination.
+The denom may be a native denom, an ibc/HASH denom or a full denom trace such as transfer/channel-0/uatom.
+With --receiver, the receiver and packet forward middleware memo of the first transfer are included.`,
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s ibc route uatom cosmoshub juno --receiver juno1...
+$ %s ibc route transfer/channel-0/uatom osmosis cosmoshub`, appName, appName)),
+		Args: cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			denom, from, to := args[0], args[1], args[2]
+
+			registry, err := a.ChainRegistry(cmd.Context())
+			if err != nil {
+				return err
+			}
+			router := chain_registry.NewIbcRouter(registry).WithMaxHops(a.Viper.GetInt(flagMaxHops))
+
+			// Denom traces missing from the asset list can be queried if the from chain is configured.
+			if strings.HasPrefix(denom, "ibc/") {
+				if src := a.GetClient(from); src != nil {
+					if trace, err := src.QueryDenomTrace(cmd.Context(), denom); err == nil {
+						denom = trace.GetFullDenomPath()
+					} else {
+						a.Log.Debug("Failed to query denom trace", zap.String("denom", denom), zap.Error(err))
+					}
+				}
+			}
+
+			route, err := router.Route(cmd.Context(), denom, from, to)
+			if err != nil {
+				return err
+			}
+
+			out := ibcRouteOutput{IbcRoute: route}
+			if receiver := a.Viper.GetString(flagReceiver); receiver != "" {
+				out.Receiver = route.Receiver(receiver)
+				out.Memo, err = route.ForwardMemo(receiver, chain_registry.ForwardOptions{Timeout: a.Viper.GetDuration(flagTimeout)})
+				if err != nil {
+					return err
+				}
+			}
+
+			if cl := a.GetDefaultClient(); cl != nil && cl.Config.OutputFormat != outputTable {
+				return cl.PrintObject(out)
+			}
+			rows := make([][]string, 0, len(route.Hops))
+			for _, hop := range route.Hops {
+				rows = append(rows, []string{hop.From, hop.To, hop.Port + "/" + hop.Channel, hop.CounterpartyChannel, hop.Denom, fmt.Sprint(hop.Unwind)})
+			}
+			if err := writeTable(cmd.OutOrStdout(), []string{"FROM", "TO", "CHANNEL", "COUNTERPARTY CHANNEL", "DENOM", "UNWIND"}, rows); err != nil {
+				return err
+			}
+			if out.Memo != "" {
+				fmt.Fprintf(cmd.OutOrStdout(), "\nreceiver: %s\nmemo: %s\n", out.Receiver, out.Memo)
+			}
+			return nil
+		},
+	}
+	return ibcRouteFlags(cmd, a.Viper)
+}
@@ -0,0 +1,46 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/KyleMoser/cosmos-client/client/chain_registry"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIbcPathsLocalRegistry(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"cosmoshub/chain.json": `{"chain_name": "cosmoshub", "chain_id": "cosmoshub-4"}`,
+		"osmosis/chain.json":   `{"chain_name": "osmosis", "chain_id": "osmosis-1"}`,
+		"_IBC/cosmoshub-osmosis.json": `{
+			"chain_1": {"chain_name": "cosmoshub", "client_id": "07-tendermint-259", "connection_id": "connection-257"},
+			"chain_2": {"chain_name": "osmosis", "client_id": "07-tendermint-1", "connection_id": "connection-1"},
+			"channels": [{"chain_1": {"channel_id": "channel-141", "port_id": "transfer"}, "chain_2": {"channel_id": "channel-0", "port_id": "transfer"}}]
+		}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+
+	// The client of the default chain reads its IBC paths from the local registry instead of GitHub.
+	res := NewSystem(t).MustRun(t, "--registry-path", dir, "ibc", "paths")
+	var configs []chain_registry.IbcConfig
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &configs))
+	require.Len(t, configs, 1)
+	require.Equal(t, "cosmoshub", configs[0].Chain1.ChainName)
+	require.Equal(t, "osmosis", configs[0].Chain2.ChainName)
+	require.Equal(t, "07-tendermint-259", configs[0].Chain1.ClientId)
+
+	res = NewSystem(t).MustRun(t, "--registry-path", dir, "ibc", "paths", "osmosis")
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &configs))
+	require.Len(t, configs, 1)
+	require.Equal(t, "osmosis", configs[0].Chain1.ChainName)
+	require.Equal(t, "channel-0", configs[0].Channels[0].Chain1.ChannelId)
+}
@@ -12,7 +12,13 @@ func queryCmd(a *appState) *cobra.Command {
 		Short:   "query things about a chain",
 	}
 
-	cmd.AddCommand(bankQueryCmd(a))
+	cmd.AddCommand(
+		bankQueryCmd(a),
+		blockQueryCmd(a),
+		blocksQueryCmd(a),
+		txQueryCmd(a),
+		txsQueryCmd(a),
+	)
 	return cmd
 }
 
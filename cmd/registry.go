@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/KyleMoser/cosmos-client/client/chain_registry"
+	"github.com/spf13/cobra"
+)
+
+func registryCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "registry",
+		Aliases: []string{"reg"},
+		Short:   "manage the chain registry",
+	}
+
+	cmd.AddCommand(
+		registryCacheCmd(a),
+		registryValidateCmd(a),
+	)
+
+	return cmd
+}
+
+func registryCacheCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "manage the on-disk cache of chain registry data",
+		Long: `Chain registry data fetched from GitHub is cached under the home directory and
+revalidated once it is older than the registry cache-ttl in the config file. If GitHub cannot be
+reached, stale cached data is used instead. Local registries are never cached.
+Use --registry to only manage the cache of one of the configured registries.`,
+	}
+
+	cmd.AddCommand(
+		registryCacheRefreshCmd(a),
+		registryCacheClearCmd(a),
+		registryCacheStatusCmd(a),
+	)
+
+	return cmd
+}
+
+func registryCacheRefreshCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "refresh",
+		Short: "revalidate every cached chain registry entry, regardless of its age",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			caches, err := a.RegistryCaches(cmd.Context())
+			if err != nil {
+				return err
+			}
+			for _, cache := range caches {
+				refreshed, err := cache.Refresh(cmd.Context())
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Refreshed %d cached entries of %s\n", refreshed, cache.SourceLink())
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+func registryCacheClearCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "clear",
+		Short: "remove every cached chain registry entry",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			caches, err := a.RegistryCaches(cmd.Context())
+			if err != nil {
+				return err
+			}
+			for _, cache := range caches {
+				if err := cache.Clear(); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+func registryCacheStatusCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "list the cached chain registry entries and their age",
+		Args:  cobra.NoArgs,
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s registry cache status
+$ %s registry cache status -o table`, appName, appName)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			caches, err := a.RegistryCaches(cmd.Context())
+			if err != nil {
+				return err
+			}
+			status := map[string][]chain_registry.CacheEntryStatus{}
+			var rows [][]string
+			for _, cache := range caches {
+				entries, err := cache.Status()
+				if err != nil {
+					return err
+				}
+				status[cache.SourceLink()] = entries
+				for _, e := range entries {
+					rows = append(rows, []string{cache.SourceLink(), e.Key, e.Age.String(), fmt.Sprint(e.Stale), fmt.Sprint(e.NotFound), e.ETag})
+				}
+			}
+
+			if cl := a.GetDefaultClient(); cl != nil && cl.Config.OutputFormat != outputTable {
+				return cl.PrintObject(status)
+			}
+			return writeTable(cmd.OutOrStdout(), []string{"REGISTRY", "KEY", "AGE", "STALE", "NOT FOUND", "ETAG"}, rows)
+		},
+	}
+	return cmd
+}
+
+func registryValidateCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate [path]",
+		Short: "validate chain registry files against their JSON schemas and the registry conventions",
+		Long: `Validate the chain.json, assetlist.json and _IBC files at path, which may be a single file, the
+directory of a chain or a whole chain registry checkout. Files are validated against the schema referenced
+by their $schema field, which is fetched from GitHub if it is not found next to the file. Semantic checks
+include the chain name matching its directory, the bech32 prefix matching sample addresses and the denom
+units of every asset. With --online, every RPC endpoint must report the chain ID, and the clients,
+connections and channels of IBC configs must exist on both chains.`,
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s registry validate ~/chain-registry/mychain --address mychain1...
+$ %s registry validate ~/chain-registry/_IBC/mychain-osmosis.json --online`, appName, appName)),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			validator := chain_registry.NewRegistryValidator(a.Log, chain_registry.ValidationOptions{
+				Online:          a.Viper.GetBool(flagOnline),
+				SampleAddresses: a.Viper.GetStringSlice(flagAddress),
+			})
+			issues, err := validator.Validate(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			if len(issues) == 0 {
+				fmt.Fprintln(cmd.ErrOrStderr(), "no issues found")
+				return nil
+			}
+
+			if cl := a.GetDefaultClient(); cl != nil && cl.Config.OutputFormat != outputTable {
+				if err := cl.PrintObject(issues); err != nil {
+					return err
+				}
+			} else {
+				rows := make([][]string, 0, len(issues))
+				for _, issue := range issues {
+					rows = append(rows, []string{issue.File, issue.Check, issue.Message})
+				}
+				if err := writeTable(cmd.OutOrStdout(), []string{"FILE", "CHECK", "MESSAGE"}, rows); err != nil {
+					return err
+				}
+			}
+			return fmt.Errorf("found %d issues", len(issues))
+		},
+	}
+	return registryValidateFlags(cmd, a.Viper)
+}
@@ -17,14 +17,19 @@ package cmd
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"os"
+	"strings"
+	"text/tabwriter"
 	"time"
 
+	"github.com/KyleMoser/cosmos-client/client/telemetry"
 	provtypes "github.com/cometbft/cometbft/light/provider"
 	rpcclient "github.com/cometbft/cometbft/rpc/client"
 	"github.com/cosmos/cosmos-sdk/client/flags"
 	zaplogfmt "github.com/jsternberg/zap-logfmt"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
@@ -71,7 +76,7 @@ func NewRootCmd(log *zap.Logger, atom zap.AtomicLevel, o map[string]ClientOverri
 		Short: "Basic CLI functionality for Cosmos blockchains.",
 	}
 
-	rootCmd.PersistentPreRunE = func(_ *cobra.Command, _ []string) error {
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, _ []string) error {
 		// Inside persistent pre-run because this takes effect after flags are parsed.
 		if a.Viper.GetBool("debug") {
 			atom.SetLevel(zapcore.DebugLevel)
@@ -85,6 +90,14 @@ func NewRootCmd(log *zap.Logger, atom zap.AtomicLevel, o map[string]ClientOverri
 			return err
 		}
 
+		if addr := a.Viper.GetString(flagMetricsAddr); addr != "" {
+			go func() {
+				if err := telemetry.ServeMetrics(cmd.Context(), addr, prometheus.DefaultGatherer); err != nil {
+					a.Log.Error("Failed to serve metrics", zap.String("addr", addr), zap.Error(err))
+				}
+			}()
+		}
+
 		return nil
 	}
 
@@ -100,7 +113,7 @@ func NewRootCmd(log *zap.Logger, atom zap.AtomicLevel, o map[string]ClientOverri
 		panic(err)
 	}
 
-	rootCmd.PersistentFlags().StringP("output", "o", "json", "output format (json, indent, yaml)")
+	rootCmd.PersistentFlags().StringP("output", "o", "json", "output format (json, indent, yaml, table); table is only supported by some commands")
 	if err := a.Viper.BindPFlag("output", rootCmd.PersistentFlags().Lookup("output")); err != nil {
 		panic(err)
 	}
@@ -110,11 +123,28 @@ func NewRootCmd(log *zap.Logger, atom zap.AtomicLevel, o map[string]ClientOverri
 		panic(err)
 	}
 
+	rootCmd.PersistentFlags().String(flagRegistryPath, "", "read the chain registry from this local checkout of cosmos/chain-registry instead of GitHub")
+	if err := a.Viper.BindPFlag(flagRegistryPath, rootCmd.PersistentFlags().Lookup(flagRegistryPath)); err != nil {
+		panic(err)
+	}
+
+	rootCmd.PersistentFlags().String(flagRegistry, "", "only use the chain registry with this name from the config file")
+	if err := a.Viper.BindPFlag(flagRegistry, rootCmd.PersistentFlags().Lookup(flagRegistry)); err != nil {
+		panic(err)
+	}
+
+	rootCmd.PersistentFlags().String(flagMetricsAddr, "", "serve Prometheus metrics of every chain client at /metrics on this address, e.g. :9090")
+	if err := a.Viper.BindPFlag(flagMetricsAddr, rootCmd.PersistentFlags().Lookup(flagMetricsAddr)); err != nil {
+		panic(err)
+	}
+
 	rootCmd.AddCommand(
 		chainsCmd(a),
 		keysCmd(a),
 		queryCmd(a),
 		tendermintCmd(a),
+		registryCmd(a),
+		ibcCmd(a),
 	)
 
 	if extraCommands != nil {
@@ -180,6 +210,16 @@ func writeJSON(w io.Writer, obj interface{}) error {
 	return enc.Encode(obj)
 }
 
+// writeTable writes the given rows as whitespace aligned columns under the given headers.
+func writeTable(w io.Writer, headers []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
 // withUsage wraps a PositionalArgs to display usage only when the PositionalArgs
 // variant is violated.
 func withUsage(inner cobra.PositionalArgs) cobra.PositionalArgs {
@@ -18,8 +18,10 @@ package cmd
 import (
 	"fmt"
 	"net/url"
+	"strconv"
 	"strings"
 
+	"github.com/KyleMoser/cosmos-client/client"
 	"github.com/KyleMoser/cosmos-client/client/query"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
@@ -36,6 +38,10 @@ func tendermintCmd(a *appState) *cobra.Command {
 		healthCmd(a),
 		netInfoCmd(a),
 		statusCmd(a),
+		mempoolCmd(a),
+		validatorsCmd(a),
+		consensusStateCmd(a),
+		dumpConsensusStateCmd(a),
 	)
 	return cmd
 }
@@ -125,3 +131,187 @@ func statusCmd(a *appState) *cobra.Command {
 	}
 	return cmd
 }
+
+func mempoolCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "mempool",
+		Aliases: []string{"mp", "unconfirmed-txs"},
+		Short:   "query the transactions pending in the node's mempool",
+		Long: `Query the transactions pending in the node's mempool, decoded and summarized by signer,
+messages and fees. Use --count to only return the number of pending transactions.
+With --signer or --msg-type, --limit caps the number of matching transactions returned.`,
+		Args: cobra.NoArgs,
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s tendermint mempool
+$ %s tm mempool --signer cosmos1... --msg-type MsgSend
+$ %s tm mempool --count`, appName, appName, appName)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cl := a.GetDefaultClient()
+
+			count, err := cmd.Flags().GetBool(flagCount)
+			if err != nil {
+				return err
+			}
+			if count {
+				res, err := cl.QueryNumUnconfirmedTxs(cmd.Context())
+				if err != nil {
+					return err
+				}
+				return cl.PrintObject(res)
+			}
+
+			limit, err := cmd.Flags().GetInt("limit")
+			if err != nil {
+				return err
+			}
+			filter := client.MempoolFilter{}
+			if filter.MsgType, err = cmd.Flags().GetString(flagMsgType); err != nil {
+				return err
+			}
+			signer, err := cmd.Flags().GetString(flagSigner)
+			if err != nil {
+				return err
+			}
+			if signer != "" {
+				addr, err := cl.AccountFromKeyOrAddress(signer)
+				if err != nil {
+					return err
+				}
+				filter.Signer = cl.MustEncodeAccAddr(addr)
+			}
+
+			res, err := cl.QueryUnconfirmedTxs(cmd.Context(), limit, filter)
+			if err != nil {
+				return err
+			}
+			return cl.PrintObject(res)
+		},
+	}
+	return mempoolFlags(limitFlag(cmd, a.Viper), a.Viper)
+}
+
+func validatorsCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "validators [height]",
+		Aliases: []string{"vals", "valset"},
+		Short:   "query the validator set (at the latest height if no height is given)",
+		Long: `Query the complete CometBFT validator set, annotated with the moniker and operator
+address of each validator as known to the staking module.`,
+		Args: withUsage(cobra.RangeArgs(0, 1)),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s tendermint validators
+$ %s tm vals 1234567 -o table`, appName, appName)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cl := a.GetDefaultClient()
+			var height int64
+			if len(args) == 1 {
+				var err error
+				height, err = strconv.ParseInt(args[0], 10, 64)
+				if err != nil {
+					return fmt.Errorf("invalid height %q: %w", args[0], err)
+				}
+			}
+
+			valSet, err := cl.QueryValidatorSet(cmd.Context(), height)
+			if err != nil {
+				return err
+			}
+			if cl.Config.OutputFormat != outputTable {
+				return cl.PrintObject(valSet)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Height:      %d\nValidators:  %d\nTotal power: %d\n\n",
+				valSet.BlockHeight, len(valSet.Validators), valSet.TotalVotingPower)
+			rows := make([][]string, 0, len(valSet.Validators))
+			for _, v := range valSet.Validators {
+				rows = append(rows, []string{
+					v.Address,
+					v.Moniker,
+					strconv.FormatInt(v.VotingPower, 10),
+					powerShare(v.VotingPower, valSet.TotalVotingPower),
+					strconv.FormatInt(v.ProposerPriority, 10),
+				})
+			}
+			return writeTable(cmd.OutOrStdout(), []string{"ADDRESS", "MONIKER", "POWER", "SHARE", "PRIORITY"}, rows)
+		},
+	}
+	return cmd
+}
+
+func consensusStateCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "consensus-state",
+		Aliases: []string{"cs", "consensus"},
+		Short:   "show which validators have prevoted and precommitted in the current consensus round",
+		Long: `Show the prevote and precommit of every validator in each round of the height the node
+is currently deciding. When a chain halts, this shows which validators are missing.`,
+		Args: cobra.NoArgs,
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s tendermint consensus-state
+$ %s tm cs -o table`, appName, appName)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cl := a.GetDefaultClient()
+			state, err := cl.QueryConsensusParticipation(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if cl.Config.OutputFormat != outputTable {
+				return cl.PrintObject(state)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Height:      %d\nRound:       %d\nStep:        %s\nProposer:    %s\nTotal power: %d\n\n",
+				state.Height, state.Round, state.Step, state.ProposerAddress, state.TotalVotingPower)
+			var rows [][]string
+			for _, r := range state.Rounds {
+				for _, v := range r.Validators {
+					rows = append(rows, []string{
+						strconv.Itoa(int(r.Round)),
+						v.Address,
+						v.Moniker,
+						strconv.FormatInt(v.VotingPower, 10),
+						string(v.Prevote),
+						string(v.Precommit),
+						v.BlockHash,
+					})
+				}
+				rows = append(rows, []string{
+					strconv.Itoa(int(r.Round)),
+					"",
+					"TOTAL",
+					"",
+					powerShare(r.PrevotePower, state.TotalVotingPower),
+					powerShare(r.PrecommitPower, state.TotalVotingPower),
+					"",
+				})
+			}
+			return writeTable(cmd.OutOrStdout(), []string{"ROUND", "ADDRESS", "MONIKER", "POWER", "PREVOTE", "PRECOMMIT", "BLOCK"}, rows)
+		},
+	}
+	return cmd
+}
+
+func dumpConsensusStateCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "dump-consensus-state",
+		Aliases: []string{"dcs"},
+		Short:   "dump the node's full consensus state, including the round state of its peers",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cl := a.GetDefaultClient()
+			state, err := cl.RPCClient.DumpConsensusState(cmd.Context())
+			if err != nil {
+				return err
+			}
+			return writeJSON(cmd.OutOrStdout(), state)
+		},
+	}
+	return cmd
+}
+
+// powerShare formats power as a percentage of total.
+func powerShare(power, total int64) string {
+	if total == 0 {
+		return "0.00%"
+	}
+	return fmt.Sprintf("%.2f%%", float64(power)*100/float64(total))
+}
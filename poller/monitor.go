@@ -0,0 +1,445 @@
+package poller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// AlertKind identifies the type of anomaly detected by a BlockMonitor.
+type AlertKind string
+
+const (
+	// AlertChainHalt is raised when a chain has not produced a new block for
+	// MonitorConfig.StallMultiple times its average block time.
+	AlertChainHalt AlertKind = "chain_halt"
+	// AlertBlockTimeDegraded is raised when the recent average block time exceeds
+	// the baseline average by more than MonitorConfig.DegradationFactor.
+	AlertBlockTimeDegraded AlertKind = "block_time_degraded"
+	// AlertNodeLag is raised when an endpoint reports a height more than
+	// MonitorConfig.MaxNodeLag blocks behind the best endpoint for the same chain.
+	AlertNodeLag AlertKind = "node_lag"
+)
+
+// Alert describes an anomaly (or the resolution of an anomaly) for a single chain.
+type Alert struct {
+	Kind     AlertKind `json:"kind"`
+	ChainID  string    `json:"chain_id"`
+	Endpoint string    `json:"endpoint,omitempty"`
+	Height   int64     `json:"height"`
+	Message  string    `json:"message"`
+	Resolved bool      `json:"resolved"`
+	Time     time.Time `json:"time"`
+}
+
+// AlertHandler is invoked by the BlockMonitor for every alert it publishes.
+// Handlers are called one at a time from a single goroutine, in the order the alerts were raised,
+// so a slow handler delays later alerts but never blocks monitoring.
+type AlertHandler func(Alert)
+
+const (
+	// alertQueueSize is the number of alerts waiting for delivery after which new alerts are dropped.
+	alertQueueSize = 256
+	// DefaultWebhookTimeout is the timeout of alert webhooks created with a zero timeout.
+	DefaultWebhookTimeout = 10 * time.Second
+)
+
+// MonitorConfig controls the thresholds used by a BlockMonitor.
+type MonitorConfig struct {
+	// StallMultiple is the multiple of the average block time without a new block
+	// after which the chain is considered halted.
+	StallMultiple float64
+	// MinStallDuration is the minimum time without a new block before a halt is reported,
+	// regardless of the average block time.
+	MinStallDuration time.Duration
+	// DegradationFactor is the ratio between the recent and baseline average block
+	// times above which block production is considered degraded.
+	DegradationFactor float64
+	// MaxNodeLag is the number of blocks an endpoint may trail the best endpoint for the same chain.
+	MaxNodeLag int64
+	// RecentWindow is the number of block intervals used to compute the recent average block time.
+	RecentWindow int
+	// BaselineWindow is the number of block intervals used to compute the baseline average block time.
+	BaselineWindow int
+}
+
+// DefaultMonitorConfig returns thresholds that are reasonable for most Cosmos chains.
+func DefaultMonitorConfig() MonitorConfig {
+	return MonitorConfig{
+		StallMultiple:     5,
+		MinStallDuration:  30 * time.Second,
+		DegradationFactor: 2,
+		MaxNodeLag:        10,
+		RecentWindow:      10,
+		BaselineWindow:    100,
+	}
+}
+
+// BlockMonitor tracks block production per chain and publishes alerts when a chain
+// stalls, when block times degrade, or when an endpoint lags behind its peers.
+// It is safe for concurrent use. Alerts are delivered asynchronously until Close is called.
+type BlockMonitor struct {
+	log *zap.Logger
+	cfg MonitorConfig
+
+	mu       sync.Mutex
+	chains   map[string]*chainStats // chainID is the map key
+	handlers []AlertHandler
+
+	queue     chan Alert
+	closeOnce sync.Once
+	closed    chan struct{}
+	done      chan struct{}
+}
+
+type chainStats struct {
+	lastHeight    int64
+	lastBlockSeen time.Time
+	intervals     []time.Duration // most recent block intervals, oldest first
+	endpoints     map[string]int64
+	halted        bool
+	degraded      bool
+	lagging       map[string]bool
+}
+
+// NewBlockMonitor returns a BlockMonitor that publishes alerts to the given handlers.
+// Close must be called to stop delivering alerts.
+func NewBlockMonitor(log *zap.Logger, cfg MonitorConfig, handlers ...AlertHandler) *BlockMonitor {
+	m := &BlockMonitor{
+		log:      log,
+		cfg:      cfg,
+		chains:   map[string]*chainStats{},
+		handlers: handlers,
+		queue:    make(chan Alert, alertQueueSize),
+		closed:   make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go m.deliver()
+	return m
+}
+
+// Close stops accepting alerts and waits until the alerts already queued have been delivered.
+func (m *BlockMonitor) Close() {
+	m.closeOnce.Do(func() {
+		m.mu.Lock()
+		close(m.closed)
+		m.mu.Unlock()
+	})
+	<-m.done
+}
+
+// AddHandler registers an additional AlertHandler.
+func (m *BlockMonitor) AddHandler(h AlertHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handlers = append(m.handlers, h)
+}
+
+// Observe records that the given endpoint reported height for chainID at time at.
+func (m *BlockMonitor) Observe(chainID, endpoint string, height int64, at time.Time) {
+	m.mu.Lock()
+	stats, ok := m.chains[chainID]
+	if !ok {
+		stats = &chainStats{endpoints: map[string]int64{}, lagging: map[string]bool{}}
+		m.chains[chainID] = stats
+	}
+
+	var alerts []Alert
+	if height > stats.endpoints[endpoint] {
+		stats.endpoints[endpoint] = height
+	}
+
+	if height > stats.lastHeight {
+		if stats.lastHeight > 0 {
+			interval := at.Sub(stats.lastBlockSeen) / time.Duration(height-stats.lastHeight)
+			stats.intervals = append(stats.intervals, interval)
+			if max := m.cfg.BaselineWindow + m.cfg.RecentWindow; len(stats.intervals) > max {
+				stats.intervals = stats.intervals[len(stats.intervals)-max:]
+			}
+		}
+		stats.lastHeight = height
+		stats.lastBlockSeen = at
+
+		if stats.halted {
+			stats.halted = false
+			alerts = append(alerts, Alert{
+				Kind:     AlertChainHalt,
+				ChainID:  chainID,
+				Height:   height,
+				Message:  "block production resumed",
+				Resolved: true,
+				Time:     at,
+			})
+		}
+		alerts = append(alerts, m.checkDegradation(chainID, stats, at)...)
+	}
+
+	alerts = append(alerts, m.checkLag(chainID, stats, at)...)
+	m.publish(alerts)
+	m.mu.Unlock()
+}
+
+// Check evaluates every tracked chain for stalls as of now.
+// Observe only runs when a height is reported, so Check must be called periodically
+// (see Run) for halts to be detected.
+func (m *BlockMonitor) Check(now time.Time) {
+	m.mu.Lock()
+	var alerts []Alert
+	for chainID, stats := range m.chains {
+		if stats.halted || stats.lastHeight == 0 {
+			continue
+		}
+		avg := average(stats.intervals)
+		if avg == 0 {
+			continue
+		}
+
+		threshold := time.Duration(float64(avg) * m.cfg.StallMultiple)
+		if threshold < m.cfg.MinStallDuration {
+			threshold = m.cfg.MinStallDuration
+		}
+
+		if since := now.Sub(stats.lastBlockSeen); since > threshold {
+			stats.halted = true
+			alerts = append(alerts, Alert{
+				Kind:    AlertChainHalt,
+				ChainID: chainID,
+				Height:  stats.lastHeight,
+				Message: fmt.Sprintf("no new block for %s (average block time %s)", since.Round(time.Second), avg.Round(time.Millisecond)),
+				Time:    now,
+			})
+		}
+	}
+	m.publish(alerts)
+	m.mu.Unlock()
+}
+
+// Run calls Check on every tick of the given interval until the context is cancelled.
+func (m *BlockMonitor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			m.Check(now)
+		}
+	}
+}
+
+// Watch subscribes to the heights the notifier reports for every given chain, feeding them into the
+// monitor, and checks for stalls on each tick of interval until the context is cancelled.
+// The notifier reports heights per chain rather than per endpoint, so node lag is only detected
+// for heights passed to Observe directly.
+func (m *BlockMonitor) Watch(ctx context.Context, notifier BlockHeightNotifier, interval time.Duration, chainIDs ...string) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	// The goroutines are stopped before waiting for them, also if subscribing fails.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for _, chainID := range chainIDs {
+		o := &monitorObserver{heights: make(chan uint64, 1)}
+		if _, err := notifier.Subscribe(o, chainID); err != nil {
+			return fmt.Errorf("failed to subscribe to block heights of %s: %w", chainID, err)
+		}
+		defer func(chainID string) {
+			if _, err := notifier.Unsubscribe(o, chainID); err != nil {
+				m.log.Debug("Failed to unsubscribe from block heights", zap.String("chain_id", chainID), zap.Error(err))
+			}
+		}(chainID)
+
+		wg.Add(1)
+		go func(chainID string) {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case height := <-o.heights:
+					m.Observe(chainID, "", int64(height), time.Now())
+				}
+			}
+		}(chainID)
+	}
+
+	m.Run(ctx, interval)
+	return nil
+}
+
+// monitorObserver receives the heights a BlockHeightNotifier reports for a chain watched by a BlockMonitor.
+type monitorObserver struct {
+	heights chan uint64
+}
+
+func (o *monitorObserver) Get() chan uint64 {
+	return o.heights
+}
+
+func (m *BlockMonitor) checkDegradation(chainID string, stats *chainStats, at time.Time) []Alert {
+	if m.cfg.RecentWindow <= 0 || len(stats.intervals) < 2*m.cfg.RecentWindow {
+		return nil
+	}
+
+	split := len(stats.intervals) - m.cfg.RecentWindow
+	baseline := average(stats.intervals[:split])
+	recent := average(stats.intervals[split:])
+	if baseline == 0 {
+		return nil
+	}
+
+	degraded := float64(recent) > float64(baseline)*m.cfg.DegradationFactor
+	if degraded == stats.degraded {
+		return nil
+	}
+	stats.degraded = degraded
+
+	msg := fmt.Sprintf("recent block time %s exceeds baseline %s", recent.Round(time.Millisecond), baseline.Round(time.Millisecond))
+	if !degraded {
+		msg = fmt.Sprintf("block time recovered to %s (baseline %s)", recent.Round(time.Millisecond), baseline.Round(time.Millisecond))
+	}
+	return []Alert{{
+		Kind:     AlertBlockTimeDegraded,
+		ChainID:  chainID,
+		Height:   stats.lastHeight,
+		Message:  msg,
+		Resolved: !degraded,
+		Time:     at,
+	}}
+}
+
+func (m *BlockMonitor) checkLag(chainID string, stats *chainStats, at time.Time) []Alert {
+	var best int64
+	for _, h := range stats.endpoints {
+		if h > best {
+			best = h
+		}
+	}
+
+	var alerts []Alert
+	for endpoint, h := range stats.endpoints {
+		lag := best - h
+		lagging := lag > m.cfg.MaxNodeLag
+		if lagging == stats.lagging[endpoint] {
+			continue
+		}
+		stats.lagging[endpoint] = lagging
+
+		msg := fmt.Sprintf("endpoint is %d blocks behind the best known height %d", lag, best)
+		if !lagging {
+			msg = "endpoint caught up"
+		}
+		alerts = append(alerts, Alert{
+			Kind:     AlertNodeLag,
+			ChainID:  chainID,
+			Endpoint: endpoint,
+			Height:   h,
+			Message:  msg,
+			Resolved: !lagging,
+			Time:     at,
+		})
+	}
+	return alerts
+}
+
+// AverageBlockTime returns the average block interval observed for the given chain,
+// or zero if fewer than two blocks have been observed.
+func (m *BlockMonitor) AverageBlockTime(chainID string) time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if stats, ok := m.chains[chainID]; ok {
+		return average(stats.intervals)
+	}
+	return 0
+}
+
+func average(intervals []time.Duration) time.Duration {
+	if len(intervals) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, i := range intervals {
+		total += i
+	}
+	return total / time.Duration(len(intervals))
+}
+
+// publish queues the alerts for delivery, dropping them if the queue is full or the monitor is closed.
+// It must be called with m.mu held, which keeps Close from closing the queue during the send.
+func (m *BlockMonitor) publish(alerts []Alert) {
+	for _, a := range alerts {
+		select {
+		case <-m.closed:
+			return
+		default:
+		}
+		select {
+		case m.queue <- a:
+		default:
+			m.log.Warn("Dropped alert because the alert queue is full", zap.String("chain_id", a.ChainID), zap.String("kind", string(a.Kind)))
+		}
+	}
+}
+
+// deliver passes the queued alerts to the handlers until the monitor is closed and the queue is drained.
+func (m *BlockMonitor) deliver() {
+	defer close(m.done)
+	for {
+		select {
+		case a := <-m.queue:
+			m.handle(a)
+		case <-m.closed:
+			for {
+				select {
+				case a := <-m.queue:
+					m.handle(a)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (m *BlockMonitor) handle(a Alert) {
+	m.mu.Lock()
+	handlers := m.handlers
+	m.mu.Unlock()
+	for _, h := range handlers {
+		h(a)
+	}
+}
+
+// NewWebhookAlertHandler returns an AlertHandler that POSTs each alert as JSON to the given URL,
+// giving up after timeout, or DefaultWebhookTimeout if timeout is zero.
+// Delivery failures are logged and otherwise ignored.
+func NewWebhookAlertHandler(log *zap.Logger, url string, timeout time.Duration) AlertHandler {
+	if timeout <= 0 {
+		timeout = DefaultWebhookTimeout
+	}
+	httpClient := &http.Client{Timeout: timeout}
+	return func(a Alert) {
+		body, err := json.Marshal(a)
+		if err != nil {
+			log.Info("Failed to encode alert", zap.Error(err))
+			return
+		}
+
+		res, err := httpClient.Post(url, "application/json", bytes.NewReader(body))
+		if err != nil {
+			log.Info("Failed to deliver alert", zap.String("url", url), zap.Error(err))
+			return
+		}
+		defer res.Body.Close()
+		if res.StatusCode >= 300 {
+			log.Info("Alert webhook rejected alert", zap.String("url", url), zap.Int("status", res.StatusCode))
+		}
+	}
+}
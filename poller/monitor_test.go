@@ -0,0 +1,176 @@
+package poller_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/KyleMoser/cosmos-client/poller"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// newTestMonitor returns a monitor sending its alerts to the returned channel.
+// Alerts are delivered in order, so a receive fails the test if an unexpected alert was raised before.
+func newTestMonitor(t *testing.T, cfg poller.MonitorConfig) (*poller.BlockMonitor, <-chan poller.Alert) {
+	alerts := make(chan poller.Alert, 100)
+	m := poller.NewBlockMonitor(zaptest.NewLogger(t), cfg, func(a poller.Alert) {
+		alerts <- a
+	})
+	t.Cleanup(m.Close)
+	return m, alerts
+}
+
+func nextAlert(t *testing.T, alerts <-chan poller.Alert) poller.Alert {
+	select {
+	case a := <-alerts:
+		return a
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for alert")
+		return poller.Alert{}
+	}
+}
+
+func TestBlockMonitorHalt(t *testing.T) {
+	m, alerts := newTestMonitor(t, poller.DefaultMonitorConfig())
+
+	start := time.Unix(1700000000, 0)
+	for i := int64(1); i <= 10; i++ {
+		m.Observe("test-1", "a", i, start.Add(time.Duration(i)*6*time.Second))
+	}
+	require.Equal(t, 6*time.Second, m.AverageBlockTime("test-1"))
+
+	last := start.Add(60 * time.Second)
+	m.Check(last.Add(20 * time.Second))
+
+	m.Check(last.Add(31 * time.Second))
+	a := nextAlert(t, alerts)
+	require.Equal(t, poller.AlertChainHalt, a.Kind)
+	require.False(t, a.Resolved)
+
+	// A halt is only reported once.
+	m.Check(last.Add(time.Minute))
+
+	m.Observe("test-1", "a", 11, last.Add(2*time.Minute))
+	a = nextAlert(t, alerts)
+	require.Equal(t, poller.AlertChainHalt, a.Kind)
+	require.True(t, a.Resolved)
+
+	m.Close()
+	require.Empty(t, alerts)
+}
+
+func TestBlockMonitorDegradation(t *testing.T) {
+	cfg := poller.DefaultMonitorConfig()
+	cfg.RecentWindow = 5
+	cfg.BaselineWindow = 20
+	m, alerts := newTestMonitor(t, cfg)
+
+	at := time.Unix(1700000000, 0)
+	height := int64(1)
+	for ; height <= 20; height++ {
+		at = at.Add(5 * time.Second)
+		m.Observe("test-1", "a", height, at)
+	}
+
+	for i := 0; i < 5; i++ {
+		at = at.Add(20 * time.Second)
+		m.Observe("test-1", "a", height, at)
+		height++
+	}
+	a := nextAlert(t, alerts)
+	require.Equal(t, poller.AlertBlockTimeDegraded, a.Kind)
+	require.False(t, a.Resolved)
+}
+
+func TestBlockMonitorNodeLag(t *testing.T) {
+	m, alerts := newTestMonitor(t, poller.DefaultMonitorConfig())
+
+	now := time.Unix(1700000000, 0)
+	m.Observe("test-1", "fast", 100, now)
+	m.Observe("test-1", "slow", 95, now)
+
+	m.Observe("test-1", "fast", 120, now.Add(time.Minute))
+	a := nextAlert(t, alerts)
+	require.Equal(t, poller.AlertNodeLag, a.Kind)
+	require.Equal(t, "slow", a.Endpoint)
+
+	m.Observe("test-1", "slow", 119, now.Add(2*time.Minute))
+	a = nextAlert(t, alerts)
+	require.True(t, a.Resolved)
+
+	m.Close()
+	require.Empty(t, alerts)
+}
+
+func TestBlockMonitorSlowHandler(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	m := poller.NewBlockMonitor(zaptest.NewLogger(t), poller.DefaultMonitorConfig(), poller.NewWebhookAlertHandler(zaptest.NewLogger(t), server.URL, 0))
+	t.Cleanup(m.Close)
+
+	// A webhook that does not respond does not block observing other chains.
+	observed := make(chan struct{})
+	go func() {
+		now := time.Unix(1700000000, 0)
+		for i := int64(0); i < 10; i++ {
+			m.Observe("test-1", "fast", 100+i*20, now)
+			m.Observe("test-1", "slow", 1, now)
+			m.Observe("test-2", "a", i, now)
+		}
+		close(observed)
+	}()
+	select {
+	case <-observed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("observing blocked on the alert webhook")
+	}
+}
+
+func TestBlockMonitorWatch(t *testing.T) {
+	m, _ := newTestMonitor(t, poller.DefaultMonitorConfig())
+	q := &poller.Querier{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	watched := make(chan error)
+	go func() {
+		watched <- m.Watch(ctx, q, time.Hour, "test-1")
+	}()
+
+	// Heights reported by the notifier are observed by the monitor.
+	var height int64
+	require.Eventually(t, func() bool {
+		height++
+		q.Notify("test-1", height)
+		return m.AverageBlockTime("test-1") > 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-watched)
+
+	// If subscribing to a chain fails, Watch returns the error without waiting for the context.
+	failing := &failingNotifier{Querier: q, chainID: "test-2"}
+	err := m.Watch(context.Background(), failing, time.Hour, "test-1", "test-2")
+	require.ErrorContains(t, err, "test-2")
+}
+
+// failingNotifier fails to subscribe to the block heights of a chain.
+type failingNotifier struct {
+	*poller.Querier
+	chainID string
+}
+
+func (n *failingNotifier) Subscribe(o poller.BlockHeightObserver, chainID string) (bool, error) {
+	if chainID == n.chainID {
+		return false, errors.New("subscription failed")
+	}
+	return n.Querier.Subscribe(o, chainID)
+}
@@ -9,15 +9,46 @@ import (
 )
 
 type Querier struct {
-	blockObservers           map[string]BlockHeightObserver   // chainID is the map key. observers can choose to listen for new blocks on particular chains.
+	mu                       sync.Mutex
+	blockObservers           map[string][]BlockHeightObserver // chainID is the map key. observers can choose to listen for new blocks on particular chains.
 	chainClients             map[string][]*client.ChainClient // chainID is the map key. multiple chain clients used for liveness.
 	activeBlockHeightQueries map[string]*sync.Mutex
 }
 
+var _ BlockHeightNotifier = &Querier{}
+
+// Subscribe registers the observer for new blocks of the chain. It returns false if the observer was already registered.
 func (q *Querier) Subscribe(o BlockHeightObserver, chainID string) (bool, error) {
-	return false, nil
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, existing := range q.blockObservers[chainID] {
+		if existing == o {
+			return false, nil
+		}
+	}
+	if q.blockObservers == nil {
+		q.blockObservers = map[string][]BlockHeightObserver{}
+	}
+	q.blockObservers[chainID] = append(q.blockObservers[chainID], o)
+	return true, nil
 }
+
+// Unsubscribe removes the observer from the chain. It returns false if the observer was not registered.
 func (q *Querier) Unsubscribe(o BlockHeightObserver, chainID string) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	observers := q.blockObservers[chainID]
+	for i, existing := range observers {
+		if existing == o {
+			observers = append(observers[:i:i], observers[i+1:]...)
+			if len(observers) == 0 {
+				delete(q.blockObservers, chainID)
+			} else {
+				q.blockObservers[chainID] = observers
+			}
+			return true, nil
+		}
+	}
 	return false, nil
 }
 
@@ -74,6 +105,8 @@ func (q *Querier) randLiveChain(chain string) (*client.ChainClient, error) {
 
 // Not all chains will have observers and it's pointless to query chains if nobody wants the results.
 func (q *Querier) getObservedChains() []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
 	observedChains := make([]string, len(q.blockObservers))
 
 	i := 0
@@ -85,8 +118,29 @@ func (q *Querier) getObservedChains() []string {
 	return observedChains
 }
 
+// Notify sends the height to the observers of the chain. Observers that have not received the previous
+// height yet only get the latest one, so a slow observer never blocks the querier.
 func (q *Querier) Notify(chain string, height int64) {
-
+	q.mu.Lock()
+	observers := q.blockObservers[chain]
+	q.mu.Unlock()
+
+	for _, o := range observers {
+		heights := o.Get()
+		select {
+		case heights <- uint64(height):
+			continue
+		default:
+		}
+		select {
+		case <-heights:
+		default:
+		}
+		select {
+		case heights <- uint64(height):
+		default:
+		}
+	}
 }
 
 // type blockPoller[T any] struct {